@@ -5,20 +5,29 @@ import (
 	"database/sql"
 	"strings"
 	"time"
+	_ "time/tzdata" // в alpine-образе нет базы часовых поясов
 
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 
+	"github.com/yandex-development-2-team/Go/internal/api"
 	"github.com/yandex-development-2-team/Go/internal/bot"
+	"github.com/yandex-development-2-team/Go/internal/bot/dispatcher"
+	"github.com/yandex-development-2-team/Go/internal/bot/middleware"
 	"github.com/yandex-development-2-team/Go/internal/config"
 	"github.com/yandex-development-2-team/Go/internal/database"
 	"github.com/yandex-development-2-team/Go/internal/database/repository"
 	"github.com/yandex-development-2-team/Go/internal/handlers"
 	"github.com/yandex-development-2-team/Go/internal/logger"
 	"github.com/yandex-development-2-team/Go/internal/metrics"
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/scheduler"
 	"github.com/yandex-development-2-team/Go/internal/shutdown"
+	"github.com/yandex-development-2-team/Go/internal/state"
+	"github.com/yandex-development-2-team/Go/internal/tracker"
 )
 
 func main() {
@@ -57,71 +66,213 @@ func main() {
 		log.Fatal("failed_to_run_migrations", zap.Error(err))
 	}
 
-	// Используем существующий UserRepository через адаптер
-	dbAdapter := repository.NewDBAdapter(db)
-	userRepo := repository.NewUserRepository(dbAdapter, log)
+	serviceRepo := repository.NewServiceRepository(sqlxDB, log)
 
-	tg, err := bot.NewTelegramBot(cfg.Telegram.BotToken, log)
+	tg, err := bot.NewTelegramBotWithEndpoint(cfg.Telegram.BotToken, cfg.Telegram.APIEndpoint, log)
 	if err != nil {
 		log.Fatal("failed_to_init_bot", zap.Error(err))
 	}
 
 	httpSrv := metrics.NewServer(cfg.Server.PrometheusPort, sqlxDB, tg, m, log)
+
+	shutdownTasks := []shutdown.ShutdownTask{
+		{Name: "http_server", Fn: httpSrv.Shutdown},
+	}
+
+	var webhook *bot.WebhookReceiver
+	if cfg.Telegram.Mode == config.TelegramModeWebhook {
+		webhook = bot.NewWebhookReceiver(cfg.Telegram.Webhook.Secret, cfg.Bot.QueueSize, log)
+
+		if cfg.Telegram.Webhook.Port == 0 {
+			httpSrv.Handle(cfg.Telegram.Webhook.Path, webhook)
+		} else {
+			webhookSrv := bot.NewWebhookServer(cfg.Telegram.Webhook.Port, cfg.Telegram.Webhook.Path, webhook, log)
+			go func() {
+				if err := webhookSrv.Start(); err != nil {
+					log.Fatal("failed_to_start_webhook_server", zap.Error(err))
+				}
+			}()
+			shutdownTasks = append(shutdownTasks, shutdown.ShutdownTask{Name: "webhook_server", Fn: webhookSrv.Shutdown})
+		}
+	}
+
+	// LoadConfig уже проверил часовой пояс
+	bookingTZ, _ := time.LoadLocation(cfg.Reminders.Timezone)
+	bookingRepo := repository.NewBookingRepository(sqlxDB, log)
+
+	var calendarURL func(int64) string
+	if cfg.Calendar.Secret != "" {
+		feed := api.NewCalendarFeed(bookingRepo, cfg.Calendar.Secret, bookingTZ, log)
+		httpSrv.Handle(api.CalendarFeedPath, feed)
+		calendarURL = func(telegramID int64) string { return feed.URL(cfg.Calendar.PublicURL, telegramID) }
+	}
+
 	go func() {
 		if err := httpSrv.Start(); err != nil {
 			log.Fatal("failed_to_start_http_server", zap.Error(err))
 		}
 	}()
 
+	botSender := handlers.NewBotSender(tg.Api)
+	handlers.Sender = botSender
+
+	mainMenu := handlers.NewMainMenuHandler(tg.Api, log)
+	serviceCards := handlers.NewServiceCards(serviceRepo, botSender, cfg.Bot.MediaDir, log)
+	boxSolutions := handlers.NewBoxSolutionsHandler(tg.Api, log, serviceRepo, serviceCards)
+	userRepo := repository.NewUserRepository(repository.NewDBAdapter(db), log)
+	sessionRepo := repository.NewSessionRepository(sqlxDB, log)
+
+	var (
+		bookingStore state.Store[models.BookingState]
+		adminStore   state.Store[models.AdminServiceState]
+		staleStates  state.StaleSessions
+	)
+	if cfg.Bot.StateStore == config.StateStorePostgres {
+		bookingStore = state.NewSessionStore[models.BookingState](handlers.BookingFormState, sessionRepo, userRepo)
+		adminStore = state.NewSessionStore[models.AdminServiceState](handlers.AdminServicesState, sessionRepo, userRepo)
+		staleStates = sessionRepo
+	} else {
+		bookingMemory := state.NewMemoryStore[models.BookingState](handlers.BookingFormState)
+		adminMemory := state.NewMemoryStore[models.AdminServiceState](handlers.AdminServicesState)
+		bookingStore, adminStore = bookingMemory, adminMemory
+		staleStates = state.StaleSources{bookingMemory, adminMemory}
+	}
+
+	bookingForm := handlers.NewBookingFormHandler(tg.Api, bookingRepo, bookingStore, log)
+	bookingForm.SetTTL(cfg.Bot.StateTTL)
+	bookingForm.SetServiceCatalog(serviceRepo)
+	adminBookings := handlers.NewAdminBookingsHandler(bookingRepo, handlers.Sender, cfg.Admin.ChatID, log)
+	bookingForm.OnBookingCreated(adminBookings.NotifyNewBooking)
+	adminServices := handlers.NewAdminServicesHandler(serviceRepo, userRepo, adminStore, handlers.Sender, log)
+	calendar := handlers.NewCalendarHandler(bookingRepo, botSender, handlers.Sender, calendarURL, bookingTZ, log)
+	bookingForm.OnBookingCreated(calendar.SendInvite)
+
+	jobRepo := repository.NewScheduledJobRepository(sqlxDB, log)
+	reminders := handlers.NewBookingReminders(bookingRepo, jobRepo, handlers.Sender, cfg.Reminders.Offsets, bookingTZ, log)
+	bookingForm.OnBookingCreated(reminders.Schedule)
+	jobs := scheduler.New(jobRepo, scheduler.Config{}, log)
+	jobs.Register(handlers.JobBookingReminder, reminders.Handle)
+
+	waitlistRepo := repository.NewWaitlistRepository(bookingRepo)
+	bookingForm.EnableWaitlist(waitlistRepo)
+	waitlist := handlers.NewWaitlistHandler(waitlistRepo, handlers.Sender, bookingTZ, log)
+	waitlist.OnBookingCreated(adminBookings.NotifyNewBooking)
+	waitlist.OnBookingCreated(calendar.SendInvite)
+	waitlist.OnBookingCreated(reminders.Schedule)
+	jobs.Register(repository.JobWaitlistOffer, waitlist.HandleOfferJob)
+	jobs.Register(repository.JobWaitlistExpire, waitlist.HandleExpireJob)
+
+	d := dispatcher.New(tg.Api, log)
+	d.Use(
+		middleware.ErrorReply(handlers.Sender, log),
+		middleware.Logging(log),
+		middleware.Metrics(m),
+		middleware.Recover(log),
+		middleware.Timeout(cfg.Bot.UpdateTimeout),
+		middleware.RateLimit(cfg.Bot.UserRateLimit, cfg.Bot.UserRateBurst),
+		middleware.Auth(userRepo.IsAdmin, middleware.ActionPrefix("/admin", "admin_")),
+	)
+	d.HandleCommand("start", handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+		return handlers.HandleStart(ctx, tg.Api, msg, log, db)
+	}))
+	d.HandleCallback(handlers.CallbackBackToMain, mainMenu)
+	d.HandleCallback(handlers.CallbackBoxSolutions, boxSolutions)
+	d.HandleCallback(handlers.CallbackBackToBoxSolutions, boxSolutions)
+	d.HandleCallback("box_{id:int}", boxSolutions)
+	d.HandleCallbackPrefix(handlers.CallbackBoxCategories+":", boxSolutions)
+	d.HandleCallbackPrefix(handlers.CallbackBoxServices+":", boxSolutions)
+	d.HandleCallback("option:{service:int}:{idx:int}", bookingForm)
+	d.HandleCallback("book_now:{service:int}", bookingForm)
+	myBookings := handlers.NewMyBookingsHandler(bookingRepo, handlers.Sender, log)
+	myBookings.OnBookingRescheduled(reminders.Schedule)
+	d.HandleCommand("mybookings", handlers.MessageHandlerFunc(myBookings.HandleCommand))
+	d.HandleCommand("calendar", handlers.MessageHandlerFunc(calendar.HandleCommand))
+	d.HandleCallback(handlers.CallbackCalendarFeed, handlers.CallbackHandlerFunc(calendar.Handle))
+	d.HandleCallback(handlers.CallbackMyBookings, handlers.CallbackHandlerFunc(myBookings.List))
+	d.HandleCallback(handlers.CallbackMyBookingsPage, handlers.CallbackHandlerFunc(myBookings.List))
+	d.HandleCallback(handlers.CallbackBookingDetails, handlers.CallbackHandlerFunc(myBookings.Details))
+	d.HandleCallback(handlers.CallbackBookingCancel, handlers.CallbackHandlerFunc(myBookings.Cancel))
+	d.HandleCallback(handlers.CallbackBookingCancelYes, handlers.CallbackHandlerFunc(myBookings.ConfirmCancel))
+	d.HandleCallback(handlers.CallbackBookingReschedule, handlers.CallbackHandlerFunc(myBookings.Reschedule))
+	d.HandleCallback(handlers.CallbackRescheduleDate, handlers.CallbackHandlerFunc(myBookings.RescheduleDate))
+	d.HandleCallback(handlers.CallbackRescheduleDateTime, handlers.CallbackHandlerFunc(myBookings.RescheduleDate))
+	d.HandleCallback(handlers.CallbackWaitlistClaim, handlers.CallbackHandlerFunc(waitlist.Claim))
+	d.HandleCallback(handlers.CallbackWaitlistDecline, handlers.CallbackHandlerFunc(waitlist.Decline))
+	d.HandleCallback(handlers.CallbackAdminBookingApprove, handlers.CallbackHandlerFunc(adminBookings.Approve))
+	d.HandleCallback(handlers.CallbackAdminBookingReject, handlers.CallbackHandlerFunc(adminBookings.Reject))
+	d.HandleCommand("admin", handlers.MessageHandlerFunc(adminServices.HandleCommand))
+	d.HandleCallbackPrefix(handlers.CallbackAdminServices, handlers.CallbackHandlerFunc(adminServices.Handle))
+	d.HandleCallbackFallback(handlers.NewNotAvailableHandler(tg.Api, log))
+	d.Conversation(bookingForm)
+	d.Conversation(adminServices)
+	d.HandleText(handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+		return handlers.HandleUnknownMessage(tg.Api, msg, log)
+	}))
+	d.HandleChatMember(func(ctx context.Context, upd *tgbotapi.ChatMemberUpdated) error {
+		return handlers.HandleMyChatMember(ctx, upd, log)
+	})
+
+	pool := dispatcher.NewPool(cfg.Bot.Workers, cfg.Bot.QueueSize, m, log)
+	d.UsePool(pool)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	updates, err := tg.GetUpdates(ctx, 30*time.Second)
-	if err != nil {
-		log.Fatal("failed_to_get_updates", zap.Error(err))
+	go jobs.Run(ctx)
+	shutdownTasks = append(shutdownTasks, shutdown.ShutdownTask{Name: "scheduler", Fn: jobs.Shutdown})
+
+	conversations := []string{handlers.BookingFormState, handlers.AdminServicesState}
+	stateSweeper := state.NewSweeper(staleStates, conversations, cfg.Bot.StateTTL, cfg.Bot.StateSweepInterval, m, log)
+	stateSweeper.OnExpired(bookingForm.NotifyExpired)
+	go stateSweeper.Run(ctx)
+	shutdownTasks = append(shutdownTasks, shutdown.ShutdownTask{Name: "state_sweeper", Fn: stateSweeper.Shutdown})
+
+	if cfg.Tracker.Token != "" {
+		trackerWorker := tracker.NewWorker(
+			repository.NewTrackerOutboxRepository(sqlxDB, log),
+			bookingRepo,
+			tracker.NewHTTPClient(cfg.Tracker.BaseURL, cfg.Tracker.Token, cfg.Tracker.OrgID),
+			tracker.WorkerConfig{
+				Queue:        cfg.Tracker.Queue,
+				PollInterval: cfg.Tracker.PollInterval,
+				BatchSize:    cfg.Tracker.BatchSize,
+				MaxAttempts:  cfg.Tracker.MaxAttempts,
+			},
+			m, log,
+		)
+		go trackerWorker.Run(ctx)
+		shutdownTasks = append(shutdownTasks, shutdown.ShutdownTask{Name: "tracker_worker", Fn: trackerWorker.Shutdown})
+	} else {
+		log.Warn("tracker_integration_disabled")
+	}
+
+	var updates tgbotapi.UpdatesChannel
+	if webhook != nil {
+		if err := tg.SetWebhook(cfg.Telegram.Webhook.URL, cfg.Telegram.Webhook.Secret); err != nil {
+			log.Fatal("failed_to_set_webhook", zap.Error(err))
+		}
+		updates = webhook.Updates()
+	} else {
+		updates, err = tg.GetUpdates(ctx, 30*time.Second)
+		if err != nil {
+			log.Fatal("failed_to_get_updates", zap.Error(err))
+		}
 	}
 
 	// graceful shutdown: по SIGINT/SIGTERM отменяем контекст
 	sh := shutdown.NewShutdownHandler(log)
+	shutdownDone := make(chan struct{})
 	go func() {
-		if err := sh.WaitForShutdown(ctx, cancel,
-			shutdown.ShutdownTask{
-				Name: "http_server",
-				Fn:   httpSrv.Shutdown,
-			},
-		); err != nil {
+		defer close(shutdownDone)
+		tasks := append(shutdownTasks, shutdown.ShutdownTask{Name: "update_workers", Fn: pool.Shutdown})
+		if err := sh.WaitForShutdown(ctx, cancel, tasks...); err != nil {
 			log.Error("Graceful shutdown completed with errors", zap.Error(err))
 		}
 	}()
 
-	for update := range updates {
-		if update.Message != nil && update.Message.IsCommand() && update.Message.Command() == "start" {
-			user := update.Message.From
-
-			// Сохраняем пользователя через существующий репозиторий
-			_, err, _ := userRepo.CreateUser(
-				ctx,
-				user.ID,
-				user.UserName,
-				user.FirstName,
-				user.LastName,
-			)
-			if err != nil {
-				log.Warn("failed_to_save_user",
-					zap.Int64("user_id", user.ID),
-					zap.Error(err),
-				)
-			}
-
-			// 2. Вызываем хендлер
-			if err := handlers.HandleStart(tg.Api, update.Message, log, db); err != nil {
-				log.Error("handle_start_failed",
-					zap.Int64("user_id", user.ID),
-					zap.Error(err),
-				)
-			}
-
-			continue
-		}
-	}
+	d.Run(ctx, updates)
+
+	// ждём, пока shutdown-задачи (в том числе дообработка очереди) завершатся
+	cancel()
+	<-shutdownDone
 }
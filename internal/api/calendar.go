@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/ics"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// CalendarFeedPath — префикс адреса календаря: /calendar/{telegram_id}.ics?token=...
+const CalendarFeedPath = "/calendar/"
+
+// CalendarBookings возвращает подтверждённые бронирования пользователя.
+type CalendarBookings interface {
+	ListConfirmedBookings(ctx context.Context, telegramID int64) ([]models.Booking, error)
+}
+
+// CalendarFeed отдаёт подтверждённые бронирования пользователя в формате iCalendar.
+// Доступ защищён токеном — HMAC от Telegram ID пользователя.
+type CalendarFeed struct {
+	repo   CalendarBookings
+	secret []byte
+	loc    *time.Location
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewCalendarFeed создаёт обработчик календаря. loc — часовой пояс для услуг без своего пояса.
+func NewCalendarFeed(repo CalendarBookings, secret string, loc *time.Location, logger *zap.Logger) *CalendarFeed {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &CalendarFeed{repo: repo, secret: []byte(secret), loc: loc, logger: logger, now: time.Now}
+}
+
+// Token возвращает токен доступа к календарю пользователя.
+func (f *CalendarFeed) Token(telegramID int64) string {
+	mac := hmac.New(sha256.New, f.secret)
+	fmt.Fprintf(mac, "calendar:%d", telegramID)
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// URL возвращает адрес календаря пользователя относительно публичного адреса сервера.
+func (f *CalendarFeed) URL(baseURL string, telegramID int64) string {
+	return fmt.Sprintf("%s%s%d.ics?token=%s", strings.TrimRight(baseURL, "/"), CalendarFeedPath, telegramID, f.Token(telegramID))
+}
+
+func (f *CalendarFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, CalendarFeedPath)
+	telegramID, err := strconv.ParseInt(strings.TrimSuffix(name, ".ics"), 10, 64)
+	if err != nil || !strings.HasSuffix(name, ".ics") {
+		http.NotFound(w, r)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if !hmac.Equal([]byte(token), []byte(f.Token(telegramID))) {
+		// не раскрываем, существует ли пользователь
+		http.NotFound(w, r)
+		return
+	}
+
+	bookings, err := f.repo.ListConfirmedBookings(r.Context(), telegramID)
+	if err != nil {
+		f.logger.Error("calendar_feed_failed", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	cal := ics.Calendar{Name: "Мои бронирования"}
+	for i := range bookings {
+		cal.Events = append(cal.Events, ics.BookingEvent(&bookings[i], f.loc))
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if err := ics.Write(w, cal, f.now()); err != nil {
+		f.logger.Warn("calendar_feed_write_failed", zap.Error(err))
+	}
+}
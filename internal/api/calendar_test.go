@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+type fakeCalendarBookings struct {
+	bookings map[int64][]models.Booking
+	err      error
+}
+
+func (f *fakeCalendarBookings) ListConfirmedBookings(_ context.Context, telegramID int64) ([]models.Booking, error) {
+	return f.bookings[telegramID], f.err
+}
+
+func newTestFeed(repo CalendarBookings) *CalendarFeed {
+	return NewCalendarFeed(repo, "secret", time.UTC, zap.NewNop())
+}
+
+func TestCalendarFeed_ServesUserBookings(t *testing.T) {
+	repo := &fakeCalendarBookings{bookings: map[int64][]models.Booking{
+		777: {{
+			ID:              12,
+			ServiceTitle:    "Теннис в Лужниках",
+			ServiceTimezone: "Europe/Moscow",
+			BookingDate:     time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+			BookingTime:     sql.NullString{String: "18:00", Valid: true},
+			Status:          models.BookingStatusConfirmed,
+		}},
+	}}
+	feed := newTestFeed(repo)
+
+	u := feed.URL("https://bot.example.com/", 777)
+	require.True(t, strings.HasPrefix(u, "https://bot.example.com/calendar/777.ics?token="))
+
+	rec := httptest.NewRecorder()
+	feed.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(u, "https://bot.example.com"), nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "DTSTART;TZID=Europe/Moscow:20260314T180000")
+	assert.Contains(t, rec.Body.String(), "SUMMARY:Теннис в Лужниках")
+}
+
+func TestCalendarFeed_RejectsWrongToken(t *testing.T) {
+	feed := newTestFeed(&fakeCalendarBookings{})
+
+	for _, target := range []string{
+		"/calendar/777.ics",
+		"/calendar/777.ics?token=" + feed.Token(778),
+		"/calendar/abc.ics?token=" + feed.Token(777),
+		"/calendar/777?token=" + feed.Token(777),
+	} {
+		rec := httptest.NewRecorder()
+		feed.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, target)
+	}
+}
+
+func TestCalendarFeed_RepositoryError(t *testing.T) {
+	feed := newTestFeed(&fakeCalendarBookings{err: errors.New("db is down")})
+
+	rec := httptest.NewRecorder()
+	feed.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/777.ics?token="+feed.Token(777), nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -3,26 +3,44 @@ package bot
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
 )
 
+// allowedUpdates — типы апдейтов, которые обрабатывает диспетчер.
+var allowedUpdates = []string{
+	"message",
+	"callback_query",
+	"my_chat_member",
+}
+
 type TelegramBot struct {
 	Api    *tgbotapi.BotAPI
 	logger *zap.Logger
 }
 
 func NewTelegramBot(token string, logger *zap.Logger) (*TelegramBot, error) {
+	return NewTelegramBotWithEndpoint(token, tgbotapi.APIEndpoint, logger)
+}
+
+// NewTelegramBotWithEndpoint создаёт бота для другого адреса Bot API
+// (локальный Bot API server или тестовый сервер). Формат endpoint — как у
+// tgbotapi.APIEndpoint: "https://api.telegram.org/bot%s/%s".
+func NewTelegramBotWithEndpoint(token, endpoint string, logger *zap.Logger) (*TelegramBot, error) {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
 	if token == "" {
 		return nil, fmt.Errorf("telegram token is empty")
 	}
+	if endpoint == "" {
+		endpoint = tgbotapi.APIEndpoint
+	}
 
-	api, err := tgbotapi.NewBotAPI(token)
+	api, err := tgbotapi.NewBotAPIWithClient(token, endpoint, &http.Client{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create telegram bot api: %w", err)
 	}
@@ -55,13 +73,14 @@ func (bot *TelegramBot) GetUpdates(ctx context.Context, timeout time.Duration) (
 		timeout = time.Second * 30
 	}
 
+	// getUpdates не работает, пока у бота установлен webhook
+	if err := bot.DeleteWebhook(); err != nil {
+		bot.logger.Warn("failed_to_delete_webhook", zap.Error(err))
+	}
+
 	cfg := tgbotapi.NewUpdate(0)
 	cfg.Timeout = int(timeout.Seconds())
-	cfg.AllowedUpdates = []string{
-		"message",
-		"callback_query",
-		"my_chat_member",
-	}
+	cfg.AllowedUpdates = allowedUpdates
 
 	updates := bot.Api.GetUpdatesChan(cfg)
 
@@ -0,0 +1,243 @@
+package dispatcher
+
+import (
+	"context"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/bot/middleware"
+	"github.com/yandex-development-2-team/Go/internal/handlers"
+)
+
+// ChatMemberHandlerFunc обрабатывает изменение статуса бота в чате (my_chat_member).
+type ChatMemberHandlerFunc func(ctx context.Context, upd *tgbotapi.ChatMemberUpdated) error
+
+// Conversation — многошаговый диалог, который получает текст и колбэки пользователя,
+// пока для него есть активное состояние (например, форма бронирования).
+type Conversation interface {
+	IsActive(ctx context.Context, userID int64) bool
+	HandleUpdate(ctx context.Context, update tgbotapi.Update) error
+}
+
+// Requester отправляет служебные запросы в Telegram API (например, ответ на callback query).
+type Requester interface {
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+// Dispatcher маршрутизирует апдейты Telegram по зарегистрированным обработчикам:
+// команды — по имени, callback query — через handlers.CallbackRouter,
+// текст — в активный диалог пользователя, my_chat_member — в отдельный обработчик.
+type Dispatcher struct {
+	api    Requester
+	logger *zap.Logger
+
+	commands      map[string]handlers.MessageHandler
+	callbacks     *handlers.CallbackRouter
+	conversations []Conversation
+	text          handlers.MessageHandler
+	chatMember    ChatMemberHandlerFunc
+	middlewares   []middleware.Middleware
+	pool          *Pool
+}
+
+func New(api Requester, logger *zap.Logger) *Dispatcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Dispatcher{
+		api:       api,
+		logger:    logger,
+		commands:  make(map[string]handlers.MessageHandler),
+		callbacks: handlers.NewCallbackRouter(logger),
+	}
+}
+
+// Use добавляет middleware, которые оборачивают обработку каждого сообщения и callback query.
+func (d *Dispatcher) Use(mws ...middleware.Middleware) {
+	d.middlewares = append(d.middlewares, mws...)
+}
+
+// UsePool включает параллельную обработку апдейтов в пуле воркеров
+// с сохранением порядка внутри одного чата.
+func (d *Dispatcher) UsePool(p *Pool) {
+	d.pool = p
+}
+
+// HandleCommand регистрирует обработчик команды без ведущего "/" (например, "start").
+func (d *Dispatcher) HandleCommand(command string, h handlers.MessageHandler) {
+	d.commands[strings.TrimPrefix(command, "/")] = h
+}
+
+// HandleCallback регистрирует обработчик callback data: точное значение
+// или шаблон с параметрами, например "option:{service:int}:{idx:int}".
+func (d *Dispatcher) HandleCallback(pattern string, h handlers.CallbackHandler) {
+	d.callbacks.Handle(pattern, h)
+}
+
+// HandleCallbackPrefix регистрирует обработчик для callback data с указанным префиксом.
+func (d *Dispatcher) HandleCallbackPrefix(prefix string, h handlers.CallbackHandler) {
+	d.callbacks.HandlePrefix(prefix, h)
+}
+
+// HandleCallbackFallback задаёт обработчик для callback data без маршрута
+// и вне активных диалогов.
+func (d *Dispatcher) HandleCallbackFallback(h handlers.CallbackHandler) {
+	d.callbacks.SetFallback(h)
+}
+
+// Conversation регистрирует диалог, получающий текст и неизвестные колбэки активных пользователей.
+func (d *Dispatcher) Conversation(c Conversation) {
+	d.conversations = append(d.conversations, c)
+}
+
+// HandleText регистрирует обработчик текста вне активных диалогов.
+func (d *Dispatcher) HandleText(h handlers.MessageHandler) {
+	d.text = h
+}
+
+// HandleChatMember регистрирует обработчик апдейтов my_chat_member.
+func (d *Dispatcher) HandleChatMember(h ChatMemberHandlerFunc) {
+	d.chatMember = h
+}
+
+// Run читает апдейты из канала и обрабатывает их до закрытия канала или отмены контекста.
+// Если подключён пул, Run только ставит апдейты в очередь: уже принятые апдейты
+// дорабатываются после отмены ctx, их ожидание выполняет Pool.Shutdown.
+func (d *Dispatcher) Run(ctx context.Context, updates tgbotapi.UpdatesChannel) {
+	// обработка не должна прерываться вместе с приёмом апдейтов при остановке бота
+	handleCtx := context.WithoutCancel(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+
+			if d.pool == nil {
+				d.handle(handleCtx, update)
+				continue
+			}
+
+			if err := d.pool.Submit(ctx, updateKey(update), func() { d.handle(handleCtx, update) }); err != nil {
+				d.logger.Error("update_enqueue_failed",
+					zap.Int("update_id", update.UpdateID),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) handle(ctx context.Context, update tgbotapi.Update) {
+	if err := d.Dispatch(ctx, update); err != nil {
+		d.logger.Error("update_handling_failed",
+			zap.Int("update_id", update.UpdateID),
+			zap.Error(err),
+		)
+	}
+}
+
+// updateKey возвращает ключ упорядочивания апдейта: ID чата, а если его нет — ID пользователя.
+func updateKey(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.Chat != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil && update.CallbackQuery.Message.Chat != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.MyChatMember != nil:
+		return update.MyChatMember.Chat.ID
+	}
+	if from := update.SentFrom(); from != nil {
+		return from.ID
+	}
+	return 0
+}
+
+// Dispatch обрабатывает один апдейт.
+func (d *Dispatcher) Dispatch(ctx context.Context, update tgbotapi.Update) error {
+	switch {
+	case update.Message != nil:
+		return d.dispatchMessage(ctx, update)
+	case update.CallbackQuery != nil:
+		return d.dispatchCallback(ctx, update)
+	case update.MyChatMember != nil:
+		if d.chatMember == nil {
+			return nil
+		}
+		return d.chatMember(ctx, update.MyChatMember)
+	default:
+		d.logger.Debug("update_skipped", zap.Int("update_id", update.UpdateID))
+		return nil
+	}
+}
+
+func (d *Dispatcher) dispatchMessage(ctx context.Context, update tgbotapi.Update) error {
+	h := d.messageHandler(ctx, update)
+	if h == nil {
+		return nil
+	}
+	return middleware.WrapMessage(h, d.middlewares...).Handle(ctx, update.Message)
+}
+
+// messageHandler выбирает обработчик сообщения: команда, активный диалог или текст по умолчанию.
+func (d *Dispatcher) messageHandler(ctx context.Context, update tgbotapi.Update) handlers.MessageHandler {
+	msg := update.Message
+
+	if msg.IsCommand() {
+		if h, ok := d.commands[msg.Command()]; ok {
+			return h
+		}
+		d.logger.Info("unknown_command", zap.String("command", msg.Command()))
+	}
+
+	if msg.From != nil {
+		if c := d.activeConversation(ctx, msg.From.ID); c != nil {
+			return handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+				return c.HandleUpdate(ctx, update)
+			})
+		}
+	}
+
+	return d.text
+}
+
+func (d *Dispatcher) dispatchCallback(ctx context.Context, update tgbotapi.Update) error {
+	q := update.CallbackQuery
+	defer d.answerCallback(q)
+
+	var h handlers.CallbackHandler = handlers.CallbackHandlerFunc(d.callbacks.Route)
+
+	if _, _, ok := d.callbacks.Match(q.Data); !ok && q.From != nil {
+		if c := d.activeConversation(ctx, q.From.ID); c != nil {
+			h = handlers.CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+				return c.HandleUpdate(ctx, update)
+			})
+		}
+	}
+
+	return middleware.WrapCallback(h, d.middlewares...).Handle(ctx, q)
+}
+
+func (d *Dispatcher) activeConversation(ctx context.Context, userID int64) Conversation {
+	for _, c := range d.conversations {
+		if c.IsActive(ctx, userID) {
+			return c
+		}
+	}
+	return nil
+}
+
+// answerCallback убирает индикатор загрузки на кнопке у пользователя.
+func (d *Dispatcher) answerCallback(q *tgbotapi.CallbackQuery) {
+	if d.api == nil || q.ID == "" {
+		return
+	}
+	if _, err := d.api.Request(tgbotapi.NewCallback(q.ID, "")); err != nil {
+		d.logger.Warn("answer_callback_failed", zap.String("callback_id", q.ID), zap.Error(err))
+	}
+}
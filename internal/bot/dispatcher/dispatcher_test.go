@@ -0,0 +1,209 @@
+package dispatcher
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/bot/middleware"
+	"github.com/yandex-development-2-team/Go/internal/handlers"
+)
+
+type fakeRequester struct {
+	requests []tgbotapi.Chattable
+}
+
+func (f *fakeRequester) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.requests = append(f.requests, c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+type recordingCallback struct {
+	calls []string
+}
+
+func (r *recordingCallback) Handle(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	r.calls = append(r.calls, q.Data)
+	return nil
+}
+
+type fakeConversation struct {
+	active  map[int64]bool
+	updates []tgbotapi.Update
+}
+
+func (c *fakeConversation) IsActive(ctx context.Context, userID int64) bool {
+	return c.active[userID]
+}
+
+func (c *fakeConversation) HandleUpdate(ctx context.Context, update tgbotapi.Update) error {
+	c.updates = append(c.updates, update)
+	return nil
+}
+
+func commandUpdate(userID int64, text string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From:     &tgbotapi.User{ID: userID},
+		Chat:     &tgbotapi.Chat{ID: userID},
+		Text:     text,
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}},
+	}}
+}
+
+func textUpdate(userID int64, text string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: userID},
+		Chat: &tgbotapi.Chat{ID: userID},
+		Text: text,
+	}}
+}
+
+func callbackUpdate(userID int64, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: userID},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: userID}},
+		Data:    data,
+	}}
+}
+
+func TestDispatch_Command(t *testing.T) {
+	d := New(nil, zap.NewNop())
+
+	var got string
+	d.HandleCommand("/start", handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+		got = msg.Text
+		return nil
+	}))
+
+	err := d.Dispatch(context.Background(), commandUpdate(1, "/start"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/start", got)
+}
+
+func TestDispatch_CallbackExactAndPrefix(t *testing.T) {
+	api := &fakeRequester{}
+	d := New(api, zap.NewNop())
+
+	menu := &recordingCallback{}
+	item := &recordingCallback{}
+	option := &recordingCallback{}
+	d.HandleCallback("box_solutions", menu)
+	d.HandleCallback("box_{id:int}", item)
+	d.HandleCallbackPrefix("option:", option)
+
+	for _, data := range []string{"box_solutions", "box_3", "option:1:0"} {
+		assert.NoError(t, d.Dispatch(context.Background(), callbackUpdate(1, data)))
+	}
+
+	assert.Equal(t, []string{"box_solutions"}, menu.calls)
+	assert.Equal(t, []string{"box_3"}, item.calls)
+	assert.Equal(t, []string{"option:1:0"}, option.calls)
+	assert.Len(t, api.requests, 3, "каждый callback должен получить ответ")
+}
+
+func TestDispatch_PatternArgs(t *testing.T) {
+	d := New(nil, zap.NewNop())
+
+	var service, idx int
+	d.HandleCallback("option:{service:int}:{idx:int}", handlers.CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+		args := handlers.CallbackArgsFromContext(ctx)
+		service, _ = args.Int("service")
+		idx, _ = args.Int("idx")
+		return nil
+	}))
+
+	assert.NoError(t, d.Dispatch(context.Background(), callbackUpdate(1, "option:3:1")))
+
+	assert.Equal(t, 3, service)
+	assert.Equal(t, 1, idx)
+}
+
+func TestDispatch_CallbackFallback(t *testing.T) {
+	d := New(nil, zap.NewNop())
+
+	fallback := &recordingCallback{}
+	d.HandleCallbackFallback(fallback)
+
+	assert.NoError(t, d.Dispatch(context.Background(), callbackUpdate(1, "visit_guide")))
+	assert.Equal(t, []string{"visit_guide"}, fallback.calls)
+}
+
+func TestDispatch_ConversationReceivesTextAndUnknownCallbacks(t *testing.T) {
+	d := New(nil, zap.NewNop())
+
+	conv := &fakeConversation{active: map[int64]bool{7: true}}
+	d.Conversation(conv)
+
+	var fallback []string
+	d.HandleText(handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+		fallback = append(fallback, msg.Text)
+		return nil
+	}))
+
+	assert.NoError(t, d.Dispatch(context.Background(), textUpdate(7, "Иван Иванов")))
+	assert.NoError(t, d.Dispatch(context.Background(), callbackUpdate(7, "2026-03-01")))
+	assert.NoError(t, d.Dispatch(context.Background(), textUpdate(8, "привет")))
+
+	assert.Len(t, conv.updates, 2)
+	assert.Equal(t, []string{"привет"}, fallback)
+}
+
+func TestDispatch_ChatMember(t *testing.T) {
+	d := New(nil, zap.NewNop())
+
+	var status string
+	d.HandleChatMember(func(ctx context.Context, upd *tgbotapi.ChatMemberUpdated) error {
+		status = upd.NewChatMember.Status
+		return nil
+	})
+
+	update := tgbotapi.Update{MyChatMember: &tgbotapi.ChatMemberUpdated{
+		NewChatMember: tgbotapi.ChatMember{Status: "kicked"},
+	}}
+
+	assert.NoError(t, d.Dispatch(context.Background(), update))
+	assert.Equal(t, "kicked", status)
+}
+
+func TestRun_StopsWhenChannelClosed(t *testing.T) {
+	d := New(nil, zap.NewNop())
+
+	var handled int
+	d.HandleText(handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+		handled++
+		return nil
+	}))
+
+	ch := make(chan tgbotapi.Update, 2)
+	ch <- textUpdate(1, "a")
+	ch <- textUpdate(1, "b")
+	close(ch)
+
+	d.Run(context.Background(), ch)
+
+	assert.Equal(t, 2, handled)
+}
+
+func TestDispatch_MiddlewareWrapsMessagesAndCallbacks(t *testing.T) {
+	d := New(nil, zap.NewNop())
+
+	var seen []string
+	d.Use(func(ctx context.Context, req middleware.Request, next middleware.Next) error {
+		seen = append(seen, req.Kind+":"+req.Action)
+		return next(ctx)
+	})
+	d.HandleCommand("start", handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+		return nil
+	}))
+	d.HandleCallback("box_solutions", &recordingCallback{})
+
+	assert.NoError(t, d.Dispatch(context.Background(), commandUpdate(1, "/start")))
+	assert.NoError(t, d.Dispatch(context.Background(), callbackUpdate(1, "box_solutions")))
+
+	assert.Equal(t, []string{"message:/start", "callback:box_solutions"}, seen)
+}
@@ -0,0 +1,178 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/metrics"
+)
+
+// ErrPoolClosed возвращается при попытке добавить задачу в остановленный пул.
+var ErrPoolClosed = errors.New("worker pool is closed")
+
+type poolJob struct {
+	fn       func()
+	queuedAt time.Time
+}
+
+// Pool — ограниченный пул воркеров. Задачи с разными ключами (чатами) выполняются
+// параллельно, задачи с одним ключом — строго по очереди в порядке добавления.
+// Общее число ожидающих задач ограничено queueSize: при переполнении Submit блокируется.
+type Pool struct {
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+
+	slots chan struct{} // свободные места в очереди
+	ready chan int64    // ключи, у которых есть задачи и которые никто не обрабатывает
+
+	mu        sync.Mutex
+	queues    map[int64][]poolJob
+	closed    bool
+	closeOnce sync.Once
+
+	wg sync.WaitGroup
+}
+
+func NewPool(workers, queueSize int, m *metrics.Metrics, logger *zap.Logger) *Pool {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < workers {
+		queueSize = workers
+	}
+
+	p := &Pool{
+		logger:  logger,
+		metrics: m,
+		slots:   make(chan struct{}, queueSize),
+		// в ready не может оказаться больше ключей, чем задач в очереди
+		ready:  make(chan int64, queueSize),
+		queues: make(map[int64][]poolJob),
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Submit ставит задачу в очередь ключа. Если очередь заполнена, ждёт освобождения места
+// или отмены контекста.
+func (p *Pool) Submit(ctx context.Context, key int64, fn func()) error {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		if p.metrics != nil {
+			p.metrics.UpdatesBackpressureTotal.Inc()
+		}
+		p.logger.Warn("update_queue_full", zap.Int("queue_size", cap(p.slots)))
+
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		<-p.slots
+		return ErrPoolClosed
+	}
+
+	queue, busy := p.queues[key]
+	p.queues[key] = append(queue, poolJob{fn: fn, queuedAt: time.Now()})
+	if !busy {
+		p.ready <- key
+	}
+
+	if p.metrics != nil {
+		p.metrics.UpdatesQueueDepth.Inc()
+	}
+	return nil
+}
+
+func (p *Pool) worker() {
+	defer p.wg.Done()
+
+	for key := range p.ready {
+		p.mu.Lock()
+		job := p.queues[key][0]
+		p.mu.Unlock()
+
+		p.run(key, job)
+
+		p.mu.Lock()
+		rest := p.queues[key][1:]
+		if len(rest) == 0 {
+			delete(p.queues, key)
+			if p.closed && len(p.queues) == 0 {
+				p.closeReady()
+			}
+		} else {
+			// отдаём ключ в конец очереди, чтобы активный чат не занимал воркер целиком
+			p.queues[key] = rest
+			p.ready <- key
+		}
+		p.mu.Unlock()
+
+		<-p.slots
+	}
+}
+
+func (p *Pool) run(key int64, job poolJob) {
+	if p.metrics != nil {
+		p.metrics.UpdatesQueueDepth.Dec()
+		p.metrics.UpdatesQueueWaitDuration.Observe(time.Since(job.queuedAt).Seconds())
+		p.metrics.UpdatesInFlight.Inc()
+		defer p.metrics.UpdatesInFlight.Dec()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("worker_job_panic", zap.Int64("key", key), zap.Any("panic", r))
+		}
+	}()
+
+	job.fn()
+}
+
+// Shutdown перестаёт принимать задачи и ждёт завершения уже поставленных
+// или отмены контекста.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	// пока очередь не пуста, воркеры ещё возвращают ключи в ready —
+	// тогда канал закроет воркер, разобравший последнюю задачу
+	if len(p.queues) == 0 {
+		p.closeReady()
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) closeReady() {
+	p.closeOnce.Do(func() { close(p.ready) })
+}
@@ -0,0 +1,84 @@
+package dispatcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPool_PreservesOrderPerKey(t *testing.T) {
+	p := NewPool(4, 64, nil, zap.NewNop())
+
+	var (
+		mu  sync.Mutex
+		got = map[int64][]int{}
+	)
+	for i := 0; i < 20; i++ {
+		for key := int64(1); key <= 3; key++ {
+			i, key := i, key
+			require.NoError(t, p.Submit(context.Background(), key, func() {
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				got[key] = append(got[key], i)
+				mu.Unlock()
+			}))
+		}
+	}
+
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	for key := int64(1); key <= 3; key++ {
+		assert.Len(t, got[key], 20)
+		for i, v := range got[key] {
+			assert.Equal(t, i, v, "нарушен порядок для ключа %d", key)
+		}
+	}
+}
+
+func TestPool_SlowKeyDoesNotBlockOthers(t *testing.T) {
+	p := NewPool(2, 8, nil, zap.NewNop())
+
+	release := make(chan struct{})
+	require.NoError(t, p.Submit(context.Background(), 1, func() { <-release }))
+
+	done := make(chan struct{})
+	require.NoError(t, p.Submit(context.Background(), 2, func() { close(done) }))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("задача другого чата заблокирована медленным чатом")
+	}
+
+	close(release)
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestPool_Backpressure(t *testing.T) {
+	p := NewPool(1, 1, nil, zap.NewNop())
+
+	release := make(chan struct{})
+	require.NoError(t, p.Submit(context.Background(), 1, func() { <-release }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := p.Submit(ctx, 2, func() {})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestPool_SubmitAfterShutdown(t *testing.T) {
+	p := NewPool(1, 1, nil, zap.NewNop())
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	err := p.Submit(context.Background(), 1, func() {})
+	assert.ErrorIs(t, err, ErrPoolClosed)
+}
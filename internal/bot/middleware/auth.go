@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrForbidden возвращается, если у пользователя нет прав на действие.
+var ErrForbidden = errors.New("access denied")
+
+// AuthFunc проверяет, разрешено ли пользователю действие (например, UserRepository.IsAdmin).
+type AuthFunc func(ctx context.Context, userID int64) (bool, error)
+
+// Auth пропускает к защищённым действиям только пользователей, для которых allowed вернул true.
+// Незащищённые действия (protected вернул false) проходят без проверки.
+func Auth(allowed AuthFunc, protected func(Request) bool) Middleware {
+	return func(ctx context.Context, req Request, next Next) error {
+		if protected == nil || !protected(req) {
+			return next(ctx)
+		}
+
+		ok, err := allowed(ctx, req.UserID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrForbidden, err)
+		}
+		if !ok {
+			return ErrForbidden
+		}
+		return next(ctx)
+	}
+}
+
+// ActionPrefix возвращает селектор действий, начинающихся с одного из префиксов
+// (например, "/admin" для команд и "admin_" для callback data).
+func ActionPrefix(prefixes ...string) func(Request) bool {
+	return func(req Request) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(req.Action, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
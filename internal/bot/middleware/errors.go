@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/handlers"
+)
+
+const (
+	replyInternalError = "Произошла ошибка, попробуйте позже"
+	replyTimeout       = "Сервис отвечает слишком долго, попробуйте ещё раз чуть позже"
+	replyRateLimited   = "Слишком много запросов. Подождите немного и попробуйте снова"
+	replyForbidden     = "У вас нет доступа к этому разделу"
+)
+
+// ErrorReply сообщает пользователю об ошибке обработки понятным текстом.
+// Ошибка возвращается дальше, чтобы внешние middleware (логирование, метрики) её учли.
+func ErrorReply(sender handlers.MessageSender, logger *zap.Logger) Middleware {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return func(ctx context.Context, req Request, next Next) error {
+		err := next(ctx)
+		if err == nil || req.ChatID == 0 {
+			return err
+		}
+
+		if sendErr := sender.SendMessage(req.ChatID, userMessage(err), nil); sendErr != nil {
+			logger.Error("failed_to_send_error_reply", zap.Int64("chat_id", req.ChatID), zap.Error(sendErr))
+		}
+		return err
+	}
+}
+
+func userMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return replyRateLimited
+	case errors.Is(err, ErrForbidden):
+		return replyForbidden
+	case errors.Is(err, context.DeadlineExceeded):
+		return replyTimeout
+	default:
+		return replyInternalError
+	}
+}
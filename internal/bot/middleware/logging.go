@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Logging пишет структурированный лог по каждому обработанному апдейту.
+func Logging(logger *zap.Logger) Middleware {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return func(ctx context.Context, req Request, next Next) error {
+		start := time.Now()
+		err := next(ctx)
+
+		fields := []zap.Field{
+			zap.String("kind", req.Kind),
+			zap.String("action", req.Action),
+			zap.Int64("user_id", req.UserID),
+			zap.Int64("chat_id", req.ChatID),
+			zap.Float64("duration_seconds", time.Since(start).Seconds()),
+			zap.Bool("success", err == nil),
+		}
+		if err != nil {
+			logger.Error("update_handled", append(fields, zap.Error(err))...)
+			return err
+		}
+		logger.Info("update_handled", fields...)
+		return nil
+	}
+}
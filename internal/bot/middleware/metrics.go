@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/yandex-development-2-team/Go/internal/metrics"
+)
+
+// Metrics считает полученные и обработанные апдейты, ошибки и длительность обработки.
+// ActiveUsers отражает количество одновременно обрабатываемых запросов.
+func Metrics(m *metrics.Metrics) Middleware {
+	return func(ctx context.Context, req Request, next Next) error {
+		if m == nil {
+			return next(ctx)
+		}
+
+		start := time.Now()
+
+		m.ActiveUsers.Inc()
+		defer m.ActiveUsers.Dec()
+
+		if req.Kind == KindCallback {
+			m.CallbacksReceived.Inc()
+		} else {
+			m.MessagesReceived.Inc()
+		}
+
+		err := next(ctx)
+
+		dur := time.Since(start).Seconds()
+		if req.Kind == KindCallback {
+			m.CallbacksProcessingDuration.Observe(dur)
+		} else {
+			m.MessageProcessingDuration.Observe(dur)
+		}
+
+		if err != nil {
+			m.MessagesErrorsTotal.Inc()
+			return err
+		}
+		m.MessagesProcessedTotal.Inc()
+		return nil
+	}
+}
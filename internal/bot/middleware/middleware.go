@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/yandex-development-2-team/Go/internal/handlers"
+)
+
+const (
+	KindMessage  = "message"
+	KindCallback = "callback"
+)
+
+// Request описывает обрабатываемый апдейт независимо от его типа.
+type Request struct {
+	Kind   string // KindMessage или KindCallback
+	UserID int64
+	ChatID int64
+	// Action — команда ("/start"), "text" для обычного текста или callback data.
+	Action string
+}
+
+// Next вызывает следующее звено цепочки.
+type Next func(ctx context.Context) error
+
+// Middleware оборачивает обработку сообщения или callback query.
+// Реализация может изменить контекст, не вызывать next или обработать его ошибку.
+type Middleware func(ctx context.Context, req Request, next Next) error
+
+// Chain объединяет middleware в одно; первое в списке выполняется первым (внешнее).
+func Chain(mws ...Middleware) Middleware {
+	return func(ctx context.Context, req Request, next Next) error {
+		return run(ctx, req, mws, next)
+	}
+}
+
+func run(ctx context.Context, req Request, mws []Middleware, last Next) error {
+	if len(mws) == 0 {
+		return last(ctx)
+	}
+	return mws[0](ctx, req, func(ctx context.Context) error {
+		return run(ctx, req, mws[1:], last)
+	})
+}
+
+// WrapMessage оборачивает обработчик сообщений цепочкой middleware.
+func WrapMessage(h handlers.MessageHandler, mws ...Middleware) handlers.MessageHandler {
+	if len(mws) == 0 {
+		return h
+	}
+	chain := Chain(mws...)
+	return handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+		return chain(ctx, MessageRequest(msg), func(ctx context.Context) error {
+			return h.Handle(ctx, msg)
+		})
+	})
+}
+
+// WrapCallback оборачивает обработчик callback query цепочкой middleware.
+func WrapCallback(h handlers.CallbackHandler, mws ...Middleware) handlers.CallbackHandler {
+	if len(mws) == 0 {
+		return h
+	}
+	chain := Chain(mws...)
+	return handlers.CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+		return chain(ctx, CallbackRequest(q), func(ctx context.Context) error {
+			return h.Handle(ctx, q)
+		})
+	})
+}
+
+// MessageRequest описывает сообщение для middleware. Текст сообщения не попадает
+// в Action, чтобы не логировать персональные данные из формы.
+func MessageRequest(msg *tgbotapi.Message) Request {
+	req := Request{Kind: KindMessage, Action: "text"}
+	if msg == nil {
+		return req
+	}
+	if msg.From != nil {
+		req.UserID = msg.From.ID
+	}
+	if msg.Chat != nil {
+		req.ChatID = msg.Chat.ID
+	}
+	if msg.IsCommand() {
+		req.Action = "/" + msg.Command()
+	}
+	return req
+}
+
+// CallbackRequest описывает callback query для middleware.
+func CallbackRequest(q *tgbotapi.CallbackQuery) Request {
+	req := Request{Kind: KindCallback}
+	if q == nil {
+		return req
+	}
+	req.Action = q.Data
+	if q.From != nil {
+		req.UserID = q.From.ID
+		req.ChatID = q.From.ID
+	}
+	if q.Message != nil && q.Message.Chat != nil {
+		req.ChatID = q.Message.Chat.ID
+	}
+	return req
+}
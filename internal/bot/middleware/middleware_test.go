@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/handlers"
+)
+
+type fakeSender struct {
+	chatID int64
+	text   string
+}
+
+func (f *fakeSender) SendMessage(userID int64, text string, buttons [][]handlers.Button) error {
+	f.chatID = userID
+	f.text = text
+	return nil
+}
+
+func callback(userID int64, data string) *tgbotapi.CallbackQuery {
+	return &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: userID},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: userID}},
+		Data:    data,
+	}
+}
+
+func TestChain_Order(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(ctx context.Context, req Request, next Next) error {
+			order = append(order, name+":before")
+			err := next(ctx)
+			order = append(order, name+":after")
+			return err
+		}
+	}
+
+	h := WrapCallback(handlers.CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+		order = append(order, "handler")
+		return nil
+	}), mw("outer"), mw("inner"))
+
+	assert.NoError(t, h.Handle(context.Background(), callback(1, "x")))
+	assert.Equal(t, []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}, order)
+}
+
+func TestRecover(t *testing.T) {
+	h := WrapMessage(handlers.MessageHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) error {
+		panic("boom")
+	}), Recover(zap.NewNop()))
+
+	err := h.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}})
+
+	assert.ErrorIs(t, err, ErrPanic)
+}
+
+func TestTimeout(t *testing.T) {
+	h := WrapCallback(handlers.CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}), Timeout(10*time.Millisecond))
+
+	err := h.Handle(context.Background(), callback(1, "slow"))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimit_PerUser(t *testing.T) {
+	h := WrapCallback(handlers.CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+		return nil
+	}), RateLimit(0.001, 2))
+
+	assert.NoError(t, h.Handle(context.Background(), callback(1, "a")))
+	assert.NoError(t, h.Handle(context.Background(), callback(1, "b")))
+	assert.ErrorIs(t, h.Handle(context.Background(), callback(1, "c")), ErrRateLimited)
+
+	// у другого пользователя свой лимит
+	assert.NoError(t, h.Handle(context.Background(), callback(2, "a")))
+}
+
+func TestAuth(t *testing.T) {
+	isAdmin := func(ctx context.Context, userID int64) (bool, error) {
+		return userID == 42, nil
+	}
+	h := WrapCallback(handlers.CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+		return nil
+	}), Auth(isAdmin, ActionPrefix("admin_")))
+
+	assert.NoError(t, h.Handle(context.Background(), callback(1, "box_solutions")))
+	assert.ErrorIs(t, h.Handle(context.Background(), callback(1, "admin_services")), ErrForbidden)
+	assert.NoError(t, h.Handle(context.Background(), callback(42, "admin_services")))
+}
+
+func TestErrorReply(t *testing.T) {
+	sender := &fakeSender{}
+	handlerErr := errors.New("db is down")
+
+	h := WrapCallback(handlers.CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+		return handlerErr
+	}), ErrorReply(sender, zap.NewNop()))
+
+	err := h.Handle(context.Background(), callback(7, "box_1"))
+
+	assert.ErrorIs(t, err, handlerErr)
+	assert.Equal(t, int64(7), sender.chatID)
+	assert.Equal(t, replyInternalError, sender.text)
+}
+
+func TestMessageRequest_HidesText(t *testing.T) {
+	req := MessageRequest(&tgbotapi.Message{
+		From: &tgbotapi.User{ID: 5},
+		Chat: &tgbotapi.Chat{ID: 6},
+		Text: "Иванов Иван",
+	})
+
+	assert.Equal(t, Request{Kind: KindMessage, UserID: 5, ChatID: 6, Action: "text"}, req)
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited возвращается, если пользователь превысил лимит запросов.
+var ErrRateLimited = errors.New("too many requests")
+
+// limiterIdleTTL — через сколько неиспользуемый лимитер пользователя удаляется из памяти.
+const limiterIdleTTL = 10 * time.Minute
+
+type userLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type userLimiters struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	users     map[int64]*userLimiter
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func (l *userLimiters) allow(userID int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastPrune) > limiterIdleTTL {
+		for id, u := range l.users {
+			if now.Sub(u.lastSeen) > limiterIdleTTL {
+				delete(l.users, id)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	u, ok := l.users[userID]
+	if !ok {
+		u = &userLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.users[userID] = u
+	}
+	u.lastSeen = now
+	return u.limiter.AllowN(now, 1)
+}
+
+// RateLimit ограничивает частоту апдейтов от одного пользователя (token bucket).
+// Лишние апдейты не обрабатываются и завершаются ошибкой ErrRateLimited.
+func RateLimit(perSecond float64, burst int) Middleware {
+	if burst <= 0 {
+		burst = 1
+	}
+	limiters := &userLimiters{
+		limit: rate.Limit(perSecond),
+		burst: burst,
+		users: make(map[int64]*userLimiter),
+		now:   time.Now,
+	}
+	return func(ctx context.Context, req Request, next Next) error {
+		if perSecond <= 0 || req.UserID == 0 {
+			return next(ctx)
+		}
+		if !limiters.allow(req.UserID) {
+			return ErrRateLimited
+		}
+		return next(ctx)
+	}
+}
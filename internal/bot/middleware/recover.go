@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// ErrPanic возвращается, если обработчик запаниковал.
+var ErrPanic = errors.New("handler panicked")
+
+// Recover перехватывает панику обработчика и превращает её в ошибку ErrPanic.
+func Recover(logger *zap.Logger) Middleware {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return func(ctx context.Context, req Request, next Next) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("handler_panic",
+					zap.String("kind", req.Kind),
+					zap.String("action", req.Action),
+					zap.Int64("user_id", req.UserID),
+					zap.Any("panic", r),
+					zap.ByteString("stack", debug.Stack()),
+				)
+				err = fmt.Errorf("%w: %v", ErrPanic, r)
+			}
+		}()
+		return next(ctx)
+	}
+}
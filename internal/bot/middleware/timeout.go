@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"context"
+	"time"
+)
+
+// Timeout ограничивает время обработки одного апдейта.
+func Timeout(d time.Duration) Middleware {
+	return func(ctx context.Context, req Request, next Next) error {
+		if d <= 0 {
+			return next(ctx)
+		}
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return next(ctx)
+	}
+}
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// SecretTokenHeader — заголовок, в котором Telegram передаёт secret_token из setWebhook.
+const SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxWebhookBody ограничивает размер тела запроса с апдейтом.
+const maxWebhookBody = 1 << 20
+
+// WebhookReceiver принимает апдейты, которые Telegram присылает на webhook,
+// и отдаёт их в тот же канал, что и long polling.
+type WebhookReceiver struct {
+	secret  string
+	updates chan tgbotapi.Update
+	logger  *zap.Logger
+}
+
+func NewWebhookReceiver(secret string, buffer int, logger *zap.Logger) *WebhookReceiver {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if buffer <= 0 {
+		buffer = 100
+	}
+	return &WebhookReceiver{
+		secret:  secret,
+		updates: make(chan tgbotapi.Update, buffer),
+		logger:  logger,
+	}
+}
+
+// Updates возвращает канал апдейтов для диспетчера.
+func (w *WebhookReceiver) Updates() tgbotapi.UpdatesChannel {
+	return w.updates
+}
+
+func (w *WebhookReceiver) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.Header.Get(SecretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(w.secret)) != 1 {
+		w.logger.Warn("webhook_invalid_secret_token", zap.String("remote_addr", r.RemoteAddr))
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, maxWebhookBody)).Decode(&update); err != nil {
+		w.logger.Warn("webhook_invalid_update", zap.Error(err))
+		http.Error(rw, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	// Если очередь занята дольше, чем готов ждать Telegram, отвечаем ошибкой —
+	// Telegram повторит доставку апдейта позже.
+	select {
+	case w.updates <- update:
+		rw.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+		w.logger.Warn("webhook_update_dropped", zap.Int("update_id", update.UpdateID))
+		http.Error(rw, "service unavailable", http.StatusServiceUnavailable)
+	}
+}
+
+// SetWebhook регистрирует webhook в Telegram. Параметр secret_token отсутствует
+// в WebhookConfig библиотеки, поэтому запрос собирается вручную.
+func (bot *TelegramBot) SetWebhook(webhookURL, secret string) error {
+	if bot == nil || bot.Api == nil {
+		return fmt.Errorf("telegram bot api is nil")
+	}
+
+	allowed, err := json.Marshal(allowedUpdates)
+	if err != nil {
+		return fmt.Errorf("marshal allowed updates: %w", err)
+	}
+
+	params := tgbotapi.Params{}
+	params["url"] = webhookURL
+	params["secret_token"] = secret
+	params["allowed_updates"] = string(allowed)
+
+	if _, err := bot.Api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+
+	bot.logger.Info("telegram_webhook_set", zap.String("url", webhookURL))
+	return nil
+}
+
+// DeleteWebhook отключает webhook, чтобы заработал getUpdates.
+func (bot *TelegramBot) DeleteWebhook() error {
+	if bot == nil || bot.Api == nil {
+		return fmt.Errorf("telegram bot api is nil")
+	}
+	if _, err := bot.Api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// WebhookServer — отдельный HTTP-сервер для webhook, если он не монтируется
+// на сервер метрик.
+type WebhookServer struct {
+	srv    *http.Server
+	logger *zap.Logger
+}
+
+func NewWebhookServer(port int, path string, receiver *WebhookReceiver, logger *zap.Logger) *WebhookServer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, receiver)
+
+	return &WebhookServer{
+		srv: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+func (s *WebhookServer) Start() error {
+	s.logger.Info("http_webhook_server_started", zap.String("addr", s.srv.Addr))
+	err := s.srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *WebhookServer) Shutdown(ctx context.Context) error {
+	s.logger.Info("http_webhook_server_shutdown")
+	return s.srv.Shutdown(ctx)
+}
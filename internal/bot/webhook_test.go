@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeTelegram — локальная замена Bot API: отвечает на getMe, setWebhook и deleteWebhook
+// и запоминает параметры вызовов.
+type fakeTelegram struct {
+	mu    sync.Mutex
+	calls map[string]map[string]string
+}
+
+func newFakeTelegram(t *testing.T) (*fakeTelegram, *httptest.Server) {
+	t.Helper()
+
+	f := &fakeTelegram{calls: map[string]map[string]string{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		_ = r.ParseForm()
+
+		params := map[string]string{}
+		for k := range r.PostForm {
+			params[k] = r.PostForm.Get(k)
+		}
+		f.mu.Lock()
+		f.calls[method] = params
+		f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch method {
+		case "getMe":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Test","username":"test_bot"}}`))
+		case "setWebhook", "deleteWebhook":
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		default:
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":404,"description":"Not Found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeTelegram) call(method string) (map[string]string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	params, ok := f.calls[method]
+	return params, ok
+}
+
+func TestSetWebhook_SendsSecretToken(t *testing.T) {
+	fake, srv := newFakeTelegram(t)
+
+	tg, err := NewTelegramBotWithEndpoint("123:abc", srv.URL+"/bot%s/%s", zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, tg.SetWebhook("https://bot.example.com/telegram/webhook", "s3cret"))
+
+	params, ok := fake.call("setWebhook")
+	require.True(t, ok)
+	assert.Equal(t, "https://bot.example.com/telegram/webhook", params["url"])
+	assert.Equal(t, "s3cret", params["secret_token"])
+	assert.Contains(t, params["allowed_updates"], "callback_query")
+}
+
+func TestWebhookReceiver(t *testing.T) {
+	receiver := NewWebhookReceiver("s3cret", 1, zap.NewNop())
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"update_id": 42,
+		"callback_query": map[string]interface{}{
+			"id":   "cb1",
+			"from": map[string]interface{}{"id": 7, "is_bot": false, "first_name": "Ivan"},
+			"data": "box_solutions",
+		},
+	})
+	require.NoError(t, err)
+
+	post := func(secret string, payload []byte) int {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(payload))
+		require.NoError(t, err)
+		if secret != "" {
+			req.Header.Set(SecretTokenHeader, secret)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("wrong secret", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, post("wrong", body))
+		assert.Equal(t, http.StatusUnauthorized, post("", body))
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post("s3cret", []byte("{")))
+	})
+
+	t.Run("valid update", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, post("s3cret", body))
+
+		select {
+		case update := <-receiver.Updates():
+			assert.Equal(t, 42, update.UpdateID)
+			require.NotNil(t, update.CallbackQuery)
+			assert.Equal(t, "box_solutions", update.CallbackQuery.Data)
+		case <-time.After(time.Second):
+			t.Fatal("update was not delivered")
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		resp, err := http.Get(srv.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,6 +16,11 @@ type Config struct {
 	Telegram  TelegramConfig  `yaml:"telegram"`
 	Database  DatabaseConfig  `yaml:"database"`
 	Logger    LoggerConfig    `yaml:"logger"`
+	Bot       BotConfig       `yaml:"bot"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Tracker   TrackerConfig   `yaml:"tracker"`
+	Reminders RemindersConfig `yaml:"reminders"`
+	Calendar  CalendarConfig  `yaml:"calendar"`
 }
 
 type ServerConfig struct {
@@ -21,8 +29,26 @@ type ServerConfig struct {
 	PrometheusPort int    `yaml:"prometheus_port"`
 }
 
+const (
+	TelegramModePolling = "polling"
+	TelegramModeWebhook = "webhook"
+
+	StateStorePostgres = "postgres"
+	StateStoreMemory   = "memory"
+)
+
 type TelegramConfig struct {
-	BotToken string `yaml:"bot_token"`
+	BotToken    string        `yaml:"bot_token"`
+	APIEndpoint string        `yaml:"api_endpoint"` // пусто — api.telegram.org
+	Mode        string        `yaml:"mode"`         // polling/webhook
+	Webhook     WebhookConfig `yaml:"webhook"`
+}
+
+type WebhookConfig struct {
+	URL    string `yaml:"url"`    // публичный адрес, который регистрируется в Telegram
+	Path   string `yaml:"path"`   // путь обработчика на HTTP-сервере
+	Secret string `yaml:"secret"` // secret_token, проверяется в заголовке каждого запроса
+	Port   int    `yaml:"port"`   // 0 — монтировать на сервер метрик
 }
 
 type DatabaseConfig struct {
@@ -33,6 +59,46 @@ type LoggerConfig struct {
 	Level string `yaml:"level"`
 }
 
+type BotConfig struct {
+	UpdateTimeout time.Duration `yaml:"update_timeout"`  // дедлайн обработки одного апдейта
+	UserRateLimit float64       `yaml:"user_rate_limit"` // апдейтов в секунду от одного пользователя
+	UserRateBurst int           `yaml:"user_rate_burst"`
+	Workers       int           `yaml:"workers"`     // воркеры, параллельно обрабатывающие апдейты
+	QueueSize     int           `yaml:"queue_size"`  // максимум апдейтов в очереди на обработку
+	StateStore    string        `yaml:"state_store"` // postgres/memory — где хранить состояние диалогов
+	// StateTTL — сколько диалог живёт без действий пользователя, StateSweepInterval —
+	// как часто удалять брошенные диалоги.
+	StateTTL           time.Duration `yaml:"state_ttl"`
+	StateSweepInterval time.Duration `yaml:"state_sweep_interval"`
+	MediaDir           string        `yaml:"media_dir"` // каталог с фотографиями услуг, относительные пути считаются от него
+}
+
+type AdminConfig struct {
+	ChatID int64 `yaml:"chat_id"` // чат, куда приходят новые бронирования на подтверждение
+}
+
+// TrackerConfig — интеграция с Яндекс Трекером. Пустой token отключает создание тикетов.
+type TrackerConfig struct {
+	BaseURL      string        `yaml:"base_url"` // пусто — api.tracker.yandex.net
+	Token        string        `yaml:"token"`    // OAuth-токен
+	OrgID        string        `yaml:"org_id"`
+	Queue        string        `yaml:"queue"` // очередь, в которой создаются тикеты
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	MaxAttempts  int           `yaml:"max_attempts"` // после стольких неудач запись помечается failed
+}
+
+type RemindersConfig struct {
+	Offsets  []time.Duration `yaml:"offsets"`  // за сколько до посещения напоминать
+	Timezone string          `yaml:"timezone"` // часовой пояс дат и времени бронирований
+}
+
+// CalendarConfig — календарь бронирований по ссылке. Пустой secret отключает его.
+type CalendarConfig struct {
+	PublicURL string `yaml:"public_url"` // внешний адрес HTTP-сервера, из него строятся ссылки
+	Secret    string `yaml:"secret"`     // ключ подписи ссылок
+}
+
 func LoadConfig() (*Config, error) {
 	cfg := &Config{}
 
@@ -53,6 +119,32 @@ func LoadConfig() (*Config, error) {
 		cfg.Telegram.BotToken = v
 	}
 
+	if v := os.Getenv("TELEGRAM_API_ENDPOINT"); v != "" {
+		cfg.Telegram.APIEndpoint = v
+	}
+
+	if v := os.Getenv("TELEGRAM_MODE"); v != "" {
+		cfg.Telegram.Mode = v
+	}
+
+	if v := os.Getenv("WEBHOOK_URL"); v != "" {
+		cfg.Telegram.Webhook.URL = v
+	}
+
+	if v := os.Getenv("WEBHOOK_PATH"); v != "" {
+		cfg.Telegram.Webhook.Path = v
+	}
+
+	if v := os.Getenv("WEBHOOK_SECRET"); v != "" {
+		cfg.Telegram.Webhook.Secret = v
+	}
+
+	if v := os.Getenv("WEBHOOK_PORT"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			cfg.Telegram.Webhook.Port = i
+		}
+	}
+
 	if v := os.Getenv("POSTGRES_URL"); v != "" {
 		cfg.Database.PostgresURL = v
 	}
@@ -77,6 +169,160 @@ func LoadConfig() (*Config, error) {
 		cfg.Logger.Level = v
 	}
 
+	if v := os.Getenv("BOT_UPDATE_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Bot.UpdateTimeout = d
+		}
+	}
+
+	if v := os.Getenv("BOT_USER_RATE_LIMIT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Bot.UserRateLimit = f
+		}
+	}
+
+	if v := os.Getenv("BOT_USER_RATE_BURST"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			cfg.Bot.UserRateBurst = i
+		}
+	}
+
+	if v := os.Getenv("BOT_WORKERS"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			cfg.Bot.Workers = i
+		}
+	}
+
+	if v := os.Getenv("BOT_QUEUE_SIZE"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			cfg.Bot.QueueSize = i
+		}
+	}
+
+	if v := os.Getenv("BOT_STATE_STORE"); v != "" {
+		cfg.Bot.StateStore = v
+	}
+
+	if v := os.Getenv("BOT_STATE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Bot.StateTTL = d
+		}
+	}
+
+	if v := os.Getenv("BOT_STATE_SWEEP_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Bot.StateSweepInterval = d
+		}
+	}
+
+	if v := os.Getenv("BOT_MEDIA_DIR"); v != "" {
+		cfg.Bot.MediaDir = v
+	}
+
+	if v := os.Getenv("ADMIN_CHAT_ID"); v != "" {
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.Admin.ChatID = i
+		}
+	}
+
+	if v := os.Getenv("TRACKER_BASE_URL"); v != "" {
+		cfg.Tracker.BaseURL = v
+	}
+
+	if v := os.Getenv("TRACKER_TOKEN"); v != "" {
+		cfg.Tracker.Token = v
+	}
+
+	if v := os.Getenv("TRACKER_ORG_ID"); v != "" {
+		cfg.Tracker.OrgID = v
+	}
+
+	if v := os.Getenv("TRACKER_QUEUE"); v != "" {
+		cfg.Tracker.Queue = v
+	}
+
+	if v := os.Getenv("TRACKER_POLL_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Tracker.PollInterval = d
+		}
+	}
+
+	if v := os.Getenv("REMINDER_OFFSETS"); v != "" {
+		var offsets []time.Duration
+		for _, part := range strings.Split(v, ",") {
+			if d, err := time.ParseDuration(strings.TrimSpace(part)); err == nil && d > 0 {
+				offsets = append(offsets, d)
+			}
+		}
+		cfg.Reminders.Offsets = offsets
+	}
+
+	if v := os.Getenv("TIMEZONE"); v != "" {
+		cfg.Reminders.Timezone = v
+	}
+
+	if v := os.Getenv("CALENDAR_PUBLIC_URL"); v != "" {
+		cfg.Calendar.PublicURL = v
+	}
+
+	if v := os.Getenv("CALENDAR_SECRET"); v != "" {
+		cfg.Calendar.Secret = v
+	}
+
+	// defaults
+
+	if cfg.Telegram.Mode == "" {
+		cfg.Telegram.Mode = TelegramModePolling
+	}
+	if cfg.Telegram.Webhook.Path == "" {
+		cfg.Telegram.Webhook.Path = "/telegram/webhook"
+	}
+
+	if cfg.Bot.UpdateTimeout <= 0 {
+		cfg.Bot.UpdateTimeout = 15 * time.Second
+	}
+	if cfg.Bot.UserRateLimit <= 0 {
+		cfg.Bot.UserRateLimit = 2
+	}
+	if cfg.Bot.UserRateBurst <= 0 {
+		cfg.Bot.UserRateBurst = 5
+	}
+	if cfg.Bot.Workers <= 0 {
+		cfg.Bot.Workers = 8
+	}
+	if cfg.Bot.QueueSize <= 0 {
+		cfg.Bot.QueueSize = 256
+	}
+	if cfg.Bot.StateStore == "" {
+		cfg.Bot.StateStore = StateStorePostgres
+	}
+	if cfg.Bot.StateTTL <= 0 {
+		cfg.Bot.StateTTL = 2 * time.Hour
+	}
+	if cfg.Bot.StateSweepInterval <= 0 {
+		cfg.Bot.StateSweepInterval = 10 * time.Minute
+	}
+	if cfg.Bot.MediaDir == "" {
+		cfg.Bot.MediaDir = "media"
+	}
+
+	if cfg.Tracker.PollInterval <= 0 {
+		cfg.Tracker.PollInterval = 10 * time.Second
+	}
+	if cfg.Tracker.BatchSize <= 0 {
+		cfg.Tracker.BatchSize = 10
+	}
+	if cfg.Tracker.MaxAttempts <= 0 {
+		cfg.Tracker.MaxAttempts = 10
+	}
+
+	if cfg.Reminders.Offsets == nil {
+		cfg.Reminders.Offsets = []time.Duration{24 * time.Hour, 2 * time.Hour}
+	}
+	if cfg.Reminders.Timezone == "" {
+		cfg.Reminders.Timezone = "Europe/Moscow"
+	}
+
 	// validation
 
 	if cfg.Telegram.BotToken == "" {
@@ -85,6 +331,30 @@ func LoadConfig() (*Config, error) {
 	if cfg.Database.PostgresURL == "" {
 		return nil, errors.New("postgres url is required")
 	}
+	if cfg.Bot.StateStore != StateStorePostgres && cfg.Bot.StateStore != StateStoreMemory {
+		return nil, errors.New("bot state store must be postgres or memory")
+	}
+	if cfg.Tracker.Token != "" && cfg.Tracker.Queue == "" {
+		return nil, errors.New("tracker queue is required when tracker token is set")
+	}
+	if _, err := time.LoadLocation(cfg.Reminders.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid reminders timezone: %w", err)
+	}
+	if cfg.Calendar.Secret != "" && cfg.Calendar.PublicURL == "" {
+		return nil, errors.New("calendar public url is required when calendar secret is set")
+	}
+	switch cfg.Telegram.Mode {
+	case TelegramModePolling:
+	case TelegramModeWebhook:
+		if cfg.Telegram.Webhook.URL == "" {
+			return nil, errors.New("webhook url is required in webhook mode")
+		}
+		if cfg.Telegram.Webhook.Secret == "" {
+			return nil, errors.New("webhook secret is required in webhook mode")
+		}
+	default:
+		return nil, errors.New("telegram mode must be polling or webhook")
+	}
 
 	return cfg, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Действия администратора в admin_audit_log.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionHide   = "hide"
+	AuditActionShow   = "show"
+	AuditActionMove   = "move"
+	AuditActionDelete = "delete"
+)
+
+const auditEntityService = "service"
+
+const insertAuditLogQuery = `
+INSERT INTO admin_audit_log (admin_telegram_id, action, entity_type, entity_id, before_data, after_data)
+VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb)
+`
+
+// writeAudit записывает изменение в admin_audit_log в той же транзакции, что и само изменение.
+// before и after сохраняются как JSON; nil записывается как NULL.
+func writeAudit(ctx context.Context, tx *sqlx.Tx, logger *zap.Logger, adminID int64, action, entity string, entityID int64, before, after any) error {
+	beforeJS, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJS, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = tx.ExecContext(ctx, insertAuditLogQuery, adminID, action, entity, entityID, beforeJS, afterJS)
+	observeQuery(logger, "create", start, err)
+	if err != nil {
+		logger.Error("write_audit_log_failed", zap.Error(err),
+			zap.String("action", action), zap.String("entity_type", entity), zap.Int64("entity_id", entityID))
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return nil
+}
+
+func auditJSON(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal audit data: %w", err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,1031 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/schedule"
+)
+
+// bookingHorizonDays — на сколько дней вперёд предлагаются даты для бронирования.
+const bookingHorizonDays = 14
+
+const (
+	// ensureSlotQuery создаёт слот при первом бронировании, вместимость берётся из настроек услуги.
+	ensureSlotQuery = `
+INSERT INTO service_slots (service_id, slot_date, slot_time, capacity)
+SELECT id, $2, $3, CASE WHEN $3::time IS NULL THEN daily_capacity ELSE slot_capacity END
+FROM services WHERE id = $1
+ON CONFLICT ON CONSTRAINT service_slots_service_date_time_key DO NOTHING
+`
+	// reserveSlotQuery занимает в слоте $4 мест — по одному на гостя. Условие
+	// booked + $4 <= capacity проверяется под блокировкой строки, поэтому два
+	// параллельных бронирования не превысят вместимость.
+	reserveSlotQuery = `
+UPDATE service_slots
+SET booked = booked + $4,
+	updated_at = CURRENT_TIMESTAMP
+WHERE service_id = $1
+	AND slot_date = $2
+	AND slot_time IS NOT DISTINCT FROM $3
+	AND booked + $4 <= capacity
+RETURNING id
+`
+	insertBookingQuery = `
+INSERT INTO bookings (user_id, service_id, slot_id, booking_date, booking_time, guest_name, guest_organization, guest_position, visit_type, guest_count)
+SELECT id, $2, $3, $4, $5, $6, $7, $8, $9, $10 FROM users WHERE telegram_id = $1
+RETURNING id
+`
+	// insertBookingGuestsQuery сохраняет гостей одним запросом, порядок задаёт sort_order.
+	insertBookingGuestsQuery = `
+INSERT INTO booking_guests (booking_id, sort_order, name, organization, position)
+SELECT $1, g.ord, g.name, NULLIF(g.organization, ''), NULLIF(g.position, '')
+FROM unnest($2::text[], $3::text[], $4::text[]) WITH ORDINALITY AS g(name, organization, position, ord)
+`
+	getMaxGuestsQuery = `SELECT max_guests FROM services WHERE id = $1`
+
+	getServiceScheduleQuery = `
+SELECT weekly_hours, daily_capacity, slot_minutes, slot_capacity, COALESCE(timezone, '') AS timezone
+FROM services
+WHERE id = $1
+`
+
+	// fullDatesQuery возвращает дни, в которые слот на весь день уже заполнен.
+	fullDatesQuery = `
+SELECT slot_date
+FROM service_slots
+WHERE service_id = $1
+	AND slot_time IS NULL
+	AND slot_date BETWEEN $2 AND $3
+	AND booked >= capacity
+`
+	// fullTimedSlotsQuery возвращает число заполненных почасовых слотов по дням.
+	fullTimedSlotsQuery = `
+SELECT slot_date, COUNT(*) AS full_slots
+FROM service_slots
+WHERE service_id = $1
+	AND slot_time IS NOT NULL
+	AND slot_date BETWEEN $2 AND $3
+	AND booked >= capacity
+GROUP BY slot_date
+`
+	insertStatusHistoryQuery = `
+INSERT INTO booking_status_history (booking_id, old_status, new_status, changed_by)
+VALUES ($1, $2, $3, $4)
+`
+	insertRescheduleHistoryQuery = `
+INSERT INTO booking_status_history (booking_id, old_status, new_status, changed_by,
+	old_booking_date, old_booking_time, new_booking_date, new_booking_time)
+VALUES ($1, $2, $2, $3, $4, $5, $6, $7)
+`
+
+	releaseSlotQuery = `
+UPDATE service_slots
+SET booked = GREATEST(booked - $2, 0),
+	updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+	selectBookingQuery = `
+SELECT b.id, b.user_id, u.telegram_id AS user_telegram_id, b.service_id, COALESCE(s.title, '') AS service_title,
+	s.location AS service_location, COALESCE(s.timezone, '') AS service_timezone, s.slot_minutes AS service_slot_minutes, b.slot_id,
+	b.booking_date, to_char(b.booking_time, 'HH24:MI') AS booking_time,
+	b.guest_name, b.guest_organization, b.guest_position, b.visit_type,
+	b.guest_count, bg.guest_names,
+	COALESCE(b.status, 'pending') AS status, b.tracker_ticket_id, b.created_at, b.updated_at
+FROM bookings b
+JOIN users u ON u.id = b.user_id
+LEFT JOIN services s ON s.id = b.service_id
+LEFT JOIN LATERAL (
+	SELECT string_agg(g.name, ', ' ORDER BY g.sort_order) AS guest_names
+	FROM booking_guests g
+	WHERE g.booking_id = b.id
+) bg ON TRUE
+`
+	listUserBookingsQuery = selectBookingQuery + `
+WHERE u.telegram_id = $1
+ORDER BY b.booking_date < CURRENT_DATE,
+	CASE WHEN b.booking_date >= CURRENT_DATE THEN b.booking_date END,
+	b.booking_date DESC,
+	b.booking_time,
+	b.id
+LIMIT $2 OFFSET $3
+`
+	countUserBookingsQuery = `
+SELECT COUNT(*)
+FROM bookings b
+JOIN users u ON u.id = b.user_id
+WHERE u.telegram_id = $1
+`
+	getUserBookingQuery = selectBookingQuery + `WHERE u.telegram_id = $1 AND b.id = $2`
+	getBookingQuery     = selectBookingQuery + `WHERE b.id = $1`
+	listConfirmedQuery  = selectBookingQuery + `
+WHERE u.telegram_id = $1 AND b.status = 'confirmed'
+ORDER BY b.booking_date, b.booking_time, b.id
+`
+
+	lockBookingQuery = `
+SELECT COALESCE(b.status, 'pending') AS status, b.service_id, b.slot_id, b.guest_count,
+	u.telegram_id, COALESCE(b.visit_type, '') AS visit_type,
+	to_char(b.booking_date, 'YYYY-MM-DD') AS booking_date,
+	COALESCE(to_char(b.booking_time, 'HH24:MI'), '') AS booking_time
+FROM bookings b
+JOIN users u ON u.id = b.user_id
+WHERE b.id = $1 AND ($2::bigint = 0 OR u.telegram_id = $2)
+FOR UPDATE OF b
+`
+	updateBookingStatusQuery = `
+UPDATE bookings
+SET status = $2,
+	updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+	rescheduleBookingQuery = `
+UPDATE bookings
+SET booking_date = $2,
+	booking_time = $3,
+	slot_id = $4,
+	updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+	takenSlotsQuery = `
+SELECT to_char(slot_time, 'HH24:MI')
+FROM service_slots
+WHERE service_id = $1
+	AND slot_date = $2
+	AND slot_time IS NOT NULL
+	AND booked >= capacity
+`
+)
+
+const slotTimeLayout = "15:04"
+
+var (
+	// ErrUserNotRegistered возвращается, если бронирование создаёт пользователь без записи в users.
+	ErrUserNotRegistered = errors.New("user is not registered")
+	// ErrServiceNotFound возвращается для неизвестного service_id.
+	ErrServiceNotFound = errors.New("service not found")
+	// ErrSlotTaken возвращается, если в выбранном слоте не осталось мест.
+	ErrSlotTaken = errors.New("slot is already taken")
+	// ErrSlotUnavailable возвращается, если выбранные дата или время не входят в расписание
+	// услуги или уже прошли.
+	ErrSlotUnavailable = errors.New("slot is not in the service schedule")
+	// ErrBookingNotFound возвращается, если бронирования нет или оно принадлежит другому пользователю.
+	ErrBookingNotFound = errors.New("booking not found")
+)
+
+type BookingRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewBookingRepository(db *sqlx.DB, logger *zap.Logger) *BookingRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &BookingRepository{db: db, logger: logger, now: time.Now}
+}
+
+// SaveBooking сохраняет заполненную форму и возвращает ID бронирования. state.UserID —
+// Telegram ID пользователя, он сопоставляется с users.id в том же запросе. Место в слоте
+// резервируется в той же транзакции; если мест не осталось, возвращается ErrSlotTaken,
+// если дата или время не входят в расписание услуги — ErrSlotUnavailable.
+// До резервирования проверяются правила уровня пользователя: ErrGradeNotAllowed и ErrMonthlyLimitReached.
+func (r *BookingRepository) SaveBooking(ctx context.Context, state *models.BookingState) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		r.logger.Error("context cancelled before query")
+		return 0, err
+	}
+
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctxQ, nil)
+	if err != nil {
+		r.logger.Error("begin_tx_failed", zap.Error(err))
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	date := state.SelectedDate.Format("2006-01-02")
+	// пустое время — слот на весь день
+	slotTime := nullIfEmpty(state.SelectedTime)
+
+	if err := checkGradeRules(ctxQ, tx, r.logger, state.UserID, state.ServiceID, state.VisitType, date, 0); err != nil {
+		if errors.Is(err, ErrUserNotRegistered) {
+			r.logger.Error("booking_user_not_registered", zap.Int64("telegram_id", state.UserID))
+		}
+		return 0, err
+	}
+
+	if err := r.checkSchedule(ctxQ, tx, state.ServiceID, state.SelectedDate, state.SelectedTime); err != nil {
+		if errors.Is(err, ErrSlotUnavailable) {
+			r.logger.Info("booking_slot_unavailable",
+				zap.Int64("telegram_id", state.UserID),
+				zap.Int("service_id", state.ServiceID),
+				zap.String("date", date),
+				zap.String("time", state.SelectedTime),
+			)
+		}
+		return 0, err
+	}
+
+	// контактное лицо — первый гость, остальные хранятся только в booking_guests;
+	// место в слоте занимает каждый гость
+	guests := state.AllGuests()
+	slotID, err := r.reserveSlot(ctxQ, tx, state.ServiceID, date, slotTime, len(guests))
+	if err != nil {
+		if errors.Is(err, ErrSlotTaken) {
+			r.logger.Info("booking_slot_taken",
+				zap.Int64("telegram_id", state.UserID),
+				zap.Int("service_id", state.ServiceID),
+				zap.String("date", date),
+			)
+		}
+		return 0, err
+	}
+
+	var bookingID int64
+	start := time.Now()
+	err = tx.GetContext(ctxQ, &bookingID, insertBookingQuery,
+		state.UserID,
+		state.ServiceID,
+		slotID,
+		date,
+		slotTime,
+		guests[0].Name,
+		nullIfEmpty(guests[0].Organization),
+		nullIfEmpty(guests[0].Position),
+		nullIfEmpty(state.VisitType),
+		len(guests),
+	)
+	observeQuery(r.logger, "create", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Error("booking_user_not_registered", zap.Int64("telegram_id", state.UserID))
+			return 0, ErrUserNotRegistered
+		}
+		r.logger.Error("save_booking_failed", zap.Error(err), zap.Int64("telegram_id", state.UserID))
+		return 0, fmt.Errorf("save booking: %w", err)
+	}
+
+	if err := insertBookingGuests(ctxQ, tx, r.logger, bookingID, guests); err != nil {
+		return 0, err
+	}
+
+	if err := r.recordStatus(ctxQ, tx, bookingID, "", models.BookingStatusPending, state.UserID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("commit_booking_failed", zap.Error(err))
+		return 0, fmt.Errorf("commit booking: %w", err)
+	}
+
+	r.logger.Info("booking_created",
+		zap.Int64("booking_id", bookingID),
+		zap.Int64("slot_id", slotID),
+		zap.Int64("telegram_id", state.UserID),
+		zap.Int("service_id", state.ServiceID),
+	)
+	return bookingID, nil
+}
+
+// insertBookingGuests сохраняет гостей бронирования в booking_guests.
+func insertBookingGuests(ctx context.Context, tx *sqlx.Tx, logger *zap.Logger, bookingID int64, guests []models.BookingGuest) error {
+	names := make([]string, len(guests))
+	orgs := make([]string, len(guests))
+	positions := make([]string, len(guests))
+	for i, g := range guests {
+		names[i], orgs[i], positions[i] = g.Name, g.Organization, g.Position
+	}
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, insertBookingGuestsQuery, bookingID, pq.Array(names), pq.Array(orgs), pq.Array(positions))
+	observeQuery(logger, "create", start, err)
+	if err != nil {
+		logger.Error("save_booking_guests_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return fmt.Errorf("save booking guests: %w", err)
+	}
+	return nil
+}
+
+// GetMaxGuests возвращает, сколько гостей можно указать в одном бронировании услуги.
+func (r *BookingRepository) GetMaxGuests(ctx context.Context, serviceID int) (int, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var maxGuests int
+	start := time.Now()
+	err := r.db.GetContext(ctxQ, &maxGuests, getMaxGuestsQuery, serviceID)
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrServiceNotFound
+		}
+		r.logger.Error("get_max_guests_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return 0, fmt.Errorf("get max guests: %w", err)
+	}
+	return maxGuests, nil
+}
+
+// reserveSlot создаёт слот при необходимости и занимает в нём guests мест.
+func (r *BookingRepository) reserveSlot(ctx context.Context, tx *sqlx.Tx, serviceID int, date string, slotTime sql.NullString, guests int) (int64, error) {
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, ensureSlotQuery, serviceID, date, slotTime)
+	observeQuery(r.logger, "create", start, err)
+	if err != nil {
+		r.logger.Error("ensure_slot_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return 0, fmt.Errorf("ensure slot: %w", err)
+	}
+
+	var slotID int64
+	start = time.Now()
+	err = tx.GetContext(ctx, &slotID, reserveSlotQuery, serviceID, date, slotTime, guests)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrSlotTaken
+		}
+		r.logger.Error("reserve_slot_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return 0, fmt.Errorf("reserve slot: %w", err)
+	}
+	return slotID, nil
+}
+
+// recordStatus записывает смену статуса в booking_status_history. Пустой from означает
+// создание бронирования, changedBy == 0 — изменение системой.
+func (r *BookingRepository) recordStatus(ctx context.Context, tx *sqlx.Tx, bookingID int64, from, to models.BookingStatus, changedBy int64) error {
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, insertStatusHistoryQuery,
+		bookingID,
+		nullIfEmpty(string(from)),
+		to,
+		sql.NullInt64{Int64: changedBy, Valid: changedBy != 0},
+	)
+	observeQuery(r.logger, "create", start, err)
+	if err != nil {
+		r.logger.Error("record_booking_status_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return fmt.Errorf("record booking status: %w", err)
+	}
+	return nil
+}
+
+// recordReschedule записывает перенос бронирования в booking_status_history: статус
+// не меняется, а прежние и новые дата и время сохраняются рядом с ним.
+func (r *BookingRepository) recordReschedule(ctx context.Context, tx *sqlx.Tx, bookingID int64, b *lockedBooking, day, slotTime string, changedBy int64) error {
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, insertRescheduleHistoryQuery,
+		bookingID,
+		b.Status,
+		sql.NullInt64{Int64: changedBy, Valid: changedBy != 0},
+		nullIfEmpty(b.BookingDate),
+		nullIfEmpty(b.BookingTime),
+		day,
+		nullIfEmpty(slotTime),
+	)
+	observeQuery(r.logger, "create", start, err)
+	if err != nil {
+		r.logger.Error("record_booking_reschedule_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return fmt.Errorf("record booking reschedule: %w", err)
+	}
+	return nil
+}
+
+// releaseSlot освобождает guests мест в слоте отменённого или перенесённого бронирования.
+// Если на слот есть лист ожидания, свободные места тут же предлагаются очереди: каждому,
+// чья группа в них помещается, по порядку.
+func (r *BookingRepository) releaseSlot(ctx context.Context, tx *sqlx.Tx, slotID sql.NullInt64, guests int) error {
+	if !slotID.Valid {
+		return nil
+	}
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, releaseSlotQuery, slotID.Int64, guests)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("release_slot_failed", zap.Error(err), zap.Int64("slot_id", slotID.Int64))
+		return fmt.Errorf("release slot: %w", err)
+	}
+
+	for {
+		offered, err := offerSlot(ctx, tx, r.logger, slotID.Int64)
+		if err != nil || !offered {
+			return err
+		}
+	}
+}
+
+// ListUserBookings возвращает страницу бронирований пользователя: сначала предстоящие
+// по возрастанию даты, затем прошедшие от новых к старым. total — общее число бронирований.
+func (r *BookingRepository) ListUserBookings(ctx context.Context, telegramID int64, limit, offset int) (bookings []models.Booking, total int, err error) {
+	if err := ctx.Err(); err != nil {
+		r.logger.Error("context cancelled before query")
+		return nil, 0, err
+	}
+
+	op := "read"
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err = r.db.GetContext(ctxQ, &total, countUserBookingsQuery, telegramID)
+	observeQuery(r.logger, op, start, err)
+	if err != nil {
+		r.logger.Error("count_user_bookings_failed", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return nil, 0, fmt.Errorf("count user bookings: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	start = time.Now()
+	err = r.db.SelectContext(ctxQ, &bookings, listUserBookingsQuery, telegramID, limit, offset)
+	observeQuery(r.logger, op, start, err)
+	if err != nil {
+		r.logger.Error("list_user_bookings_failed", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return nil, 0, fmt.Errorf("list user bookings: %w", err)
+	}
+	return bookings, total, nil
+}
+
+// GetUserBooking возвращает бронирование, если оно принадлежит пользователю.
+func (r *BookingRepository) GetUserBooking(ctx context.Context, telegramID, bookingID int64) (*models.Booking, error) {
+	if err := ctx.Err(); err != nil {
+		r.logger.Error("context cancelled before query")
+		return nil, err
+	}
+
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var b models.Booking
+	start := time.Now()
+	err := r.db.GetContext(ctxQ, &b, getUserBookingQuery, telegramID, bookingID)
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookingNotFound
+		}
+		r.logger.Error("get_user_booking_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return nil, fmt.Errorf("get user booking: %w", err)
+	}
+	return &b, nil
+}
+
+// GetBooking возвращает бронирование по ID без проверки владельца (для администраторов).
+func (r *BookingRepository) GetBooking(ctx context.Context, bookingID int64) (*models.Booking, error) {
+	if err := ctx.Err(); err != nil {
+		r.logger.Error("context cancelled before query")
+		return nil, err
+	}
+
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var b models.Booking
+	start := time.Now()
+	err := r.db.GetContext(ctxQ, &b, getBookingQuery, bookingID)
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookingNotFound
+		}
+		r.logger.Error("get_booking_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return nil, fmt.Errorf("get booking: %w", err)
+	}
+	return &b, nil
+}
+
+// ListConfirmedBookings возвращает все подтверждённые бронирования пользователя по дате посещения.
+func (r *BookingRepository) ListConfirmedBookings(ctx context.Context, telegramID int64) ([]models.Booking, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var bookings []models.Booking
+	start := time.Now()
+	err := r.db.SelectContext(ctxQ, &bookings, listConfirmedQuery, telegramID)
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		r.logger.Error("list_confirmed_bookings_failed", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return nil, fmt.Errorf("list confirmed bookings: %w", err)
+	}
+	return bookings, nil
+}
+
+// lockedBooking — поля бронирования, заблокированного для изменения.
+type lockedBooking struct {
+	Status    models.BookingStatus `db:"status"`
+	ServiceID int                  `db:"service_id"`
+	SlotID    sql.NullInt64        `db:"slot_id"`
+	// GuestCount — сколько мест бронирование занимает в слоте.
+	GuestCount int `db:"guest_count"`
+	// TelegramID и VisitType нужны для проверки правил уровня при переносе.
+	TelegramID int64  `db:"telegram_id"`
+	VisitType  string `db:"visit_type"`
+	// BookingDate и BookingTime — прежние дата и время для истории переноса.
+	BookingDate string `db:"booking_date"`
+	BookingTime string `db:"booking_time"`
+}
+
+// lockBooking блокирует строку бронирования до конца транзакции. Если telegramID != 0,
+// бронирование должно принадлежать этому пользователю.
+func (r *BookingRepository) lockBooking(ctx context.Context, tx *sqlx.Tx, bookingID, telegramID int64) (*lockedBooking, error) {
+	var b lockedBooking
+	start := time.Now()
+	err := tx.GetContext(ctx, &b, lockBookingQuery, bookingID, telegramID)
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookingNotFound
+		}
+		r.logger.Error("lock_booking_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return nil, fmt.Errorf("lock booking: %w", err)
+	}
+	return &b, nil
+}
+
+// UpdateBookingStatus переводит бронирование в статус next с проверкой допустимости
+// перехода и записывает изменение в историю от имени changedBy (Telegram ID).
+// При отмене место в слоте освобождается.
+func (r *BookingRepository) UpdateBookingStatus(ctx context.Context, bookingID int64, next models.BookingStatus, changedBy int64) error {
+	return r.updateStatus(ctx, bookingID, 0, next, changedBy)
+}
+
+// CancelBooking отменяет бронирование пользователя.
+func (r *BookingRepository) CancelBooking(ctx context.Context, telegramID, bookingID int64) error {
+	return r.updateStatus(ctx, bookingID, telegramID, models.BookingStatusCancelled, telegramID)
+}
+
+func (r *BookingRepository) updateStatus(ctx context.Context, bookingID, ownerID int64, next models.BookingStatus, changedBy int64) error {
+	if err := ctx.Err(); err != nil {
+		r.logger.Error("context cancelled before query")
+		return err
+	}
+
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctxQ, nil)
+	if err != nil {
+		r.logger.Error("begin_tx_failed", zap.Error(err))
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	b, err := r.lockBooking(ctxQ, tx, bookingID, ownerID)
+	if err != nil {
+		return err
+	}
+	if err := b.Status.ValidateTransition(next); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = tx.ExecContext(ctxQ, updateBookingStatusQuery, bookingID, next)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("update_booking_status_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return fmt.Errorf("update booking status: %w", err)
+	}
+
+	if err := r.recordStatus(ctxQ, tx, bookingID, b.Status, next, changedBy); err != nil {
+		return err
+	}
+
+	switch next {
+	case models.BookingStatusCancelled:
+		if err := r.releaseSlot(ctxQ, tx, b.SlotID, b.GuestCount); err != nil {
+			return err
+		}
+	case models.BookingStatusConfirmed:
+		// тикет в Трекере создаётся асинхронно из tracker_outbox
+		start = time.Now()
+		_, err = tx.ExecContext(ctxQ, enqueueTrackerTicketQuery, bookingID)
+		observeQuery(r.logger, "create", start, err)
+		if err != nil {
+			r.logger.Error("enqueue_tracker_ticket_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+			return fmt.Errorf("enqueue tracker ticket: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("commit_booking_status_failed", zap.Error(err))
+		return fmt.Errorf("commit booking status: %w", err)
+	}
+
+	r.logger.Info("booking_status_changed",
+		zap.Int64("booking_id", bookingID),
+		zap.String("from", string(b.Status)),
+		zap.String("to", string(next)),
+		zap.Int64("changed_by", changedBy),
+	)
+	return nil
+}
+
+// RescheduleBooking переносит бронирование пользователя на другую дату и время:
+// место в старом слоте освобождается, в новом — резервируется в той же транзакции.
+// Если новые дата или время не входят в расписание услуги, возвращается ErrSlotUnavailable;
+// правила уровня проверяются как в SaveBooking.
+func (r *BookingRepository) RescheduleBooking(ctx context.Context, telegramID, bookingID int64, date time.Time, slotTime string) error {
+	if err := ctx.Err(); err != nil {
+		r.logger.Error("context cancelled before query")
+		return err
+	}
+
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctxQ, nil)
+	if err != nil {
+		r.logger.Error("begin_tx_failed", zap.Error(err))
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	b, err := r.lockBooking(ctxQ, tx, bookingID, telegramID)
+	if err != nil {
+		return err
+	}
+	if b.Status == models.BookingStatusCancelled {
+		return fmt.Errorf("%w: cancelled booking can not be rescheduled", models.ErrInvalidStatusTransition)
+	}
+
+	day := date.Format("2006-01-02")
+	// на новую дату действуют те же правила уровня и месячный лимит, что и при бронировании;
+	// само переносимое бронирование в лимите не считается
+	if err := checkGradeRules(ctxQ, tx, r.logger, b.TelegramID, b.ServiceID, b.VisitType, day, bookingID); err != nil {
+		return err
+	}
+	if err := r.checkSchedule(ctxQ, tx, b.ServiceID, date, slotTime); err != nil {
+		return err
+	}
+
+	if err := r.releaseSlot(ctxQ, tx, b.SlotID, b.GuestCount); err != nil {
+		return err
+	}
+
+	slotID, err := r.reserveSlot(ctxQ, tx, b.ServiceID, day, nullIfEmpty(slotTime), b.GuestCount)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = tx.ExecContext(ctxQ, rescheduleBookingQuery, bookingID, day, nullIfEmpty(slotTime), slotID)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("reschedule_booking_failed", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return fmt.Errorf("reschedule booking: %w", err)
+	}
+
+	if err := r.recordReschedule(ctxQ, tx, bookingID, b, day, slotTime, telegramID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("commit_reschedule_failed", zap.Error(err))
+		return fmt.Errorf("commit reschedule: %w", err)
+	}
+
+	r.logger.Info("booking_rescheduled",
+		zap.Int64("booking_id", bookingID),
+		zap.String("date", day),
+		zap.String("time", slotTime),
+	)
+	return nil
+}
+
+// serviceSchedule — расписание и вместимость услуги из таблицы services.
+type serviceSchedule struct {
+	Weekly        schedule.Weekly `db:"weekly_hours"`
+	DailyCapacity int             `db:"daily_capacity"`
+	SlotMinutes   sql.NullInt64   `db:"slot_minutes"`
+	SlotCapacity  int             `db:"slot_capacity"`
+	Timezone      string          `db:"timezone"`
+
+	// Hours — недельное расписание с исключениями и праздниками за запрошенный период.
+	Hours schedule.Schedule `db:"-"`
+}
+
+// timed сообщает, бронируется ли услуга по времени, а не на весь день.
+func (s serviceSchedule) timed() bool {
+	return s.SlotMinutes.Valid && s.SlotMinutes.Int64 > 0
+}
+
+// slotTimes возвращает начала слотов (15:04) на дату по часам работы с шагом slot_minutes.
+func (s serviceSchedule) slotTimes(date time.Time) []string {
+	if !s.timed() {
+		return nil
+	}
+
+	var slots []string
+	for _, c := range s.Hours.Day(date).SlotStarts(time.Duration(s.SlotMinutes.Int64) * time.Minute) {
+		slots = append(slots, c.String())
+	}
+	return slots
+}
+
+// bookable сообщает, можно ли в эту дату забронировать услугу при свободных местах:
+// услуга работает, а у почасовой услуги в часы работы помещается хотя бы один слот.
+func (s serviceSchedule) bookable(date time.Time) bool {
+	if s.timed() {
+		return len(s.slotTimes(date)) > 0
+	}
+	return s.Hours.Day(date).Open()
+}
+
+// getSchedule загружает расписание услуги с исключениями и праздниками за период [from, to].
+// q — r.db или транзакция, в которой проверяется бронирование.
+func (r *BookingRepository) getSchedule(ctx context.Context, q sqlx.QueryerContext, serviceID int, from, to time.Time) (*serviceSchedule, error) {
+	var sched serviceSchedule
+
+	start := time.Now()
+	err := sqlx.GetContext(ctx, q, &sched, getServiceScheduleQuery, serviceID)
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrServiceNotFound
+		}
+		r.logger.Error("get_service_schedule_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return nil, fmt.Errorf("get service schedule: %w", err)
+	}
+
+	sched.Hours = schedule.Schedule{Weekly: sched.Weekly, Location: serviceLocation(r.logger, sched.Timezone)}
+	if err := loadScheduleDays(ctx, q, r.logger, &sched.Hours, serviceID, from, to); err != nil {
+		return nil, err
+	}
+	return &sched, nil
+}
+
+// checkSchedule проверяет, что бронирование на дату и время slotTime (пустое — на весь день)
+// укладывается в расписание услуги и ещё не прошло. Кнопки со старыми датами и временем
+// остаются в чате, поэтому выбор пользователя нельзя принимать на веру.
+func (r *BookingRepository) checkSchedule(ctx context.Context, q sqlx.QueryerContext, serviceID int, date time.Time, slotTime string) error {
+	day := truncateToDate(date)
+	sched, err := r.getSchedule(ctx, q, serviceID, day, day)
+	if err != nil {
+		return err
+	}
+	if day.Before(r.today(sched)) {
+		return ErrSlotUnavailable
+	}
+
+	if !sched.timed() {
+		if slotTime != "" || !sched.bookable(day) {
+			return ErrSlotUnavailable
+		}
+		return nil
+	}
+	if !slices.Contains(r.upcomingSlots(sched, day), slotTime) {
+		return ErrSlotUnavailable
+	}
+	return nil
+}
+
+// GetAvailableDates возвращает дни на ближайшие bookingHorizonDays дней, в которые услуга
+// работает по расписанию (с учётом исключений и праздников) и остались свободные места.
+// Для почасовых услуг день доступен, пока свободен хотя бы один слот. Даты возвращаются
+// в UTC без времени.
+func (r *BookingRepository) GetAvailableDates(ctx context.Context, serviceID int) ([]time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		r.logger.Error("context cancelled before query")
+		return nil, err
+	}
+
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	sched, from, err := r.horizonSchedule(ctxQ, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	full, err := r.fullDates(ctxQ, serviceID, sched, from)
+	if err != nil || full == nil {
+		return nil, err
+	}
+
+	return availableDates(from, bookingHorizonDays, sched.bookable, full), nil
+}
+
+// GetFullDates возвращает рабочие дни услуги на ближайшие bookingHorizonDays дней,
+// в которые свободных мест не осталось. На эти даты можно встать в лист ожидания.
+func (r *BookingRepository) GetFullDates(ctx context.Context, serviceID int) ([]time.Time, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	sched, from, err := r.horizonSchedule(ctxQ, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	full, err := r.fullDates(ctxQ, serviceID, sched, from)
+	if err != nil || len(full) == 0 {
+		return nil, err
+	}
+
+	var dates []time.Time
+	for _, d := range availableDates(from, bookingHorizonDays, sched.bookable, nil) {
+		if full[d] {
+			dates = append(dates, d)
+		}
+	}
+	return dates, nil
+}
+
+// fullDates возвращает заполненные дни начиная с from. nil означает, что у услуги
+// нет мест в принципе и бронировать её нельзя.
+func (r *BookingRepository) fullDates(ctx context.Context, serviceID int, sched *serviceSchedule, from time.Time) (map[time.Time]bool, error) {
+	to := from.AddDate(0, 0, bookingHorizonDays-1)
+
+	if !sched.timed() {
+		if sched.DailyCapacity <= 0 {
+			return nil, nil
+		}
+
+		var full []time.Time
+		start := time.Now()
+		err := r.db.SelectContext(ctx, &full, fullDatesQuery,
+			serviceID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+		observeQuery(r.logger, "read", start, err)
+		if err != nil {
+			r.logger.Error("get_full_dates_failed", zap.Error(err), zap.Int("service_id", serviceID))
+			return nil, fmt.Errorf("get full dates: %w", err)
+		}
+
+		closed := make(map[time.Time]bool, len(full))
+		for _, d := range full {
+			closed[truncateToDate(d)] = true
+		}
+		return closed, nil
+	}
+
+	if sched.SlotCapacity <= 0 {
+		return nil, nil
+	}
+
+	var rows []struct {
+		Date      time.Time `db:"slot_date"`
+		FullSlots int       `db:"full_slots"`
+	}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &rows, fullTimedSlotsQuery,
+		serviceID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		r.logger.Error("get_full_dates_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return nil, fmt.Errorf("get full dates: %w", err)
+	}
+
+	// число слотов зависит от часов работы в конкретный день
+	closed := make(map[time.Time]bool, len(rows))
+	for _, row := range rows {
+		d := truncateToDate(row.Date)
+		if slots := len(sched.slotTimes(d)); slots > 0 && row.FullSlots >= slots {
+			closed[d] = true
+		}
+	}
+	return closed, nil
+}
+
+// GetAvailableSlots возвращает свободные слоты (15:04) услуги на указанную дату.
+// timed == false означает, что услуга бронируется на весь день и выбор времени не нужен.
+// Для сегодняшней даты уже начавшиеся слоты не возвращаются.
+func (r *BookingRepository) GetAvailableSlots(ctx context.Context, serviceID int, date time.Time) (slots []string, timed bool, err error) {
+	if err := ctx.Err(); err != nil {
+		r.logger.Error("context cancelled before query")
+		return nil, false, err
+	}
+
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	sched, err := r.getSchedule(ctxQ, r.db, serviceID, date, date)
+	if err != nil {
+		return nil, false, err
+	}
+	if !sched.timed() {
+		return nil, false, nil
+	}
+
+	busy, err := r.takenSlots(ctxQ, serviceID, date)
+	if err != nil {
+		return nil, true, err
+	}
+
+	for _, slot := range r.upcomingSlots(sched, date) {
+		if !busy[slot] {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, true, nil
+}
+
+// GetTakenSlots возвращает ещё не начавшиеся слоты (15:04) на дату, в которых не осталось мест.
+func (r *BookingRepository) GetTakenSlots(ctx context.Context, serviceID int, date time.Time) ([]string, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	sched, err := r.getSchedule(ctxQ, r.db, serviceID, date, date)
+	if err != nil || !sched.timed() {
+		return nil, err
+	}
+
+	busy, err := r.takenSlots(ctxQ, serviceID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var slots []string
+	for _, slot := range r.upcomingSlots(sched, date) {
+		if busy[slot] {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
+func (r *BookingRepository) takenSlots(ctx context.Context, serviceID int, date time.Time) (map[string]bool, error) {
+	var taken []string
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &taken, takenSlotsQuery, serviceID, date.Format("2006-01-02"))
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		r.logger.Error("get_taken_slots_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return nil, fmt.Errorf("get taken slots: %w", err)
+	}
+
+	busy := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		busy[t] = true
+	}
+	return busy, nil
+}
+
+// upcomingSlots возвращает слоты расписания на дату; для сегодняшней даты — только не начавшиеся.
+// «Сегодня» и текущее время берутся в часовом поясе услуги: слоты заданы в её местном времени.
+func (r *BookingRepository) upcomingSlots(sched *serviceSchedule, date time.Time) []string {
+	now := r.now().In(sched.Hours.Location)
+	today := truncateToDate(now).Equal(truncateToDate(date))
+
+	var slots []string
+	for _, slot := range sched.slotTimes(date) {
+		if today && slot <= now.Format(slotTimeLayout) {
+			continue
+		}
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+// horizonSchedule загружает расписание услуги на bookingHorizonDays дней начиная с сегодняшней
+// даты в её часовом поясе и возвращает его вместе с этой датой.
+func (r *BookingRepository) horizonSchedule(ctx context.Context, serviceID int) (*serviceSchedule, time.Time, error) {
+	// часовой пояс известен только после загрузки услуги, поэтому исключения берём с запасом
+	// в день: местная дата отличается от даты в UTC не больше чем на сутки
+	utc := truncateToDate(r.now())
+	sched, err := r.getSchedule(ctx, r.db, serviceID, utc.AddDate(0, 0, -1), utc.AddDate(0, 0, bookingHorizonDays))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return sched, r.today(sched), nil
+}
+
+// today возвращает сегодняшнюю дату в часовом поясе услуги (в UTC без времени, как все даты слотов).
+func (r *BookingRepository) today(sched *serviceSchedule) time.Time {
+	return truncateToDate(r.now().In(sched.Hours.Location))
+}
+
+// availableDates перебирает days дней начиная с from и оставляет дни, в которые
+// услугу можно забронировать и которые не попали в full.
+func availableDates(from time.Time, days int, bookable func(time.Time) bool, full map[time.Time]bool) []time.Time {
+	var dates []time.Time
+	for i := 0; i < days; i++ {
+		d := from.AddDate(0, 0, i)
+		if !bookable(d) || full[d] {
+			continue
+		}
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+func truncateToDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,1057 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+func newBookingRepo(t *testing.T, now time.Time) (*BookingRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+
+	repo := NewBookingRepository(sqlx.NewDb(db, "postgres"), zap.NewNop())
+	repo.now = func() time.Time { return now }
+
+	return repo, mock, func() { _ = db.Close() }
+}
+
+var (
+	scheduleColumns    = []string{"weekly_hours", "daily_capacity", "slot_minutes", "slot_capacity", "timezone"}
+	scheduleDayColumns = []string{"day", "open_time", "close_time", "note", "holiday"}
+)
+
+// weeklyHours возвращает weekly_hours с одинаковыми часами в дни ISO days.
+func weeklyHours(open, closing string, days ...int) []byte {
+	parts := make([]string, len(days))
+	for i, d := range days {
+		parts[i] = fmt.Sprintf(`"%d":[{"open":%q,"close":%q}]`, d, open, closing)
+	}
+	return []byte("{" + strings.Join(parts, ",") + "}")
+}
+
+// expectSchedule ожидает загрузку расписания услуги. days — исключения и праздники
+// за период, nil — их нет.
+func expectSchedule(mock sqlmock.Sqlmock, serviceID int, weekly []byte, dailyCapacity int, slotMinutes any, days *sqlmock.Rows) {
+	mock.ExpectQuery(`SELECT weekly_hours, daily_capacity`).
+		WithArgs(serviceID).
+		WillReturnRows(sqlmock.NewRows(scheduleColumns).AddRow(weekly, dailyCapacity, slotMinutes, 1, "Europe/Moscow"))
+	if days == nil {
+		days = sqlmock.NewRows(scheduleDayColumns)
+	}
+	mock.ExpectQuery(`FROM service_schedule_exceptions`).
+		WithArgs(serviceID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(days)
+}
+
+var gradeRuleColumns = []string{"grade", "visit_type", "can_book", "monthly_limit"}
+
+// expectNoGradeRule ожидает проверку правил уровня, для которой правил нет.
+func expectNoGradeRule(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`FROM users u`).
+		WillReturnRows(sqlmock.NewRows(gradeRuleColumns).AddRow(2, nil, nil, nil))
+}
+
+func expectGuests(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`INSERT INTO booking_guests`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// lockedBookingColumns — столбцы lockBookingQuery, нужные для отмены и переноса.
+var lockedBookingColumns = []string{"status", "service_id", "slot_id", "guest_count"}
+
+// offerColumns — столбцы, которые возвращает offerSlotQuery.
+var offerColumns = []string{"id", "offer_expires_at", "guests"}
+
+// bookingNow — «текущее» время тестов бронирования: вторник перед датами бронирований.
+var bookingNow = time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+// expectOpenDay ожидает проверку бронирования по расписанию услуги, работающей ежедневно
+// с 10:00 до 20:00. slotMinutes == nil — услуга на весь день.
+func expectOpenDay(mock sqlmock.Sqlmock, serviceID int, slotMinutes any) {
+	expectSchedule(mock, serviceID, weeklyHours("10:00", "20:00", 1, 2, 3, 4, 5, 6, 7), 10, slotMinutes, nil)
+}
+
+// expectReserve ожидает резервирование guests мест в слоте на весь день.
+func expectReserve(mock sqlmock.Sqlmock, serviceID int, date string, guests int, slotID int64) {
+	mock.ExpectExec(`INSERT INTO service_slots`).
+		WithArgs(serviceID, date, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE service_slots`).
+		WithArgs(serviceID, date, nil, guests).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(slotID))
+}
+
+func TestSaveBooking_OK(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 1, nil)
+	expectReserve(mock, 1, "2026-03-14", 1, 9)
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WithArgs(int64(777), 1, int64(9), "2026-03-14", nil, "Иван Иванов", "Яндекс", "Разработчик", "private", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+	expectGuests(mock)
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WithArgs(int64(42), nil, models.BookingStatusPending, int64(777)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:            777,
+		ServiceID:         1,
+		VisitType:         "private",
+		SelectedDate:      time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:         "Иван Иванов",
+		GuestOrganization: "Яндекс",
+		GuestPosition:     "Разработчик",
+	})
+	if err != nil {
+		t.Fatalf("SaveBooking err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_EmptyOptionalFieldsAreNull(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 4, nil)
+	expectReserve(mock, 4, "2026-03-14", 1, 1)
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WithArgs(int64(777), 4, int64(1), "2026-03-14", nil, "Иван Иванов", nil, nil, nil, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	expectGuests(mock)
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    4,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:    "Иван Иванов",
+	})
+	if err != nil {
+		t.Fatalf("SaveBooking err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_SlotTaken(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 1, nil)
+	mock.ExpectExec(`INSERT INTO service_slots`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`UPDATE service_slots`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    1,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:    "Иван Иванов",
+	})
+	if !errors.Is(err, ErrSlotTaken) {
+		t.Fatalf("expected ErrSlotTaken, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_UserNotRegistered(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM users u`).
+		WithArgs(int64(1), 0, "").
+		WillReturnRows(sqlmock.NewRows(gradeRuleColumns))
+	mock.ExpectRollback()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{UserID: 1, GuestName: "Иван"})
+	if !errors.Is(err, ErrUserNotRegistered) {
+		t.Fatalf("expected ErrUserNotRegistered, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_DBError(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	dbErr := errors.New("connection reset")
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 1, nil)
+	mock.ExpectExec(`INSERT INTO service_slots`).WillReturnError(dbErr)
+	mock.ExpectRollback()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       1,
+		ServiceID:    1,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:    "Иван",
+	})
+	if !errors.Is(err, dbErr) {
+		t.Fatalf("expected wrapped db error, got %v", err)
+	}
+}
+
+func TestGetAvailableDates_SkipsClosedAndFullDays(t *testing.T) {
+	// понедельник
+	now := time.Date(2026, 3, 2, 15, 30, 0, 0, time.UTC)
+	repo, mock, cleanup := newBookingRepo(t, now)
+	defer cleanup()
+
+	expectSchedule(mock, 1, weeklyHours("10:00", "18:00", 1, 3, 5), 2, nil, nil)
+
+	mock.ExpectQuery(`FROM service_slots`).
+		WithArgs(1, "2026-03-02", "2026-03-15").
+		WillReturnRows(sqlmock.NewRows([]string{"slot_date"}).
+			AddRow(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)))
+
+	dates, err := repo.GetAvailableDates(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetAvailableDates err: %v", err)
+	}
+
+	var got []string
+	for _, d := range dates {
+		got = append(got, d.Format("2006-01-02"))
+	}
+	want := []string{"2026-03-02", "2026-03-06", "2026-03-09", "2026-03-11", "2026-03-13"}
+	if len(got) != len(want) {
+		t.Fatalf("dates mismatch: got=%v want=%v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("dates mismatch: got=%v want=%v", got, want)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetAvailableDates_SundayIsSeven(t *testing.T) {
+	// воскресенье
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo, mock, cleanup := newBookingRepo(t, now)
+	defer cleanup()
+
+	expectSchedule(mock, 4, weeklyHours("10:00", "18:00", 7), 5, nil, nil)
+	mock.ExpectQuery(`FROM service_slots`).
+		WillReturnRows(sqlmock.NewRows([]string{"slot_date"}))
+
+	dates, err := repo.GetAvailableDates(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("GetAvailableDates err: %v", err)
+	}
+	if len(dates) != 2 || dates[0].Weekday() != time.Sunday || dates[1].Weekday() != time.Sunday {
+		t.Fatalf("expected two sundays, got %v", dates)
+	}
+}
+
+func TestGetAvailableDates_NoCapacity(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	expectSchedule(mock, 6, weeklyHours("10:00", "18:00", 1, 2, 3, 4, 5, 6, 7), 0, nil, nil)
+
+	dates, err := repo.GetAvailableDates(context.Background(), 6)
+	if err != nil {
+		t.Fatalf("GetAvailableDates err: %v", err)
+	}
+	if len(dates) != 0 {
+		t.Fatalf("expected no dates, got %v", dates)
+	}
+}
+
+func TestGetAvailableDates_ServiceNotFound(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	mock.ExpectQuery(`SELECT weekly_hours, daily_capacity`).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.GetAvailableDates(context.Background(), 99)
+	if !errors.Is(err, ErrServiceNotFound) {
+		t.Fatalf("expected ErrServiceNotFound, got %v", err)
+	}
+}
+
+func TestSaveBooking_TimedSlot(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 4, 60)
+	mock.ExpectExec(`INSERT INTO service_slots`).
+		WithArgs(4, "2026-03-14", "18:00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE service_slots`).
+		WithArgs(4, "2026-03-14", "18:00", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(3)))
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WithArgs(int64(777), 4, int64(3), "2026-03-14", "18:00", "Иван Иванов", nil, nil, nil, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	expectGuests(mock)
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    4,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		SelectedTime: "18:00",
+		GuestName:    "Иван Иванов",
+	})
+	if err != nil {
+		t.Fatalf("SaveBooking err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetAvailableDates_TimedService(t *testing.T) {
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	repo, mock, cleanup := newBookingRepo(t, now)
+	defer cleanup()
+
+	// 4 марта услуга работает только до 07:00 — один слот
+	expectSchedule(mock, 4, weeklyHours("06:00", "23:00", 1, 2, 3, 4, 5, 6, 7), 8, 60,
+		sqlmock.NewRows(scheduleDayColumns).
+			AddRow(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), "06:00", "07:00", "Турнир", false))
+	// 17 часовых слотов с 06:00 до 23:00: 3 марта заполнены все, 5 марта — не все
+	mock.ExpectQuery(`COUNT\(\*\) AS full_slots`).
+		WithArgs(4, "2026-03-02", "2026-03-15").
+		WillReturnRows(sqlmock.NewRows([]string{"slot_date", "full_slots"}).
+			AddRow(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), 17).
+			AddRow(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), 1).
+			AddRow(time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), 16))
+
+	dates, err := repo.GetAvailableDates(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("GetAvailableDates err: %v", err)
+	}
+	if len(dates) != bookingHorizonDays-2 {
+		t.Fatalf("expected %d dates, got %d", bookingHorizonDays-2, len(dates))
+	}
+	for _, d := range dates {
+		if day := d.Format("2006-01-02"); day == "2026-03-03" || day == "2026-03-04" {
+			t.Fatalf("full day %s must be hidden", day)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetAvailableSlots_HidesTakenAndPast(t *testing.T) {
+	// 08:30 по Москве — часовому поясу услуги
+	now := time.Date(2026, 3, 2, 5, 30, 0, 0, time.UTC)
+	repo, mock, cleanup := newBookingRepo(t, now)
+	defer cleanup()
+
+	expectSchedule(mock, 4, weeklyHours("06:00", "12:00", 1, 2, 3, 4, 5, 6, 7), 8, 90, nil)
+	mock.ExpectQuery(`FROM service_slots`).
+		WithArgs(4, "2026-03-02").
+		WillReturnRows(sqlmock.NewRows([]string{"to_char"}).AddRow("09:00"))
+
+	slots, timed, err := repo.GetAvailableSlots(context.Background(), 4, truncateToDate(now))
+	if err != nil {
+		t.Fatalf("GetAvailableSlots err: %v", err)
+	}
+	if !timed {
+		t.Fatalf("expected timed service")
+	}
+	// 06:00 и 07:30 уже начались, 09:00 занят
+	want := []string{"10:30"}
+	if len(slots) != len(want) || slots[0] != want[0] {
+		t.Fatalf("slots mismatch: got=%v want=%v", slots, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestBookingRepository_ServiceTimeZoneNearMidnight(t *testing.T) {
+	// в UTC ещё 10 марта, а в Москве — часовом поясе услуги — уже 01:30 11 марта
+	now := time.Date(2026, 3, 10, 22, 30, 0, 0, time.UTC)
+	repo, mock, cleanup := newBookingRepo(t, now)
+	defer cleanup()
+
+	expectSchedule(mock, 4, weeklyHours("00:00", "04:00", 1, 2, 3, 4, 5, 6, 7), 8, 60, nil)
+	mock.ExpectQuery(`FROM service_slots`).
+		WithArgs(4, "2026-03-11", "2026-03-24").
+		WillReturnRows(sqlmock.NewRows([]string{"slot_date", "full_slots"}))
+
+	dates, err := repo.GetAvailableDates(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("GetAvailableDates err: %v", err)
+	}
+	if len(dates) == 0 || !dates[0].Equal(time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected dates to start on 2026-03-11, got %v", dates)
+	}
+
+	expectSchedule(mock, 4, weeklyHours("00:00", "04:00", 1, 2, 3, 4, 5, 6, 7), 8, 60, nil)
+	mock.ExpectQuery(`FROM service_slots`).
+		WithArgs(4, "2026-03-11").
+		WillReturnRows(sqlmock.NewRows([]string{"to_char"}))
+
+	slots, _, err := repo.GetAvailableSlots(context.Background(), 4, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetAvailableSlots err: %v", err)
+	}
+	// 00:00 и 01:00 по Москве уже начались
+	want := []string{"02:00", "03:00"}
+	if !slices.Equal(slots, want) {
+		t.Fatalf("slots mismatch: got=%v want=%v", slots, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetAvailableSlots_DateOnlyService(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	expectSchedule(mock, 1, weeklyHours("10:00", "18:00", 1, 2, 3), 20, nil, nil)
+
+	slots, timed, err := repo.GetAvailableSlots(context.Background(), 1, time.Now())
+	if err != nil {
+		t.Fatalf("GetAvailableSlots err: %v", err)
+	}
+	if timed || slots != nil {
+		t.Fatalf("expected date-only service, got timed=%v slots=%v", timed, slots)
+	}
+}
+
+var bookingColumns = []string{
+	"id", "user_id", "user_telegram_id", "service_id", "service_title", "slot_id", "booking_date", "booking_time",
+	"guest_name", "guest_organization", "guest_position", "visit_type", "status",
+	"tracker_ticket_id", "created_at", "updated_at",
+}
+
+func TestListUserBookings_OK(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).
+		WithArgs(int64(777)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+	mock.ExpectQuery(`FROM bookings b`).
+		WithArgs(int64(777), 5, 5).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).
+			AddRow(int64(10), int64(5), int64(777), 4, "Теннис в Лужниках", int64(3),
+				time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), "18:00",
+				"Иван Иванов", nil, nil, nil, "confirmed", nil, now, now))
+
+	bookings, total, err := repo.ListUserBookings(context.Background(), 777, 5, 5)
+	if err != nil {
+		t.Fatalf("ListUserBookings err: %v", err)
+	}
+	if total != 7 || len(bookings) != 1 {
+		t.Fatalf("unexpected result: total=%d len=%d", total, len(bookings))
+	}
+	b := bookings[0]
+	if b.Status != models.BookingStatusConfirmed || b.When() != "14.03.2026 18:00" || b.ServiceTitle != "Теннис в Лужниках" {
+		t.Fatalf("unexpected booking: %+v", b)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListUserBookings_Empty(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	bookings, total, err := repo.ListUserBookings(context.Background(), 777, 5, 0)
+	if err != nil || total != 0 || bookings != nil {
+		t.Fatalf("expected empty result, got %v %d %v", bookings, total, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetBooking_OK(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery(`WHERE b.id = \$1`).
+		WithArgs(int64(10)).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).
+			AddRow(int64(10), int64(5), int64(777), 1, "Третьяковская галерея", nil,
+				time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), nil,
+				"Иван Иванов", "Яндекс", nil, "private", "pending", nil, now, now))
+
+	b, err := repo.GetBooking(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetBooking err: %v", err)
+	}
+	if b.UserTelegramID != 777 || b.When() != "14.03.2026" || b.GuestOrganization.String != "Яндекс" {
+		t.Fatalf("unexpected booking: %+v", b)
+	}
+}
+
+func TestGetUserBooking_NotFound(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	mock.ExpectQuery(`FROM bookings b`).
+		WithArgs(int64(777), int64(10)).
+		WillReturnRows(sqlmock.NewRows(bookingColumns))
+
+	_, err := repo.GetUserBooking(context.Background(), 777, 10)
+	if !errors.Is(err, ErrBookingNotFound) {
+		t.Fatalf("expected ErrBookingNotFound, got %v", err)
+	}
+}
+
+func TestCancelBooking_ReleasesSlot(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF b`).
+		WithArgs(int64(10), int64(777)).
+		WillReturnRows(sqlmock.NewRows(lockedBookingColumns).AddRow("confirmed", 4, int64(3), 2))
+	mock.ExpectExec(`UPDATE bookings`).
+		WithArgs(int64(10), models.BookingStatusCancelled).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WithArgs(int64(10), "confirmed", models.BookingStatusCancelled, int64(777)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// освобождаются места всех гостей
+	mock.ExpectExec(`UPDATE service_slots`).
+		WithArgs(int64(3), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE waitlist_entries`).
+		WithArgs(int64(3), WaitlistOfferTTL.Seconds(), "{}").
+		WillReturnRows(sqlmock.NewRows(offerColumns))
+	mock.ExpectCommit()
+
+	if err := repo.CancelBooking(context.Background(), 777, 10); err != nil {
+		t.Fatalf("CancelBooking err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCancelBooking_OffersSlotToWaitlist(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	expires := time.Now().Add(WaitlistOfferTTL)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF b`).
+		WithArgs(int64(10), int64(777)).
+		WillReturnRows(sqlmock.NewRows(lockedBookingColumns).AddRow("confirmed", 4, int64(3), 1))
+	mock.ExpectExec(`UPDATE bookings`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE service_slots`).
+		WithArgs(int64(3), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE waitlist_entries`).
+		WithArgs(int64(3), WaitlistOfferTTL.Seconds(), "{}").
+		WillReturnRows(sqlmock.NewRows(offerColumns).AddRow(int64(21), expires, 1))
+	// освободившееся место снова занимается — под предложение из очереди
+	mock.ExpectExec(`UPDATE service_slots`).
+		WithArgs(int64(3), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO scheduled_jobs`).
+		WithArgs(JobWaitlistOffer, "waitlist_offer:21", []byte(`{"entry_id":21}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO scheduled_jobs`).
+		WithArgs(JobWaitlistExpire, "waitlist_expire:21", []byte(`{"entry_id":21}`), expires).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// больше в свободные места никто не помещается
+	mock.ExpectQuery(`UPDATE waitlist_entries`).
+		WithArgs(int64(3), WaitlistOfferTTL.Seconds(), "{}").
+		WillReturnRows(sqlmock.NewRows(offerColumns))
+	mock.ExpectCommit()
+
+	if err := repo.CancelBooking(context.Background(), 777, 10); err != nil {
+		t.Fatalf("CancelBooking err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCancelBooking_AlreadyCancelled(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF b`).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "service_id", "slot_id"}).AddRow("cancelled", 4, int64(3)))
+	mock.ExpectRollback()
+
+	err := repo.CancelBooking(context.Background(), 777, 10)
+	if !errors.Is(err, models.ErrInvalidStatusTransition) {
+		t.Fatalf("expected ErrInvalidStatusTransition, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateBookingStatus_Confirm(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF b`).
+		WithArgs(int64(10), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "service_id", "slot_id"}).AddRow("pending", 1, int64(2)))
+	mock.ExpectExec(`UPDATE bookings`).
+		WithArgs(int64(10), models.BookingStatusConfirmed).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WithArgs(int64(10), "pending", models.BookingStatusConfirmed, int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO tracker_outbox`).
+		WithArgs(int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.UpdateBookingStatus(context.Background(), 10, models.BookingStatusConfirmed, 100); err != nil {
+		t.Fatalf("UpdateBookingStatus err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRescheduleBooking_MovesSlot(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF b`).
+		WithArgs(int64(10), int64(777)).
+		WillReturnRows(sqlmock.NewRows(append(lockedBookingColumns, "booking_date", "booking_time")).
+			AddRow("pending", 4, int64(3), 3, "2026-03-14", "18:00"))
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 4, 60)
+	mock.ExpectExec(`UPDATE service_slots`).
+		WithArgs(int64(3), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE waitlist_entries`).
+		WithArgs(int64(3), WaitlistOfferTTL.Seconds(), "{}").
+		WillReturnRows(sqlmock.NewRows(offerColumns))
+	mock.ExpectExec(`INSERT INTO service_slots`).
+		WithArgs(4, "2026-03-20", "10:00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE service_slots`).
+		WithArgs(4, "2026-03-20", "10:00", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(8)))
+	mock.ExpectExec(`UPDATE bookings`).
+		WithArgs(int64(10), "2026-03-20", "10:00", int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// статус не меняется, в историю попадают прежние и новые дата и время
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WithArgs(int64(10), models.BookingStatusPending, int64(777), "2026-03-14", "18:00", "2026-03-20", "10:00").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.RescheduleBooking(context.Background(), 777, 10, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), "10:00")
+	if err != nil {
+		t.Fatalf("RescheduleBooking err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRescheduleBooking_SlotTakenRollsBack(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF b`).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "service_id", "slot_id"}).AddRow("pending", 1, nil))
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 1, nil)
+	mock.ExpectExec(`INSERT INTO service_slots`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`UPDATE service_slots`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err := repo.RescheduleBooking(context.Background(), 777, 10, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), "")
+	if !errors.Is(err, ErrSlotTaken) {
+		t.Fatalf("expected ErrSlotTaken, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_GroupReservesPlacePerGuest(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 1, nil)
+	expectReserve(mock, 1, "2026-03-14", 3, 9)
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WithArgs(int64(777), 1, int64(9), "2026-03-14", nil, "Иван Иванов", nil, nil, nil, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+	expectGuests(mock)
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    1,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:    "Иван Иванов",
+		Guests: []models.BookingGuest{
+			{Name: "Иван Иванов"}, {Name: "Пётр Петров"}, {Name: "Анна Смирнова"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SaveBooking err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_TimeOutsideSchedule(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 4, 60)
+	mock.ExpectRollback()
+
+	// 21:00 — после закрытия, такую кнопку могла оставить устаревшая клавиатура
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    4,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		SelectedTime: "21:00",
+		GuestName:    "Иван Иванов",
+	})
+	if !errors.Is(err, ErrSlotUnavailable) {
+		t.Fatalf("expected ErrSlotUnavailable, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_ClosedDay(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	// суббота 14.03 — выходной
+	expectSchedule(mock, 1, weeklyHours("10:00", "18:00", 1, 2, 3, 4, 5), 10, nil, nil)
+	mock.ExpectRollback()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    1,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:    "Иван Иванов",
+	})
+	if !errors.Is(err, ErrSlotUnavailable) {
+		t.Fatalf("expected ErrSlotUnavailable, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRescheduleBooking_PastDateKeepsSlot(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF b`).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "service_id", "slot_id"}).AddRow("pending", 1, int64(3)))
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 1, nil)
+	mock.ExpectRollback()
+
+	err := repo.RescheduleBooking(context.Background(), 777, 10, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), "")
+	if !errors.Is(err, ErrSlotUnavailable) {
+		t.Fatalf("expected ErrSlotUnavailable, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRescheduleBooking_MonthlyLimitExcludesMovedBooking(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF b`).
+		WithArgs(int64(10), int64(777)).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "service_id", "slot_id", "telegram_id", "visit_type"}).
+			AddRow("confirmed", 1, int64(3), int64(777), "private"))
+	mock.ExpectQuery(`FOR UPDATE OF u`).
+		WithArgs(int64(777), 1, "private").
+		WillReturnRows(sqlmock.NewRows(gradeRuleColumns).AddRow(1, "private", true, 2))
+	// переносимое бронирование не учитывается, в апреле уже есть два других
+	mock.ExpectQuery(`date_trunc\('month'`).
+		WithArgs(int64(777), 1, "private", "2026-04-02", int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	err := repo.RescheduleBooking(context.Background(), 777, 10, time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC), "")
+	if !errors.Is(err, ErrMonthlyLimitReached) {
+		t.Fatalf("expected ErrMonthlyLimitReached, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListConfirmedBookings_OK(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	now := time.Now()
+	columns := append([]string{"service_location", "service_timezone", "service_slot_minutes"}, bookingColumns...)
+	mock.ExpectQuery(`WHERE u.telegram_id = \$1 AND b.status = 'confirmed'`).
+		WithArgs(int64(777)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("Москва, Лужнецкая набережная, 24", "Europe/Moscow", 60,
+				int64(10), int64(5), int64(777), 4, "Теннис в Лужниках", int64(3),
+				time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), "18:00",
+				"Иван Иванов", nil, nil, nil, "confirmed", nil, now, now))
+
+	bookings, err := repo.ListConfirmedBookings(context.Background(), 777)
+	if err != nil {
+		t.Fatalf("ListConfirmedBookings err: %v", err)
+	}
+	if len(bookings) != 1 || bookings[0].ServiceTimezone != "Europe/Moscow" || bookings[0].ServiceSlotMinutes.Int64 != 60 {
+		t.Fatalf("unexpected bookings: %+v", bookings)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_GradeNotAllowed(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF u`).
+		WithArgs(int64(777), 1, "private").
+		WillReturnRows(sqlmock.NewRows(gradeRuleColumns).AddRow(1, "private", false, nil))
+	mock.ExpectRollback()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    1,
+		VisitType:    "private",
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:    "Иван Иванов",
+	})
+	if !errors.Is(err, ErrGradeNotAllowed) {
+		t.Fatalf("expected ErrGradeNotAllowed, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_MonthlyLimitReached(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF u`).
+		WillReturnRows(sqlmock.NewRows(gradeRuleColumns).AddRow(0, "", true, 2))
+	mock.ExpectQuery(`date_trunc\('month'`).
+		WithArgs(int64(777), 4, "", "2026-03-14", int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    4,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		SelectedTime: "18:00",
+		GuestName:    "Иван Иванов",
+	})
+	if !errors.Is(err, ErrMonthlyLimitReached) {
+		t.Fatalf("expected ErrMonthlyLimitReached, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCheckBookingAllowed_SpecificVisitTypeRule(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, time.Now())
+	defer cleanup()
+
+	mock.ExpectQuery(`ORDER BY visit_type DESC`).
+		WithArgs(int64(777), 1, "group").
+		WillReturnRows(sqlmock.NewRows(gradeRuleColumns).AddRow(1, nil, nil, nil))
+
+	if err := repo.CheckBookingAllowed(context.Background(), 777, 1, "group"); err != nil {
+		t.Fatalf("CheckBookingAllowed err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveBooking_MultipleGuests(t *testing.T) {
+	repo, mock, cleanup := newBookingRepo(t, bookingNow)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectNoGradeRule(mock)
+	expectOpenDay(mock, 1, nil)
+	expectReserve(mock, 1, "2026-03-14", 2, 9)
+	// контактное лицо — первый гость, а не последний введённый
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WithArgs(int64(777), 1, int64(9), "2026-03-14", nil, "Иван Иванов", "Яндекс", nil, "public", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+	mock.ExpectExec(`INSERT INTO booking_guests`).
+		WithArgs(int64(42),
+			pq.Array([]string{"Иван Иванов", "Пётр Петров"}),
+			pq.Array([]string{"Яндекс", ""}),
+			pq.Array([]string{"", "Дизайнер"})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := repo.SaveBooking(context.Background(), &models.BookingState{
+		UserID:       777,
+		ServiceID:    1,
+		VisitType:    "public",
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:    "Пётр Петров",
+		Guests: []models.BookingGuest{
+			{Name: "Иван Иванов", Organization: "Яндекс"},
+			{Name: "Пётр Петров", Position: "Дизайнер"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SaveBooking err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetAvailableDates_SkipsHolidaysAndClosures(t *testing.T) {
+	// понедельник
+	now := time.Date(2026, 6, 8, 9, 0, 0, 0, time.UTC)
+	repo, mock, cleanup := newBookingRepo(t, now)
+	defer cleanup()
+
+	expectSchedule(mock, 1, weeklyHours("10:00", "18:00", 5), 10, nil,
+		sqlmock.NewRows(scheduleDayColumns).
+			AddRow(time.Date(2026, 6, 12, 0, 0, 0, 0, time.UTC), nil, nil, "День России", true).
+			AddRow(time.Date(2026, 6, 14, 0, 0, 0, 0, time.UTC), "12:00", "16:00", "Открытие сезона", false))
+	mock.ExpectQuery(`FROM service_slots`).
+		WillReturnRows(sqlmock.NewRows([]string{"slot_date"}))
+
+	dates, err := repo.GetAvailableDates(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetAvailableDates err: %v", err)
+	}
+
+	var got []string
+	for _, d := range dates {
+		got = append(got, d.Format("2006-01-02"))
+	}
+	// пятница 12 июня — праздник, воскресенье 14 июня открыто исключением
+	want := "2026-06-14 2026-06-19"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("dates mismatch: got=%v want=%v", got, want)
+	}
+}
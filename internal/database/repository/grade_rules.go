@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+const (
+	// gradeRuleQuery выбирает правило для уровня пользователя: правило для конкретного
+	// типа посещения важнее общего правила услуги (visit_type = '').
+	gradeRuleQuery = `
+SELECT COALESCE(u.grade, 0) AS grade, r.visit_type, r.can_book, r.monthly_limit
+FROM users u
+LEFT JOIN LATERAL (
+	SELECT visit_type, can_book, monthly_limit
+	FROM service_grade_rules
+	WHERE service_id = $2
+		AND grade = COALESCE(u.grade, 0)
+		AND visit_type IN ('', $3)
+	ORDER BY visit_type DESC
+	LIMIT 1
+) r ON TRUE
+WHERE u.telegram_id = $1
+`
+	// lockGradeRuleQuery дополнительно блокирует пользователя, чтобы параллельные
+	// бронирования одного человека не обошли месячный лимит.
+	lockGradeRuleQuery = gradeRuleQuery + `FOR UPDATE OF u`
+
+	monthlyBookingsQuery = `
+SELECT COUNT(*)
+FROM bookings b
+JOIN users u ON u.id = b.user_id
+WHERE u.telegram_id = $1
+	AND b.service_id = $2
+	AND ($3::text = '' OR b.visit_type = $3)
+	AND b.booking_date >= date_trunc('month', $4::date)
+	AND b.booking_date < date_trunc('month', $4::date) + INTERVAL '1 month'
+	AND COALESCE(b.status, 'pending') <> 'cancelled'
+	AND b.id <> $5
+`
+)
+
+var (
+	// ErrGradeNotAllowed возвращается, если уровень пользователя не позволяет бронировать услугу.
+	ErrGradeNotAllowed = errors.New("service is not available for user grade")
+	// ErrMonthlyLimitReached возвращается, если пользователь исчерпал месячный лимит бронирований услуги.
+	ErrMonthlyLimitReached = errors.New("monthly booking limit reached")
+)
+
+// gradeRule — правило service_grade_rules, применимое к пользователю. Пустые поля
+// означают, что правила нет и ограничений тоже.
+type gradeRule struct {
+	Grade        models.Grade   `db:"grade"`
+	VisitType    sql.NullString `db:"visit_type"`
+	CanBook      sql.NullBool   `db:"can_book"`
+	MonthlyLimit sql.NullInt64  `db:"monthly_limit"`
+}
+
+func (g gradeRule) allowsBooking() bool {
+	return !g.CanBook.Valid || g.CanBook.Bool
+}
+
+// CheckBookingAllowed проверяет, может ли пользователь бронировать услугу с этим типом посещения.
+// Месячный лимит зависит от даты и проверяется только в SaveBooking.
+func (r *BookingRepository) CheckBookingAllowed(ctx context.Context, telegramID int64, serviceID int, visitType string) error {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	rule, err := loadGradeRule(ctxQ, r.db, r.logger, gradeRuleQuery, telegramID, serviceID, visitType)
+	if err != nil {
+		return err
+	}
+	if !rule.allowsBooking() {
+		return ErrGradeNotAllowed
+	}
+	return nil
+}
+
+// checkGradeRules проверяет правила уровня и месячный лимит внутри транзакции бронирования.
+// excludeBookingID — переносимое бронирование, которое не считается в лимите; 0 — новое бронирование.
+func checkGradeRules(ctx context.Context, tx *sqlx.Tx, logger *zap.Logger, telegramID int64, serviceID int, visitType, date string, excludeBookingID int64) error {
+	rule, err := loadGradeRule(ctx, tx, logger, lockGradeRuleQuery, telegramID, serviceID, visitType)
+	if err != nil {
+		return err
+	}
+
+	fields := []zap.Field{
+		zap.Int64("telegram_id", telegramID),
+		zap.Int("service_id", serviceID),
+		zap.Stringer("grade", rule.Grade),
+	}
+	if !rule.allowsBooking() {
+		logger.Info("booking_grade_denied", fields...)
+		return ErrGradeNotAllowed
+	}
+	if !rule.MonthlyLimit.Valid {
+		return nil
+	}
+
+	var count int64
+	start := time.Now()
+	err = tx.GetContext(ctx, &count, monthlyBookingsQuery, telegramID, serviceID, rule.VisitType.String, date, excludeBookingID)
+	observeQuery(logger, "read", start, err)
+	if err != nil {
+		logger.Error("count_monthly_bookings_failed", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return fmt.Errorf("count monthly bookings: %w", err)
+	}
+	if count >= rule.MonthlyLimit.Int64 {
+		logger.Info("booking_monthly_limit_reached", append(fields, zap.Int64("limit", rule.MonthlyLimit.Int64))...)
+		return ErrMonthlyLimitReached
+	}
+	return nil
+}
+
+func loadGradeRule(ctx context.Context, q sqlx.QueryerContext, logger *zap.Logger, query string, telegramID int64, serviceID int, visitType string) (*gradeRule, error) {
+	var rule gradeRule
+	start := time.Now()
+	err := sqlx.GetContext(ctx, q, &rule, query, telegramID, serviceID, visitType)
+	observeQuery(logger, "read", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotRegistered
+		}
+		logger.Error("get_grade_rule_failed", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return nil, fmt.Errorf("get grade rule: %w", err)
+	}
+	return &rule, nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/metrics"
+)
+
+func TestMain(m *testing.M) {
+	if _, err := metrics.NewMetrics(zap.NewNop()); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/metrics"
+)
+
+// observeQuery записывает метрики запроса и предупреждает о медленных запросах.
+// sql.ErrNoRows не считается ошибкой базы данных.
+func observeQuery(logger *zap.Logger, op string, start time.Time, err error) {
+	dur := time.Since(start).Seconds()
+
+	metrics.Default.DatabaseQueriesTotal.WithLabelValues(op).Inc()
+	metrics.Default.DatabaseQueryDuration.WithLabelValues(op).Observe(dur)
+	if dur > slowQueryThreshold.Seconds() {
+		logger.Warn("slow_db_query", zap.String("operation", op), zap.Float64("duration_seconds", dur))
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		metrics.Default.DatabaseErrorsTotal.WithLabelValues(op).Inc()
+	}
+}
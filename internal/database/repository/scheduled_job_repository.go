@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+const (
+	scheduleJobQuery = `
+INSERT INTO scheduled_jobs (kind, dedup_key, payload, run_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (dedup_key) DO NOTHING
+`
+	// claimJobsQuery переводит готовые задания в running. SKIP LOCKED не даёт двум репликам
+	// взять одно задание; running-задания упавшей реплики возвращаются после locked_until.
+	claimJobsQuery = `
+UPDATE scheduled_jobs
+SET status = 'running',
+	attempts = attempts + 1,
+	locked_until = NOW() + make_interval(secs => $2),
+	updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+	SELECT id FROM scheduled_jobs
+	WHERE (status = 'pending' AND run_at <= NOW())
+	   OR (status = 'running' AND locked_until < NOW())
+	ORDER BY run_at, id
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, attempts
+`
+	completeJobQuery = `
+UPDATE scheduled_jobs
+SET status = 'done', locked_until = NULL, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+	retryJobQuery = `
+UPDATE scheduled_jobs
+SET status = 'pending', run_at = $2, locked_until = NULL, last_error = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+	failJobQuery = `
+UPDATE scheduled_jobs
+SET status = 'failed', locked_until = NULL, last_error = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+)
+
+type ScheduledJobRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+func NewScheduledJobRepository(db *sqlx.DB, logger *zap.Logger) *ScheduledJobRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ScheduledJobRepository{db: db, logger: logger}
+}
+
+// Schedule планирует задание на runAt. Если задание с таким key уже существует,
+// ничего не меняется и возвращается false.
+func (r *ScheduledJobRepository) Schedule(ctx context.Context, kind, key string, runAt time.Time, payload interface{}) (bool, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("marshal job payload: %w", err)
+	}
+
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := r.db.ExecContext(ctxQ, scheduleJobQuery, kind, key, data, runAt)
+	observeQuery(r.logger, "create", start, err)
+	if err != nil {
+		r.logger.Error("schedule_job_failed", zap.Error(err), zap.String("kind", kind), zap.String("key", key))
+		return false, fmt.Errorf("schedule job: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("schedule job: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Claim забирает до limit готовых заданий и блокирует их на время lease.
+func (r *ScheduledJobRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledJob, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var jobs []models.ScheduledJob
+	start := time.Now()
+	err := r.db.SelectContext(ctxQ, &jobs, claimJobsQuery, limit, lease.Seconds())
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("claim_jobs_failed", zap.Error(err))
+		return nil, fmt.Errorf("claim jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Complete отмечает задание выполненным.
+func (r *ScheduledJobRepository) Complete(ctx context.Context, jobID int64) error {
+	return r.exec(ctx, completeJobQuery, jobID)
+}
+
+// Retry возвращает задание в очередь с запуском в runAt.
+func (r *ScheduledJobRepository) Retry(ctx context.Context, jobID int64, runAt time.Time, lastErr string) error {
+	return r.exec(ctx, retryJobQuery, jobID, runAt, lastErr)
+}
+
+// Fail прекращает попытки выполнить задание.
+func (r *ScheduledJobRepository) Fail(ctx context.Context, jobID int64, lastErr string) error {
+	return r.exec(ctx, failJobQuery, jobID, lastErr)
+}
+
+func (r *ScheduledJobRepository) exec(ctx context.Context, query string, jobID int64, args ...interface{}) error {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := r.db.ExecContext(ctxQ, query, append([]interface{}{jobID}, args...)...)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("update_job_failed", zap.Error(err), zap.Int64("job_id", jobID))
+		return fmt.Errorf("update job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func newScheduledJobRepo(t *testing.T) (*ScheduledJobRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+
+	return NewScheduledJobRepository(sqlx.NewDb(db, "postgres"), zap.NewNop()), mock, func() { _ = db.Close() }
+}
+
+func TestScheduledJobs_Schedule(t *testing.T) {
+	repo, mock, cleanup := newScheduledJobRepo(t)
+	defer cleanup()
+
+	runAt := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`INSERT INTO scheduled_jobs(.|\n)*ON CONFLICT \(dedup_key\) DO NOTHING`).
+		WithArgs("booking_reminder", "key-1", []byte(`{"booking_id":42}`), runAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO scheduled_jobs`).
+		WithArgs("booking_reminder", "key-1", []byte(`{"booking_id":42}`), runAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	payload := map[string]int64{"booking_id": 42}
+	created, err := repo.Schedule(context.Background(), "booking_reminder", "key-1", runAt, payload)
+	if err != nil || !created {
+		t.Fatalf("expected job to be created, got %v, %v", created, err)
+	}
+	created, err = repo.Schedule(context.Background(), "booking_reminder", "key-1", runAt, payload)
+	if err != nil || created {
+		t.Fatalf("expected duplicate to be ignored, got %v, %v", created, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScheduledJobs_Claim(t *testing.T) {
+	repo, mock, cleanup := newScheduledJobRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`UPDATE scheduled_jobs(.|\n)*status = 'running' AND locked_until < NOW\(\)(.|\n)*FOR UPDATE SKIP LOCKED`).
+		WithArgs(20, float64(300)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "payload", "attempts"}).
+			AddRow(int64(1), "booking_reminder", []byte(`{"booking_id":42}`), 1))
+
+	jobs, err := repo.Claim(context.Background(), 20, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Kind != "booking_reminder" || string(jobs[0].Payload) != `{"booking_id":42}` {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScheduledJobs_CompleteRetryFail(t *testing.T) {
+	repo, mock, cleanup := newScheduledJobRepo(t)
+	defer cleanup()
+
+	at := time.Date(2026, 3, 1, 12, 5, 0, 0, time.UTC)
+	mock.ExpectExec(`SET status = 'done'`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SET status = 'pending', run_at = \$2`).WithArgs(int64(2), at, "timeout").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SET status = 'failed'`).WithArgs(int64(3), "bad payload").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.Complete(context.Background(), 1); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if err := repo.Retry(context.Background(), 2, at, "timeout"); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if err := repo.Fail(context.Background(), 3, "bad payload"); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
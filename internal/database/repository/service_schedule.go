@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/schedule"
+)
+
+// scheduleDaysQuery возвращает исключения услуги и праздники за период [$2, $3].
+// Исключение без времени закрывает услугу на весь день.
+const scheduleDaysQuery = `
+SELECT e.exception_date AS day, to_char(e.open_time, 'HH24:MI') AS open_time,
+	to_char(e.close_time, 'HH24:MI') AS close_time, e.note, FALSE AS holiday
+FROM service_schedule_exceptions e
+WHERE e.service_id = $1 AND e.exception_date BETWEEN $2 AND $3
+UNION ALL
+SELECT h.holiday_date, NULL, NULL, h.title, TRUE
+FROM public_holidays h
+WHERE h.holiday_date BETWEEN $2 AND $3
+ORDER BY 1, 2
+`
+
+type scheduleDayRow struct {
+	Day       time.Time      `db:"day"`
+	OpenTime  sql.NullString `db:"open_time"`
+	CloseTime sql.NullString `db:"close_time"`
+	Note      string         `db:"note"`
+	Holiday   bool           `db:"holiday"`
+}
+
+// loadScheduleDays дополняет недельное расписание исключениями и праздниками за период.
+func loadScheduleDays(ctx context.Context, db sqlx.QueryerContext, logger *zap.Logger, sched *schedule.Schedule, serviceID int, from, to time.Time) error {
+	var rows []scheduleDayRow
+	start := time.Now()
+	err := sqlx.SelectContext(ctx, db, &rows, scheduleDaysQuery,
+		serviceID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	observeQuery(logger, "read", start, err)
+	if err != nil {
+		logger.Error("get_schedule_exceptions_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return fmt.Errorf("get schedule exceptions: %w", err)
+	}
+
+	for _, row := range rows {
+		if row.Holiday {
+			sched.Holidays = append(sched.Holidays, schedule.Holiday{Date: row.Day, Title: row.Note})
+			continue
+		}
+
+		// строки одной даты идут подряд и складываются в одно исключение
+		n := len(sched.Exceptions)
+		if n == 0 || !sched.Exceptions[n-1].Date.Equal(row.Day) {
+			sched.Exceptions = append(sched.Exceptions, schedule.Exception{Date: row.Day, Note: row.Note})
+			n++
+		}
+		if !row.OpenTime.Valid || !row.CloseTime.Valid {
+			continue
+		}
+		open, err1 := schedule.ParseClock(row.OpenTime.String)
+		closing, err2 := schedule.ParseClock(row.CloseTime.String)
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("service %d exception %s: invalid hours %s-%s",
+				serviceID, row.Day.Format("2006-01-02"), row.OpenTime.String, row.CloseTime.String)
+		}
+		e := &sched.Exceptions[n-1]
+		e.Hours = append(e.Hours, schedule.Interval{Open: open, Close: closing})
+		if e.Note == "" {
+			e.Note = row.Note
+		}
+	}
+	return nil
+}
+
+// serviceLocation возвращает часовой пояс услуги; пустой или неизвестный — UTC.
+func serviceLocation(logger *zap.Logger, tz string) *time.Location {
+	if tz == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		logger.Warn("unknown_service_timezone", zap.String("timezone", tz), zap.Error(err))
+		return time.UTC
+	}
+	return loc
+}
@@ -2,21 +2,470 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
 	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/schedule"
+)
+
+// hiddenForUserQuery — условие, что услуга скрыта для уровня пользователя $1 правилом
+// service_grade_rules. Незарегистрированные пользователи считаются внешними (grade 0).
+const hiddenForUserQuery = `EXISTS (
+	SELECT 1 FROM service_grade_rules r
+	WHERE r.service_id = %s
+		AND r.visit_type = ''
+		AND NOT r.can_view
+		AND r.grade = COALESCE((SELECT grade FROM users WHERE telegram_id = $1), 0)
+)`
+
+const serviceColumns = `s.id, s.title, s.description, s.rules, s.weekly_hours, s.visit_options,
+	s.has_booking, s.sort_order, s.is_active, COALESCE(s.category_id, 0) AS category_id`
+
+var (
+	visibleServicesQuery = `SELECT ` + serviceColumns + ` FROM services s
+WHERE s.is_active AND NOT ` + fmt.Sprintf(hiddenForUserQuery, "s.id") + `
+ORDER BY s.sort_order, s.id`
+	serviceVisibleQuery = `SELECT NOT ` + fmt.Sprintf(hiddenForUserQuery, "$2")
+
+	visibleCategoriesQuery = `
+SELECT COALESCE(c.id, 0) AS id, COALESCE(c.title, 'Другое') AS title, COUNT(*) AS services
+FROM services s
+LEFT JOIN service_categories c ON c.id = s.category_id
+WHERE s.is_active AND NOT ` + fmt.Sprintf(hiddenForUserQuery, "s.id") + `
+GROUP BY c.id, c.title, c.sort_order
+ORDER BY c.sort_order NULLS LAST, c.id`
+	visibleCategoryServicesQuery = `SELECT ` + serviceColumns + ` FROM services s
+WHERE s.is_active AND COALESCE(s.category_id, 0) = $2 AND NOT ` + fmt.Sprintf(hiddenForUserQuery, "s.id") + `
+ORDER BY s.sort_order, s.id`
+
+	getServiceByIDQuery = `SELECT ` + serviceColumns + ` FROM services s WHERE s.id = $1`
+	lockServiceQuery    = getServiceByIDQuery + ` FOR UPDATE`
+	listServicesQuery   = `SELECT ` + serviceColumns + ` FROM services s ORDER BY s.sort_order, s.id`
+	prevServiceQuery    = `SELECT ` + serviceColumns + ` FROM services s WHERE (s.sort_order, s.id) < ($1, $2) ORDER BY s.sort_order DESC, s.id DESC LIMIT 1 FOR UPDATE`
+	nextServiceQuery    = `SELECT ` + serviceColumns + ` FROM services s WHERE (s.sort_order, s.id) > ($1, $2) ORDER BY s.sort_order, s.id LIMIT 1 FOR UPDATE`
+	insertServiceQuery  = `
+INSERT INTO services (title, description, rules, weekly_hours, visit_options, has_booking, is_active, category_id, sort_order)
+VALUES ($1, $2, $3, $4::jsonb, $5::jsonb, $6, $7, NULLIF($8, 0), (SELECT COALESCE(MAX(sort_order), 0) + 10 FROM services))
+RETURNING id, title, description, rules, weekly_hours, visit_options, has_booking, sort_order, is_active,
+	COALESCE(category_id, 0) AS category_id
+`
+	updateServiceQuery = `
+UPDATE services
+SET title = $2, description = $3, rules = $4, weekly_hours = $5::jsonb, visit_options = $6::jsonb, has_booking = $7,
+	category_id = NULLIF($8, 0)
+WHERE id = $1
+`
+	listCategoriesQuery = `
+SELECT c.id, c.title, COUNT(s.id) AS services
+FROM service_categories c
+LEFT JOIN services s ON s.category_id = c.id
+GROUP BY c.id, c.title, c.sort_order
+ORDER BY c.sort_order, c.id
+`
+	setServiceActiveQuery    = `UPDATE services SET is_active = $2 WHERE id = $1`
+	setServiceSortOrderQuery = `UPDATE services SET sort_order = $2 WHERE id = $1`
+	serviceInUseQuery        = `
+SELECT EXISTS (SELECT 1 FROM bookings WHERE service_id = $1)
+	OR EXISTS (SELECT 1 FROM waitlist_entries WHERE service_id = $1)
+`
+	deleteServiceQuery = `DELETE FROM services WHERE id = $1`
+
+	getServiceHoursQuery = `SELECT weekly_hours, COALESCE(timezone, '') AS timezone FROM services WHERE id = $1`
+
+	listServicePhotosQuery = `
+SELECT id, service_id, local_path, COALESCE(file_id, '') AS file_id
+FROM service_photos
+WHERE service_id = $1
+ORDER BY sort_order, id
+`
+	setServicePhotoFileIDQuery = `UPDATE service_photos SET file_id = $2 WHERE id = $1`
 )
 
+// ErrServiceInUse — у услуги есть бронирования или записи в листе ожидания; её можно только скрыть.
+var ErrServiceInUse = errors.New("service has bookings")
+
 type ServiceRepository struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	logger *zap.Logger
 }
 
-func NewServiceRepository(db *sqlx.DB) *ServiceRepository {
-	return &ServiceRepository{db: db}
+func NewServiceRepository(db *sqlx.DB, logger *zap.Logger) *ServiceRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ServiceRepository{db: db, logger: logger}
 }
 
-func (s *ServiceRepository) GetServicesOfBoxSolutions(ctx context.Context) ([]models.Service, error) {
+// GetServicesOfBoxSolutions возвращает активные услуги, которые видны пользователю с его уровнем,
+// в порядке sort_order.
+func (s *ServiceRepository) GetServicesOfBoxSolutions(ctx context.Context, telegramID int64) ([]models.Service, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
 	var services []models.Service
-	err := s.db.SelectContext(ctx, &services, `SELECT id, title FROM services ORDER BY id`)
-	return services, err
+	start := time.Now()
+	err := s.db.SelectContext(ctxQ, &services, visibleServicesQuery, telegramID)
+	observeQuery(s.logger, "read", start, err)
+	if err != nil {
+		s.logger.Error("get_visible_services_failed", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return nil, fmt.Errorf("get visible services: %w", err)
+	}
+	return services, nil
+}
+
+// IsServiceVisible сообщает, может ли пользователь открыть карточку услуги.
+// Для услуг без правил возвращает true.
+func (s *ServiceRepository) IsServiceVisible(ctx context.Context, telegramID int64, serviceID int) (bool, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var visible bool
+	start := time.Now()
+	err := s.db.GetContext(ctxQ, &visible, serviceVisibleQuery, telegramID, serviceID)
+	observeQuery(s.logger, "read", start, err)
+	if err != nil {
+		s.logger.Error("check_service_visibility_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return false, fmt.Errorf("check service %d visibility: %w", serviceID, err)
+	}
+	return visible, nil
+}
+
+// GetBoxSolutionCategories возвращает категории, в которых пользователю видна хотя бы одна
+// активная услуга. Услуги без категории собираются в категорию «Другое» с ID 0 в конце списка.
+func (s *ServiceRepository) GetBoxSolutionCategories(ctx context.Context, telegramID int64) ([]models.ServiceCategory, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var categories []models.ServiceCategory
+	start := time.Now()
+	err := s.db.SelectContext(ctxQ, &categories, visibleCategoriesQuery, telegramID)
+	observeQuery(s.logger, "read", start, err)
+	if err != nil {
+		s.logger.Error("get_service_categories_failed", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return nil, fmt.Errorf("get service categories: %w", err)
+	}
+	return categories, nil
+}
+
+// GetServicesOfCategory возвращает активные услуги категории, которые видны пользователю,
+// в порядке sort_order. categoryID 0 — услуги без категории.
+func (s *ServiceRepository) GetServicesOfCategory(ctx context.Context, telegramID int64, categoryID int) ([]models.Service, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var services []models.Service
+	start := time.Now()
+	err := s.db.SelectContext(ctxQ, &services, visibleCategoryServicesQuery, telegramID, categoryID)
+	observeQuery(s.logger, "read", start, err)
+	if err != nil {
+		s.logger.Error("get_category_services_failed", zap.Error(err), zap.Int("category_id", categoryID))
+		return nil, fmt.Errorf("get category %d services: %w", categoryID, err)
+	}
+	return services, nil
+}
+
+// GetServiceByID возвращает услугу, в том числе неактивную, или ErrServiceNotFound.
+func (s *ServiceRepository) GetServiceByID(ctx context.Context, serviceID int) (*models.Service, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var service models.Service
+	start := time.Now()
+	err := s.db.GetContext(ctxQ, &service, getServiceByIDQuery, serviceID)
+	observeQuery(s.logger, "read", start, err)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrServiceNotFound
+	}
+	if err != nil {
+		s.logger.Error("get_service_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return nil, fmt.Errorf("get service %d: %w", serviceID, err)
+	}
+	return &service, nil
+}
+
+// ListServices возвращает весь каталог, включая неактивные услуги, в порядке sort_order.
+func (s *ServiceRepository) ListServices(ctx context.Context) ([]models.Service, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var services []models.Service
+	start := time.Now()
+	err := s.db.SelectContext(ctxQ, &services, listServicesQuery)
+	observeQuery(s.logger, "read", start, err)
+	if err != nil {
+		s.logger.Error("list_services_failed", zap.Error(err))
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+	return services, nil
+}
+
+// ListServiceCategories возвращает все категории каталога с числом услуг в каждой,
+// включая скрытые, — для выбора категории в редакторе услуг.
+func (s *ServiceRepository) ListServiceCategories(ctx context.Context) ([]models.ServiceCategory, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var categories []models.ServiceCategory
+	start := time.Now()
+	err := s.db.SelectContext(ctxQ, &categories, listCategoriesQuery)
+	observeQuery(s.logger, "read", start, err)
+	if err != nil {
+		s.logger.Error("list_service_categories_failed", zap.Error(err))
+		return nil, fmt.Errorf("list service categories: %w", err)
+	}
+	return categories, nil
+}
+
+// GetServiceSchedule возвращает расписание услуги с исключениями и праздниками за период [from, to]
+// или ErrServiceNotFound.
+func (s *ServiceRepository) GetServiceSchedule(ctx context.Context, serviceID int, from, to time.Time) (*schedule.Schedule, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var row struct {
+		Weekly   schedule.Weekly `db:"weekly_hours"`
+		Timezone string          `db:"timezone"`
+	}
+	start := time.Now()
+	err := s.db.GetContext(ctxQ, &row, getServiceHoursQuery, serviceID)
+	observeQuery(s.logger, "read", start, err)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrServiceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get service %d hours: %w", serviceID, err)
+	}
+
+	sched := &schedule.Schedule{Weekly: row.Weekly, Location: serviceLocation(s.logger, row.Timezone)}
+	if err := loadScheduleDays(ctxQ, s.db, s.logger, sched, serviceID, from, to); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// ListServicePhotos возвращает фотографии карточки услуги в порядке показа.
+func (s *ServiceRepository) ListServicePhotos(ctx context.Context, serviceID int) ([]models.ServicePhoto, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var photos []models.ServicePhoto
+	start := time.Now()
+	err := s.db.SelectContext(ctxQ, &photos, listServicePhotosQuery, serviceID)
+	observeQuery(s.logger, "read", start, err)
+	if err != nil {
+		s.logger.Error("list_service_photos_failed", zap.Error(err), zap.Int("service_id", serviceID))
+		return nil, fmt.Errorf("list service %d photos: %w", serviceID, err)
+	}
+	return photos, nil
+}
+
+// SetServicePhotoFileID запоминает file_id, выданный Telegram после загрузки фотографии.
+func (s *ServiceRepository) SetServicePhotoFileID(ctx context.Context, photoID int64, fileID string) error {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := s.db.ExecContext(ctxQ, setServicePhotoFileIDQuery, photoID, fileID)
+	observeQuery(s.logger, "update", start, err)
+	if err != nil {
+		s.logger.Error("set_service_photo_file_id_failed", zap.Error(err), zap.Int64("photo_id", photoID))
+		return fmt.Errorf("set photo %d file id: %w", photoID, err)
+	}
+	return nil
+}
+
+// CreateService добавляет услугу в конец каталога и возвращает её с выданным ID.
+func (s *ServiceRepository) CreateService(ctx context.Context, adminID int64, svc models.Service) (*models.Service, error) {
+	var created models.Service
+	err := s.inTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		start := time.Now()
+		err := tx.GetContext(ctx, &created, insertServiceQuery,
+			svc.Title, svc.Description, svc.Rules, svc.Schedule, svc.VisitOptions, svc.HasBooking, svc.IsActive, svc.CategoryID)
+		observeQuery(s.logger, "create", start, err)
+		if err != nil {
+			s.logger.Error("create_service_failed", zap.Error(err))
+			return fmt.Errorf("create service: %w", err)
+		}
+		return writeAudit(ctx, tx, s.logger, adminID, AuditActionCreate, auditEntityService, int64(created.ID), nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateService сохраняет карточку услуги: название, тексты, категорию, варианты посещения и кнопку бронирования.
+// Порядок и видимость меняются отдельными методами.
+func (s *ServiceRepository) UpdateService(ctx context.Context, adminID int64, svc models.Service) error {
+	return s.inTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		before, err := s.lockService(ctx, tx, svc.ID)
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, updateServiceQuery,
+			svc.ID, svc.Title, svc.Description, svc.Rules, svc.Schedule, svc.VisitOptions, svc.HasBooking, svc.CategoryID)
+		observeQuery(s.logger, "update", start, err)
+		if err != nil {
+			s.logger.Error("update_service_failed", zap.Error(err), zap.Int("service_id", svc.ID))
+			return fmt.Errorf("update service: %w", err)
+		}
+
+		after := svc
+		after.SortOrder, after.IsActive = before.SortOrder, before.IsActive
+		return writeAudit(ctx, tx, s.logger, adminID, AuditActionUpdate, auditEntityService, int64(svc.ID), before, after)
+	})
+}
+
+// SetServiceActive скрывает услугу из каталога или возвращает её.
+func (s *ServiceRepository) SetServiceActive(ctx context.Context, adminID int64, serviceID int, active bool) error {
+	return s.inTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		before, err := s.lockService(ctx, tx, serviceID)
+		if err != nil {
+			return err
+		}
+		if before.IsActive == active {
+			return nil
+		}
+
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, setServiceActiveQuery, serviceID, active)
+		observeQuery(s.logger, "update", start, err)
+		if err != nil {
+			s.logger.Error("set_service_active_failed", zap.Error(err), zap.Int("service_id", serviceID))
+			return fmt.Errorf("set service active: %w", err)
+		}
+
+		action := AuditActionHide
+		if active {
+			action = AuditActionShow
+		}
+		after := *before
+		after.IsActive = active
+		return writeAudit(ctx, tx, s.logger, adminID, action, auditEntityService, int64(serviceID), before, after)
+	})
+}
+
+// MoveService меняет услугу местами с соседней выше (up) или ниже в каталоге.
+// Для крайней услуги ничего не делает.
+func (s *ServiceRepository) MoveService(ctx context.Context, adminID int64, serviceID int, up bool) error {
+	return s.inTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		before, err := s.lockService(ctx, tx, serviceID)
+		if err != nil {
+			return err
+		}
+
+		query := nextServiceQuery
+		if up {
+			query = prevServiceQuery
+		}
+		var neighbour models.Service
+		start := time.Now()
+		err = tx.GetContext(ctx, &neighbour, query, before.SortOrder, before.ID)
+		observeQuery(s.logger, "read", start, err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("get neighbour service: %w", err)
+		}
+
+		own, other := neighbour.SortOrder, before.SortOrder
+		if own == other {
+			// одинаковый sort_order: порядок задавал id, сдвигаем только перемещаемую услугу
+			if up {
+				own--
+			} else {
+				own++
+			}
+		}
+		for _, move := range [][2]int{{before.ID, own}, {neighbour.ID, other}} {
+			id, order := move[0], move[1]
+			start = time.Now()
+			_, err = tx.ExecContext(ctx, setServiceSortOrderQuery, id, order)
+			observeQuery(s.logger, "update", start, err)
+			if err != nil {
+				s.logger.Error("move_service_failed", zap.Error(err), zap.Int("service_id", id))
+				return fmt.Errorf("move service: %w", err)
+			}
+		}
+
+		after := *before
+		after.SortOrder = own
+		return writeAudit(ctx, tx, s.logger, adminID, AuditActionMove, auditEntityService, int64(serviceID), before, after)
+	})
+}
+
+// DeleteService удаляет услугу вместе с её слотами и правилами уровней.
+// Услугу с бронированиями удалить нельзя — возвращается ErrServiceInUse.
+func (s *ServiceRepository) DeleteService(ctx context.Context, adminID int64, serviceID int) error {
+	return s.inTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		before, err := s.lockService(ctx, tx, serviceID)
+		if err != nil {
+			return err
+		}
+
+		var inUse bool
+		start := time.Now()
+		err = tx.GetContext(ctx, &inUse, serviceInUseQuery, serviceID)
+		observeQuery(s.logger, "read", start, err)
+		if err != nil {
+			return fmt.Errorf("check service usage: %w", err)
+		}
+		if inUse {
+			return ErrServiceInUse
+		}
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, deleteServiceQuery, serviceID)
+		observeQuery(s.logger, "delete", start, err)
+		if err != nil {
+			s.logger.Error("delete_service_failed", zap.Error(err), zap.Int("service_id", serviceID))
+			return fmt.Errorf("delete service: %w", err)
+		}
+		return writeAudit(ctx, tx, s.logger, adminID, AuditActionDelete, auditEntityService, int64(serviceID), before, nil)
+	})
+}
+
+func (s *ServiceRepository) lockService(ctx context.Context, tx *sqlx.Tx, serviceID int) (*models.Service, error) {
+	var svc models.Service
+	start := time.Now()
+	err := tx.GetContext(ctx, &svc, lockServiceQuery, serviceID)
+	observeQuery(s.logger, "read", start, err)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrServiceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock service %d: %w", serviceID, err)
+	}
+	return &svc, nil
+}
+
+// inTx выполняет fn в транзакции с таймаутом dbQueryTimeout.
+func (s *ServiceRepository) inTx(ctx context.Context, fn func(ctx context.Context, tx *sqlx.Tx) error) error {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTxx(ctxQ, nil)
+	if err != nil {
+		s.logger.Error("begin_tx_failed", zap.Error(err))
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctxQ, tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("commit_service_change_failed", zap.Error(err))
+		return fmt.Errorf("commit service change: %w", err)
+	}
+	return nil
 }
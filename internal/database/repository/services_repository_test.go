@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+var serviceColumnNames = []string{"id", "title", "description", "rules", "weekly_hours", "visit_options",
+	"has_booking", "sort_order", "is_active", "category_id"}
+
+func newServiceRepo(t *testing.T) (*ServiceRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	return NewServiceRepository(sqlx.NewDb(db, "postgres"), zap.NewNop()), mock, func() { _ = db.Close() }
+}
+
+func TestGetServiceByID_OK(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`FROM services s WHERE s.id = \$1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(serviceColumnNames).AddRow(
+			1, "Третьяковская галерея", "Описание", "Правила", []byte(`{"2":[{"open":"10:00","close":"18:00"}],"7":[{"open":"10:00","close":"18:00"}]}`),
+			[]byte(`[{"title":"Приватный тур","visit_type":"private"},{"title":"Групповой тур","visit_type":"public"}]`),
+			false, 10, true, 1,
+		))
+
+	service, err := repo.GetServiceByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetServiceByID err: %v", err)
+	}
+	want := models.VisitOptions{
+		{Title: "Приватный тур", VisitType: "private"},
+		{Title: "Групповой тур", VisitType: "public"},
+	}
+	if len(service.VisitOptions) != 2 || service.VisitOptions[0] != want[0] || service.VisitOptions[1] != want[1] {
+		t.Fatalf("unexpected visit options: %+v", service.VisitOptions)
+	}
+	if service.VisitOptions.VisitType(0) != "private" || service.VisitOptions.VisitType(5) != "" {
+		t.Fatalf("unexpected visit types: %+v", service.VisitOptions)
+	}
+	if !service.IsActive || service.SortOrder != 10 || service.CategoryID != 1 || service.Schedule.IsZero() {
+		t.Fatalf("unexpected service: %+v", service)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetServiceByID_NotFound(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`FROM services s WHERE s.id = \$1`).
+		WithArgs(999).
+		WillReturnRows(sqlmock.NewRows(serviceColumnNames))
+
+	if _, err := repo.GetServiceByID(context.Background(), 999); !errors.Is(err, ErrServiceNotFound) {
+		t.Fatalf("expected ErrServiceNotFound, got %v", err)
+	}
+}
+
+func TestGetServicesOfBoxSolutions_OnlyActiveInOrder(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`WHERE s.is_active AND NOT EXISTS .+ ORDER BY s.sort_order, s.id`).
+		WithArgs(int64(777)).
+		WillReturnRows(sqlmock.NewRows(serviceColumnNames).
+			AddRow(4, "Теннис в Лужниках", "", "", []byte(`{}`), []byte(`[]`), true, 40, true, 3).
+			AddRow(6, "Дайджест светских событий", "", "", []byte(`{}`), []byte(`[]`), false, 60, true, 4))
+
+	services, err := repo.GetServicesOfBoxSolutions(context.Background(), 777)
+	if err != nil {
+		t.Fatalf("GetServicesOfBoxSolutions err: %v", err)
+	}
+	if len(services) != 2 || services[0].ID != 4 || !services[0].HasBooking {
+		t.Fatalf("unexpected services: %+v", services)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestIsServiceVisible_WrapsError(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	dbErr := errors.New("connection reset")
+	mock.ExpectQuery(`SELECT`).
+		WithArgs(int64(777), 4).
+		WillReturnError(dbErr)
+
+	if _, err := repo.IsServiceVisible(context.Background(), 777, 4); !errors.Is(err, dbErr) {
+		t.Fatalf("expected wrapped db error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func serviceRow(id, sortOrder int, active bool) *sqlmock.Rows {
+	return sqlmock.NewRows(serviceColumnNames).
+		AddRow(id, "Услуга", "Описание", "Правила", []byte(`{}`), []byte(`[]`), false, sortOrder, active, 0)
+}
+
+func TestCreateService_WritesAudit(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO services`).
+		WithArgs("Новая услуга", "Описание", "Правила", "{}", "[]", true, true, 3).
+		WillReturnRows(serviceRow(7, 70, true))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).
+		WithArgs(int64(42), AuditActionCreate, "service", int64(7), nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	created, err := repo.CreateService(context.Background(), 42, models.Service{
+		Title: "Новая услуга", Description: "Описание", Rules: "Правила", HasBooking: true, IsActive: true, CategoryID: 3,
+	})
+	if err != nil {
+		t.Fatalf("CreateService err: %v", err)
+	}
+	if created.ID != 7 || created.SortOrder != 70 {
+		t.Fatalf("unexpected service: %+v", created)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateService_NotFound(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM services s WHERE s.id = \$1 FOR UPDATE`).
+		WithArgs(99).
+		WillReturnRows(sqlmock.NewRows(serviceColumnNames))
+	mock.ExpectRollback()
+
+	err := repo.UpdateService(context.Background(), 42, models.Service{ID: 99, Title: "Услуга"})
+	if !errors.Is(err, ErrServiceNotFound) {
+		t.Fatalf("expected ErrServiceNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+// auditContains проверяет, что JSON в журнале действий содержит подстроку.
+type auditContains string
+
+func (a auditContains) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	return ok && strings.Contains(s, string(a))
+}
+
+func TestUpdateService_WritesCategory(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM services s WHERE s.id = \$1 FOR UPDATE`).
+		WithArgs(7).
+		WillReturnRows(serviceRow(7, 70, true))
+	mock.ExpectExec(`UPDATE services`).
+		WithArgs(7, "Услуга", "Описание", "Правила", "{}", "[]", false, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).
+		WithArgs(int64(42), AuditActionUpdate, "service", int64(7), auditContains(`"category_id":0`), auditContains(`"category_id":2`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.UpdateService(context.Background(), 42, models.Service{
+		ID: 7, Title: "Услуга", Description: "Описание", Rules: "Правила", CategoryID: 2,
+	})
+	if err != nil {
+		t.Fatalf("UpdateService err: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMoveService_SwapsWithNeighbour(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM services s WHERE s.id = \$1 FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(serviceRow(3, 30, true))
+	mock.ExpectQuery(`WHERE \(s.sort_order, s.id\) < \(\$1, \$2\)`).
+		WithArgs(30, 3).
+		WillReturnRows(serviceRow(2, 20, true))
+	mock.ExpectExec(`UPDATE services SET sort_order`).WithArgs(3, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE services SET sort_order`).WithArgs(2, 30).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).
+		WithArgs(int64(42), AuditActionMove, "service", int64(3), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.MoveService(context.Background(), 42, 3, true); err != nil {
+		t.Fatalf("MoveService err: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMoveService_FirstStaysInPlace(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM services s WHERE s.id = \$1 FOR UPDATE`).
+		WithArgs(1).
+		WillReturnRows(serviceRow(1, 10, true))
+	mock.ExpectQuery(`WHERE \(s.sort_order, s.id\) < \(\$1, \$2\)`).
+		WithArgs(10, 1).
+		WillReturnRows(sqlmock.NewRows(serviceColumnNames))
+	mock.ExpectCommit()
+
+	if err := repo.MoveService(context.Background(), 42, 1, true); err != nil {
+		t.Fatalf("MoveService err: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeleteService_InUse(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM services s WHERE s.id = \$1 FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(serviceRow(3, 30, true))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM bookings`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	if err := repo.DeleteService(context.Background(), 42, 3); !errors.Is(err, ErrServiceInUse) {
+		t.Fatalf("expected ErrServiceInUse, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeleteService_WritesAudit(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM services s WHERE s.id = \$1 FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(serviceRow(3, 30, false))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM bookings`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(`DELETE FROM services WHERE id = \$1`).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).
+		WithArgs(int64(42), AuditActionDelete, "service", int64(3), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.DeleteService(context.Background(), 42, 3); err != nil {
+		t.Fatalf("DeleteService err: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetServiceSchedule_MergesExceptionsAndHolidays(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	june := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	mock.ExpectQuery(`SELECT weekly_hours, COALESCE\(timezone, ''\) AS timezone FROM services`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"weekly_hours", "timezone"}).
+			AddRow([]byte(`{"5":[{"open":"10:00","close":"18:00"}]}`), "Europe/Moscow"))
+	mock.ExpectQuery(`FROM service_schedule_exceptions`).
+		WithArgs(1, "2026-06-08", "2026-06-21").
+		WillReturnRows(sqlmock.NewRows([]string{"day", "open_time", "close_time", "note", "holiday"}).
+			AddRow(june(10), "10:00", "12:00", "Короткий день", false).
+			AddRow(june(10), "15:00", "18:00", "", false).
+			AddRow(june(12), nil, nil, "День России", true).
+			AddRow(june(19), nil, nil, "Санитарный день", false))
+
+	sched, err := repo.GetServiceSchedule(context.Background(), 1, june(8), june(21))
+	if err != nil {
+		t.Fatalf("GetServiceSchedule err: %v", err)
+	}
+	if sched.Location.String() != "Europe/Moscow" {
+		t.Fatalf("unexpected location: %v", sched.Location)
+	}
+
+	if d := sched.Day(june(10)); len(d.Hours) != 2 || d.Note != "Короткий день" {
+		t.Fatalf("expected two intervals on june 10, got %+v", d)
+	}
+	if d := sched.Day(june(12)); d.Open() || d.Note != "День России" {
+		t.Fatalf("expected holiday on june 12, got %+v", d)
+	}
+	if d := sched.Day(june(19)); d.Open() || !d.Special {
+		t.Fatalf("expected closure on june 19, got %+v", d)
+	}
+	if !sched.Day(june(26)).Open() {
+		t.Fatalf("regular friday must be open")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListServicePhotos_CachedAndLocal(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`FROM service_photos\s+WHERE service_id = \$1\s+ORDER BY sort_order, id`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_id", "local_path", "file_id"}).
+			AddRow(int64(7), 2, "tennis/court.jpg", "AgACAgIAAxkBAAI").
+			AddRow(int64(8), 2, "tennis/hall.jpg", ""))
+
+	photos, err := repo.ListServicePhotos(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("ListServicePhotos err: %v", err)
+	}
+	if len(photos) != 2 || photos[0].FileID != "AgACAgIAAxkBAAI" || photos[1].Path != "tennis/hall.jpg" || photos[1].FileID != "" {
+		t.Fatalf("unexpected photos: %+v", photos)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSetServicePhotoFileID(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectExec(`UPDATE service_photos SET file_id = \$2 WHERE id = \$1`).
+		WithArgs(int64(8), "AgACAgIAAxkBAAJ").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetServicePhotoFileID(context.Background(), 8, "AgACAgIAAxkBAAJ"); err != nil {
+		t.Fatalf("SetServicePhotoFileID err: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetBoxSolutionCategories_UncategorizedLast(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`LEFT JOIN service_categories c .+ GROUP BY c.id, c.title, c.sort_order\s+ORDER BY c.sort_order NULLS LAST, c.id`).
+		WithArgs(int64(777)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "services"}).
+			AddRow(1, "Музеи", 2).
+			AddRow(3, "Спорт", 1).
+			AddRow(0, "Другое", 1))
+
+	categories, err := repo.GetBoxSolutionCategories(context.Background(), 777)
+	if err != nil {
+		t.Fatalf("GetBoxSolutionCategories err: %v", err)
+	}
+	if len(categories) != 3 || categories[0].Title != "Музеи" || categories[0].Services != 2 || categories[2].ID != 0 {
+		t.Fatalf("unexpected categories: %+v", categories)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetServicesOfCategory(t *testing.T) {
+	repo, mock, cleanup := newServiceRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`WHERE s.is_active AND COALESCE\(s.category_id, 0\) = \$2 AND NOT EXISTS .+ ORDER BY s.sort_order, s.id`).
+		WithArgs(int64(777), 3).
+		WillReturnRows(sqlmock.NewRows(serviceColumnNames).
+			AddRow(4, "Теннис в Лужниках", "", "", []byte(`{}`), []byte(`[]`), true, 40, true, 3).
+			AddRow(5, "Падел корт в Сити", "", "", []byte(`{}`), []byte(`[]`), true, 50, true, 3))
+
+	services, err := repo.GetServicesOfCategory(context.Background(), 777, 3)
+	if err != nil {
+		t.Fatalf("GetServicesOfCategory err: %v", err)
+	}
+	if len(services) != 2 || services[1].ID != 5 {
+		t.Fatalf("unexpected services: %+v", services)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/yandex-development-2-team/Go/internal/metrics"
 	"go.uber.org/zap"
 
@@ -19,8 +20,7 @@ const (
 	saveSessionQuery = `
 INSERT INTO user_sessions (user_id, current_state, state_data)
 VALUES ($1, $2, $3::jsonb)
-ON CONFLICT (user_id) DO UPDATE SET
-	current_state = EXCLUDED.current_state,
+ON CONFLICT (user_id, current_state) DO UPDATE SET
 	state_data = EXCLUDED.state_data,
 	updated_at = CURRENT_TIMESTAMP
 `
@@ -28,8 +28,22 @@ ON CONFLICT (user_id) DO UPDATE SET
 SELECT id, user_id, current_state, state_data, created_at, updated_at
 FROM user_sessions
 WHERE user_id = $1
+ORDER BY updated_at DESC, id DESC
+LIMIT 1
+`
+	getStateSessionQuery = `
+SELECT id, user_id, current_state, state_data, created_at, updated_at
+FROM user_sessions
+WHERE user_id = $1 AND current_state = $2
+`
+	clearSessionQuery      = `DELETE FROM user_sessions WHERE user_id = $1`
+	clearStateSessionQuery = `DELETE FROM user_sessions WHERE user_id = $1 AND current_state = $2`
+
+	deleteStaleSessionsQuery = `
+DELETE FROM user_sessions
+WHERE updated_at < $1 AND current_state = ANY($2)
+RETURNING id, user_id, current_state, state_data, created_at, updated_at
 `
-	clearSessionQuery = `DELETE FROM user_sessions WHERE user_id = $1`
 
 	updateSessionStateQuery = `
 UPDATE user_sessions
@@ -94,7 +108,17 @@ func (r *SessionRepository) SaveSession(ctx context.Context, userID int64, state
 	return nil
 }
 
+// GetSession возвращает последнюю обновлённую сессию пользователя в любом диалоге или nil.
 func (r *SessionRepository) GetSession(ctx context.Context, userID int64) (*models.UserSession, error) {
+	return r.getSession(ctx, userID, getSessionQuery, userID)
+}
+
+// GetStateSession возвращает сессию пользователя в диалоге state или nil.
+func (r *SessionRepository) GetStateSession(ctx context.Context, userID int64, state string) (*models.UserSession, error) {
+	return r.getSession(ctx, userID, getStateSessionQuery, userID, state)
+}
+
+func (r *SessionRepository) getSession(ctx context.Context, userID int64, query string, args ...any) (*models.UserSession, error) {
 	if r.db == nil {
 		return nil, fmt.Errorf("db is nil")
 	}
@@ -114,7 +138,7 @@ func (r *SessionRepository) GetSession(ctx context.Context, userID int64) (*mode
 	defer cancel()
 
 	start := time.Now()
-	err := r.db.QueryRowxContext(ctxQ, getSessionQuery, userID).Scan(
+	err := r.db.QueryRowxContext(ctxQ, query, args...).Scan(
 		&s.ID,
 		&s.UserID,
 		&s.CurrentState,
@@ -158,7 +182,17 @@ func (r *SessionRepository) GetSession(ctx context.Context, userID int64) (*mode
 	return &s, nil
 }
 
+// ClearSession удаляет все сессии пользователя.
 func (r *SessionRepository) ClearSession(ctx context.Context, userID int64) error {
+	return r.clearSession(ctx, userID, clearSessionQuery, userID)
+}
+
+// ClearStateSession удаляет сессию пользователя в диалоге state, не трогая остальные диалоги.
+func (r *SessionRepository) ClearStateSession(ctx context.Context, userID int64, state string) error {
+	return r.clearSession(ctx, userID, clearStateSessionQuery, userID, state)
+}
+
+func (r *SessionRepository) clearSession(ctx context.Context, userID int64, query string, args ...any) error {
 	if r.db == nil {
 		return fmt.Errorf("db is nil")
 	}
@@ -171,7 +205,7 @@ func (r *SessionRepository) ClearSession(ctx context.Context, userID int64) erro
 	defer cancel()
 
 	start := time.Now()
-	_, err := r.db.ExecContext(ctxQ, clearSessionQuery, userID)
+	_, err := r.db.ExecContext(ctxQ, query, args...)
 	dur := time.Since(start).Seconds()
 
 	metrics.Default.DatabaseQueriesTotal.WithLabelValues(op).Inc()
@@ -221,3 +255,64 @@ func (r *SessionRepository) UpdateSessionState(ctx context.Context, userID int64
 	}
 	return nil
 }
+
+// DeleteStaleSessions удаляет сессии диалогов conversations, которые не обновлялись
+// с момента before, и возвращает их. Сессии других диалогов остаются.
+func (r *SessionRepository) DeleteStaleSessions(ctx context.Context, before time.Time, conversations []string) ([]models.UserSession, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+
+	op := "delete"
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := r.db.QueryxContext(ctxQ, deleteStaleSessionsQuery, before, pq.Array(conversations))
+	var sessions []models.UserSession
+	if err == nil {
+		sessions, err = scanSessions(rows)
+	}
+	dur := time.Since(start).Seconds()
+
+	metrics.Default.DatabaseQueriesTotal.WithLabelValues(op).Inc()
+	metrics.Default.DatabaseQueryDuration.WithLabelValues(op).Observe(dur)
+
+	if dur > slowQueryThreshold.Seconds() {
+		r.logger.Warn("slow_db_query",
+			zap.String("operation", op),
+			zap.Float64("duration_seconds", dur),
+		)
+	}
+	if err != nil {
+		metrics.Default.DatabaseErrorsTotal.WithLabelValues(op).Inc()
+		return nil, fmt.Errorf("delete stale sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func scanSessions(rows *sqlx.Rows) ([]models.UserSession, error) {
+	defer rows.Close()
+
+	var sessions []models.UserSession
+	for rows.Next() {
+		var (
+			s            models.UserSession
+			currentState sql.NullString
+			stateJS      []byte
+		)
+		if err := rows.Scan(&s.ID, &s.UserID, &currentState, &stateJS, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		s.CurrentState = currentState.String
+
+		s.StateData = map[string]interface{}{}
+		if len(stateJS) > 0 {
+			if err := json.Unmarshal(stateJS, &s.StateData); err != nil {
+				return nil, fmt.Errorf("unmarshal state_data: %w", err)
+			}
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/state"
 )
 
 func newRepo(t *testing.T) (*SessionRepository, sqlmock.Sqlmock, func()) {
@@ -30,7 +33,7 @@ func TestSaveSession_UpsertJSONB(t *testing.T) {
 	repo, mock, cleanup := newRepo(t)
 	defer cleanup()
 
-	mock.ExpectExec(`INSERT INTO user_sessions`).
+	mock.ExpectExec(`INSERT INTO user_sessions(.|\n)*ON CONFLICT \(user_id, current_state\)`).
 		WithArgs(int64(10), "booking_form", sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -134,6 +137,90 @@ func TestClearSession_OK(t *testing.T) {
 	}
 }
 
+func TestClearStateSession_KeepsOtherConversations(t *testing.T) {
+	repo, mock, cleanup := newRepo(t)
+	defer cleanup()
+
+	mock.ExpectExec(`DELETE FROM user_sessions WHERE user_id = \$1 AND current_state = \$2`).
+		WithArgs(int64(10), "booking_form").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.ClearStateSession(context.Background(), 10, "booking_form"); err != nil {
+		t.Fatalf("ClearStateSession err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+// registeredUsers сопоставляет Telegram ID с users.id для SessionStore.
+type registeredUsers map[int64]int64
+
+func (u registeredUsers) GetUserByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	return &models.User{ID: u[telegramID], TelegramID: telegramID}, nil
+}
+
+func TestSessionStore_TwoConversationsInPostgres(t *testing.T) {
+	repo, mock, cleanup := newRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	users := registeredUsers{777: 10}
+	booking := state.NewSessionStore[models.BookingState]("booking_form", repo, users)
+	admin := state.NewSessionStore[models.AdminServiceState]("admin_services", repo, users)
+
+	sessionColumns := []string{"id", "user_id", "current_state", "state_data", "created_at", "updated_at"}
+	now := time.Now()
+	expectLoad := func(conversation string, data string) {
+		mock.ExpectQuery(`FROM user_sessions\s+WHERE user_id = \$1 AND current_state = \$2`).
+			WithArgs(int64(10), conversation).
+			WillReturnRows(sqlmock.NewRows(sessionColumns).AddRow(int64(1), int64(10), conversation, []byte(data), now, now))
+	}
+
+	// каждый диалог сохраняется под своим ключом (user_id, current_state)
+	mock.ExpectExec(`INSERT INTO user_sessions`).
+		WithArgs(int64(10), "booking_form", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO user_sessions`).
+		WithArgs(int64(10), "admin_services", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLoad("booking_form", `{"service_id":3}`)
+	expectLoad("admin_services", `{"draft":{"title":"Экскурсия"}}`)
+	// удаление формы одним запросом не трогает черновик администратора
+	mock.ExpectExec(`DELETE FROM user_sessions WHERE user_id = \$1 AND current_state = \$2`).
+		WithArgs(int64(10), "booking_form").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLoad("admin_services", `{"draft":{"title":"Экскурсия"}}`)
+
+	if err := booking.Save(ctx, 777, models.BookingState{ServiceID: 3}); err != nil {
+		t.Fatalf("save booking form: %v", err)
+	}
+	if err := admin.Save(ctx, 777, models.AdminServiceState{Draft: models.Service{Title: "Экскурсия"}}); err != nil {
+		t.Fatalf("save admin draft: %v", err)
+	}
+
+	form, ok, err := booking.Load(ctx, 777)
+	if err != nil || !ok || form.ServiceID != 3 {
+		t.Fatalf("booking form lost: %+v ok=%v err=%v", form, ok, err)
+	}
+	draft, ok, err := admin.Load(ctx, 777)
+	if err != nil || !ok || draft.Draft.Title != "Экскурсия" {
+		t.Fatalf("admin draft lost: %+v ok=%v err=%v", draft, ok, err)
+	}
+
+	if err := booking.Delete(ctx, 777); err != nil {
+		t.Fatalf("delete booking form: %v", err)
+	}
+	if draft, ok, err = admin.Load(ctx, 777); err != nil || !ok || draft.Draft.Title != "Экскурсия" {
+		t.Fatalf("admin draft must survive form deletion: %+v ok=%v err=%v", draft, ok, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
 func TestUpdateSessionState_OK(t *testing.T) {
 	repo, mock, cleanup := newRepo(t)
 	defer cleanup()
@@ -150,3 +237,40 @@ func TestUpdateSessionState_OK(t *testing.T) {
 		t.Fatalf("expectations: %v", err)
 	}
 }
+
+func TestDeleteStaleSessions_OK(t *testing.T) {
+	repo, mock, cleanup := newRepo(t)
+	defer cleanup()
+
+	before := time.Now().Add(-2 * time.Hour)
+	old := before.Add(-time.Hour)
+
+	rows := sqlmock.NewRows([]string{
+		"id", "user_id", "current_state", "state_data", "created_at", "updated_at",
+	}).
+		AddRow(int64(1), int64(10), "booking_form", []byte(`{"step":3}`), old, old).
+		AddRow(int64(2), int64(11), nil, nil, old, old)
+
+	// удаляются только диалоги, за которыми следит уборщик
+	mock.ExpectQuery(`DELETE FROM user_sessions\s+WHERE updated_at < \$1 AND current_state = ANY\(\$2\)`).
+		WithArgs(before, `{"booking_form","admin_services"}`).
+		WillReturnRows(rows)
+
+	sessions, err := repo.DeleteStaleSessions(context.Background(), before, []string{"booking_form", "admin_services"})
+	if err != nil {
+		t.Fatalf("DeleteStaleSessions err: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].CurrentState != "booking_form" || sessions[0].StateData["step"] != float64(3) {
+		t.Fatalf("first session mismatch: %#v", sessions[0])
+	}
+	if sessions[1].CurrentState != "" || len(sessions[1].StateData) != 0 {
+		t.Fatalf("second session mismatch: %#v", sessions[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+const (
+	enqueueTrackerTicketQuery = `
+INSERT INTO tracker_outbox (booking_id) VALUES ($1)
+ON CONFLICT (booking_id) DO NOTHING
+`
+	// claimTrackerOutboxQuery забирает готовые к отправке записи и откладывает их на время lease,
+	// чтобы другие реплики не взяли те же записи, пока идёт запрос в Трекер.
+	claimTrackerOutboxQuery = `
+UPDATE tracker_outbox
+SET attempts = attempts + 1,
+	next_attempt_at = NOW() + make_interval(secs => $2)
+WHERE id IN (
+	SELECT id FROM tracker_outbox
+	WHERE processed_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+	ORDER BY next_attempt_at, id
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, booking_id, attempts
+`
+	setTrackerTicketQuery = `
+UPDATE bookings
+SET tracker_ticket_id = $2,
+	updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+	completeTrackerOutboxQuery = `UPDATE tracker_outbox SET processed_at = NOW(), last_error = NULL WHERE id = $1`
+	retryTrackerOutboxQuery    = `UPDATE tracker_outbox SET next_attempt_at = $2, last_error = $3 WHERE id = $1`
+	failTrackerOutboxQuery     = `UPDATE tracker_outbox SET failed_at = NOW(), last_error = $2 WHERE id = $1`
+)
+
+type TrackerOutboxRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+func NewTrackerOutboxRepository(db *sqlx.DB, logger *zap.Logger) *TrackerOutboxRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &TrackerOutboxRepository{db: db, logger: logger}
+}
+
+// Claim возвращает до limit записей, готовых к отправке, и блокирует их на время lease.
+func (r *TrackerOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.TrackerOutboxEntry, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var entries []models.TrackerOutboxEntry
+	start := time.Now()
+	err := r.db.SelectContext(ctxQ, &entries, claimTrackerOutboxQuery, limit, lease.Seconds())
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("claim_tracker_outbox_failed", zap.Error(err))
+		return nil, fmt.Errorf("claim tracker outbox: %w", err)
+	}
+	return entries, nil
+}
+
+// Complete сохраняет ключ тикета в бронировании и помечает запись обработанной.
+func (r *TrackerOutboxRepository) Complete(ctx context.Context, entry models.TrackerOutboxEntry, ticketKey string) error {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctxQ, nil)
+	if err != nil {
+		r.logger.Error("begin_tx_failed", zap.Error(err))
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	start := time.Now()
+	_, err = tx.ExecContext(ctxQ, setTrackerTicketQuery, entry.BookingID, ticketKey)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("set_tracker_ticket_failed", zap.Error(err), zap.Int64("booking_id", entry.BookingID))
+		return fmt.Errorf("set tracker ticket: %w", err)
+	}
+
+	start = time.Now()
+	_, err = tx.ExecContext(ctxQ, completeTrackerOutboxQuery, entry.ID)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("complete_tracker_outbox_failed", zap.Error(err), zap.Int64("outbox_id", entry.ID))
+		return fmt.Errorf("complete tracker outbox: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("commit_tracker_outbox_failed", zap.Error(err))
+		return fmt.Errorf("commit tracker outbox: %w", err)
+	}
+	return nil
+}
+
+// Retry откладывает следующую попытку до retryAt.
+func (r *TrackerOutboxRepository) Retry(ctx context.Context, entry models.TrackerOutboxEntry, retryAt time.Time, lastErr string) error {
+	return r.exec(ctx, "retry_tracker_outbox_failed", retryTrackerOutboxQuery, entry.ID, retryAt, lastErr)
+}
+
+// Fail прекращает попытки для записи.
+func (r *TrackerOutboxRepository) Fail(ctx context.Context, entry models.TrackerOutboxEntry, lastErr string) error {
+	return r.exec(ctx, "fail_tracker_outbox_failed", failTrackerOutboxQuery, entry.ID, lastErr)
+}
+
+func (r *TrackerOutboxRepository) exec(ctx context.Context, event, query string, args ...interface{}) error {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := r.db.ExecContext(ctxQ, query, args...)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error(event, zap.Error(err))
+		return fmt.Errorf("update tracker outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+func newTrackerOutboxRepo(t *testing.T) (*TrackerOutboxRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+
+	return NewTrackerOutboxRepository(sqlx.NewDb(db, "postgres"), zap.NewNop()), mock, func() { _ = db.Close() }
+}
+
+func TestTrackerOutbox_Claim(t *testing.T) {
+	repo, mock, cleanup := newTrackerOutboxRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`UPDATE tracker_outbox(.|\n)*FOR UPDATE SKIP LOCKED`).
+		WithArgs(10, float64(60)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "attempts"}).
+			AddRow(int64(1), int64(42), 1).
+			AddRow(int64(2), int64(43), 3))
+
+	entries, err := repo.Claim(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[1].BookingID != 43 || entries[1].Attempts != 3 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTrackerOutbox_Complete(t *testing.T) {
+	repo, mock, cleanup := newTrackerOutboxRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE bookings(.|\n)*SET tracker_ticket_id = \$2`).
+		WithArgs(int64(42), "BOOK-7").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE tracker_outbox SET processed_at`).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.Complete(context.Background(), models.TrackerOutboxEntry{ID: 1, BookingID: 42}, "BOOK-7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTrackerOutbox_Complete_RollbackOnError(t *testing.T) {
+	repo, mock, cleanup := newTrackerOutboxRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE bookings`).
+		WithArgs(int64(42), "BOOK-7").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE tracker_outbox SET processed_at`).
+		WithArgs(int64(1)).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	err := repo.Complete(context.Background(), models.TrackerOutboxEntry{ID: 1, BookingID: 42}, "BOOK-7")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTrackerOutbox_RetryAndFail(t *testing.T) {
+	repo, mock, cleanup := newTrackerOutboxRepo(t)
+	defer cleanup()
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE tracker_outbox SET next_attempt_at`).
+		WithArgs(int64(1), at, "503").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE tracker_outbox SET failed_at`).
+		WithArgs(int64(1), "403").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	entry := models.TrackerOutboxEntry{ID: 1, BookingID: 42}
+	if err := repo.Retry(context.Background(), entry, at, "503"); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if err := repo.Fail(context.Background(), entry, "403"); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
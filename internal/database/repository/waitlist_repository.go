@@ -0,0 +1,499 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// WaitlistOfferTTL — сколько освободившееся место удерживается за пользователем из листа ожидания.
+const WaitlistOfferTTL = 30 * time.Minute
+
+// Задания планировщика, которые создаются вместе с предложением места.
+const (
+	JobWaitlistOffer  = "waitlist_offer"  // отправить предложение пользователю
+	JobWaitlistExpire = "waitlist_expire" // закрыть неотвеченное предложение
+)
+
+const (
+	joinWaitlistQuery = `
+INSERT INTO waitlist_entries (user_id, service_id, slot_date, slot_time, guest_name, guest_organization, guest_position, visit_type, guests)
+SELECT id, $2, $3, $4, $5, $6, $7, $8, $9 FROM users WHERE telegram_id = $1
+ON CONFLICT DO NOTHING
+RETURNING id
+`
+	userExistsQuery = `SELECT EXISTS (SELECT 1 FROM users WHERE telegram_id = $1)`
+
+	getWaitlistEntryQuery = `
+SELECT w.id, u.telegram_id AS user_telegram_id, w.service_id, COALESCE(s.title, '') AS service_title,
+	w.slot_date, to_char(w.slot_time, 'HH24:MI') AS slot_time,
+	w.guest_name, w.guest_organization, w.guest_position, w.visit_type,
+	w.status, w.slot_id, w.offer_expires_at, w.booking_id, w.created_at
+FROM waitlist_entries w
+JOIN users u ON u.id = w.user_id
+LEFT JOIN services s ON s.id = w.service_id
+WHERE w.id = $1
+`
+	lockWaitlistEntryQuery = `
+SELECT w.status, w.slot_id, w.service_id, w.slot_date, to_char(w.slot_time, 'HH24:MI') AS slot_time,
+	w.guest_name, w.guest_organization, w.guest_position, w.visit_type, w.guests,
+	COALESCE(w.offer_expires_at > NOW(), FALSE) AS offer_active
+FROM waitlist_entries w
+JOIN users u ON u.id = w.user_id
+WHERE w.id = $1 AND ($2::bigint = 0 OR u.telegram_id = $2)
+FOR UPDATE OF w
+`
+	setWaitlistStatusQuery = `
+UPDATE waitlist_entries
+SET status = $2,
+	booking_id = $3,
+	updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+	// offerSlotQuery предлагает свободные места слота первому в очереди, чья группа в них
+	// помещается; группа без списка гостей — один человек. SKIP LOCKED не даёт двум
+	// параллельным отменам предложить место одному и тому же пользователю. $3 — записи,
+	// которые в этой транзакции уже пропущены.
+	offerSlotQuery = `
+UPDATE waitlist_entries
+SET status = 'offered',
+	slot_id = $1,
+	offered_at = NOW(),
+	offer_expires_at = NOW() + make_interval(secs => $2),
+	updated_at = CURRENT_TIMESTAMP
+WHERE id = (
+	SELECT w.id
+	FROM waitlist_entries w
+	JOIN service_slots s ON s.id = $1
+	WHERE w.status = 'waiting'
+		AND w.service_id = s.service_id
+		AND w.slot_date = s.slot_date
+		AND w.slot_time IS NOT DISTINCT FROM s.slot_time
+		AND s.slot_date >= CURRENT_DATE
+		AND GREATEST(jsonb_array_length(w.guests), 1) <= s.capacity - s.booked
+		AND w.id <> ALL($3::bigint[])
+	ORDER BY w.created_at, w.id
+	LIMIT 1
+	FOR UPDATE OF w SKIP LOCKED
+)
+RETURNING id, offer_expires_at, GREATEST(jsonb_array_length(guests), 1) AS guests
+`
+	// holdSlotQuery занимает в слоте места под предложение из листа ожидания.
+	holdSlotQuery = `
+UPDATE service_slots
+SET booked = booked + $2,
+	updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND booked + $2 <= capacity
+`
+	// withdrawOfferQuery возвращает запись в очередь, если места под предложение занять не удалось.
+	withdrawOfferQuery = `
+UPDATE waitlist_entries
+SET status = 'waiting',
+	slot_id = NULL,
+	offered_at = NULL,
+	offer_expires_at = NULL,
+	updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+)
+
+var (
+	// ErrAlreadyInWaitlist возвращается, если пользователь уже ждёт место на этот слот.
+	ErrAlreadyInWaitlist = errors.New("user is already in the waitlist")
+	// ErrWaitlistEntryNotFound возвращается, если записи нет или она принадлежит другому пользователю.
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	// ErrOfferExpired возвращается при попытке принять предложение, которое уже не действует.
+	ErrOfferExpired = errors.New("waitlist offer is no longer active")
+)
+
+// waitlistJob — данные заданий JobWaitlistOffer и JobWaitlistExpire.
+type waitlistJob struct {
+	EntryID int64 `json:"entry_id"`
+}
+
+// WaitlistRepository хранит лист ожидания. Бронирования по принятым предложениям
+// создаются через BookingRepository в той же транзакции.
+type WaitlistRepository struct {
+	bookings *BookingRepository
+	db       *sqlx.DB
+	logger   *zap.Logger
+}
+
+func NewWaitlistRepository(bookings *BookingRepository) *WaitlistRepository {
+	return &WaitlistRepository{bookings: bookings, db: bookings.db, logger: bookings.logger}
+}
+
+// JoinWaitlist ставит пользователя в очередь на слот из заполненной формы бронирования.
+func (r *WaitlistRepository) JoinWaitlist(ctx context.Context, state *models.BookingState) (int64, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	guests := state.AllGuests()
+	guestsJSON, err := json.Marshal(guests)
+	if err != nil {
+		return 0, fmt.Errorf("marshal waitlist guests: %w", err)
+	}
+
+	var entryID int64
+	start := time.Now()
+	err = r.db.GetContext(ctxQ, &entryID, joinWaitlistQuery,
+		state.UserID,
+		state.ServiceID,
+		state.SelectedDate.Format("2006-01-02"),
+		nullIfEmpty(state.SelectedTime),
+		guests[0].Name,
+		nullIfEmpty(guests[0].Organization),
+		nullIfEmpty(guests[0].Position),
+		nullIfEmpty(state.VisitType),
+		guestsJSON,
+	)
+	observeQuery(r.logger, "create", start, err)
+	if errors.Is(err, sql.ErrNoRows) {
+		// строку не вставили: либо пользователь уже в очереди, либо он не зарегистрирован
+		var exists bool
+		start = time.Now()
+		err = r.db.GetContext(ctxQ, &exists, userExistsQuery, state.UserID)
+		observeQuery(r.logger, "read", start, err)
+		if err == nil && exists {
+			return 0, ErrAlreadyInWaitlist
+		}
+		if err == nil {
+			return 0, ErrUserNotRegistered
+		}
+	}
+	if err != nil {
+		r.logger.Error("join_waitlist_failed", zap.Error(err), zap.Int64("telegram_id", state.UserID))
+		return 0, fmt.Errorf("join waitlist: %w", err)
+	}
+
+	r.logger.Info("waitlist_joined",
+		zap.Int64("entry_id", entryID),
+		zap.Int64("telegram_id", state.UserID),
+		zap.Int("service_id", state.ServiceID),
+	)
+	return entryID, nil
+}
+
+// GetFullDates возвращает заполненные даты услуги, на которые можно встать в очередь.
+func (r *WaitlistRepository) GetFullDates(ctx context.Context, serviceID int) ([]time.Time, error) {
+	return r.bookings.GetFullDates(ctx, serviceID)
+}
+
+// GetTakenSlots возвращает занятые слоты услуги на дату.
+func (r *WaitlistRepository) GetTakenSlots(ctx context.Context, serviceID int, date time.Time) ([]string, error) {
+	return r.bookings.GetTakenSlots(ctx, serviceID, date)
+}
+
+// GetEntry возвращает запись листа ожидания.
+func (r *WaitlistRepository) GetEntry(ctx context.Context, entryID int64) (*models.WaitlistEntry, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var e models.WaitlistEntry
+	start := time.Now()
+	err := r.db.GetContext(ctxQ, &e, getWaitlistEntryQuery, entryID)
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWaitlistEntryNotFound
+		}
+		r.logger.Error("get_waitlist_entry_failed", zap.Error(err), zap.Int64("entry_id", entryID))
+		return nil, fmt.Errorf("get waitlist entry: %w", err)
+	}
+	return &e, nil
+}
+
+// lockedWaitlistEntry — поля записи, заблокированной для изменения.
+type lockedWaitlistEntry struct {
+	Status            models.WaitlistStatus `db:"status"`
+	SlotID            sql.NullInt64         `db:"slot_id"`
+	ServiceID         int                   `db:"service_id"`
+	SlotDate          time.Time             `db:"slot_date"`
+	SlotTime          sql.NullString        `db:"slot_time"`
+	GuestName         string                `db:"guest_name"`
+	GuestOrganization sql.NullString        `db:"guest_organization"`
+	GuestPosition     sql.NullString        `db:"guest_position"`
+	VisitType         sql.NullString        `db:"visit_type"`
+	Guests            []byte                `db:"guests"` // JSON, []models.BookingGuest
+	OfferActive       bool                  `db:"offer_active"`
+}
+
+// bookingGuests возвращает гостей заявки; для заявок без списка — контактное лицо.
+func (e lockedWaitlistEntry) bookingGuests() ([]models.BookingGuest, error) {
+	var guests []models.BookingGuest
+	if len(e.Guests) > 0 {
+		if err := json.Unmarshal(e.Guests, &guests); err != nil {
+			return nil, fmt.Errorf("decode waitlist guests: %w", err)
+		}
+	}
+	if len(guests) == 0 {
+		guests = []models.BookingGuest{{
+			Name:         e.GuestName,
+			Organization: e.GuestOrganization.String,
+			Position:     e.GuestPosition.String,
+		}}
+	}
+	return guests, nil
+}
+
+// ClaimOffer принимает предложение: на удерживаемое место создаётся бронирование
+// в статусе pending. Правила уровня пользователя проверяются так же, как в SaveBooking.
+// Возвращает ID бронирования.
+func (r *WaitlistRepository) ClaimOffer(ctx context.Context, telegramID, entryID int64) (int64, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctxQ, nil)
+	if err != nil {
+		r.logger.Error("begin_tx_failed", zap.Error(err))
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	e, err := r.lockEntry(ctxQ, tx, entryID, telegramID)
+	if err != nil {
+		return 0, err
+	}
+	if e.Status != models.WaitlistStatusOffered || !e.OfferActive || !e.SlotID.Valid {
+		return 0, ErrOfferExpired
+	}
+
+	date := e.SlotDate.Format("2006-01-02")
+	if err := checkGradeRules(ctxQ, tx, r.logger, telegramID, e.ServiceID, e.VisitType.String, date, 0); err != nil {
+		return 0, err
+	}
+
+	// места под всех гостей уже заняты в offerSlot
+	guests, err := e.bookingGuests()
+	if err != nil {
+		return 0, err
+	}
+
+	var bookingID int64
+	start := time.Now()
+	err = tx.GetContext(ctxQ, &bookingID, insertBookingQuery,
+		telegramID,
+		e.ServiceID,
+		e.SlotID.Int64,
+		date,
+		e.SlotTime,
+		e.GuestName,
+		e.GuestOrganization,
+		e.GuestPosition,
+		e.VisitType,
+		len(guests),
+	)
+	observeQuery(r.logger, "create", start, err)
+	if err != nil {
+		r.logger.Error("save_waitlist_booking_failed", zap.Error(err), zap.Int64("entry_id", entryID))
+		return 0, fmt.Errorf("save booking: %w", err)
+	}
+
+	if err := insertBookingGuests(ctxQ, tx, r.logger, bookingID, guests); err != nil {
+		return 0, err
+	}
+	if err := r.bookings.recordStatus(ctxQ, tx, bookingID, "", models.BookingStatusPending, telegramID); err != nil {
+		return 0, err
+	}
+	if err := r.setStatus(ctxQ, tx, entryID, models.WaitlistStatusClaimed, sql.NullInt64{Int64: bookingID, Valid: true}); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("commit_waitlist_claim_failed", zap.Error(err))
+		return 0, fmt.Errorf("commit waitlist claim: %w", err)
+	}
+
+	r.logger.Info("waitlist_offer_claimed", zap.Int64("entry_id", entryID), zap.Int64("booking_id", bookingID))
+	return bookingID, nil
+}
+
+// DeclineOffer фиксирует отказ пользователя; место предлагается следующему в очереди.
+func (r *WaitlistRepository) DeclineOffer(ctx context.Context, telegramID, entryID int64) error {
+	_, err := r.closeOffer(ctx, entryID, telegramID, models.WaitlistStatusDeclined, false)
+	return err
+}
+
+// ExpireOffer закрывает предложение, если его срок истёк, и передаёт место дальше.
+// Возвращает false, если предложение уже принято, отклонено или ещё действует.
+func (r *WaitlistRepository) ExpireOffer(ctx context.Context, entryID int64) (bool, error) {
+	return r.closeOffer(ctx, entryID, 0, models.WaitlistStatusExpired, true)
+}
+
+func (r *WaitlistRepository) closeOffer(ctx context.Context, entryID, ownerID int64, next models.WaitlistStatus, onlyExpired bool) (bool, error) {
+	ctxQ, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctxQ, nil)
+	if err != nil {
+		r.logger.Error("begin_tx_failed", zap.Error(err))
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	e, err := r.lockEntry(ctxQ, tx, entryID, ownerID)
+	if err != nil {
+		return false, err
+	}
+	if e.Status != models.WaitlistStatusOffered {
+		if onlyExpired {
+			return false, nil
+		}
+		return false, ErrOfferExpired
+	}
+	if onlyExpired && e.OfferActive {
+		return false, nil
+	}
+
+	guests, err := e.bookingGuests()
+	if err != nil {
+		return false, err
+	}
+	if err := r.setStatus(ctxQ, tx, entryID, next, sql.NullInt64{}); err != nil {
+		return false, err
+	}
+	if err := r.bookings.releaseSlot(ctxQ, tx, e.SlotID, len(guests)); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("commit_waitlist_offer_failed", zap.Error(err))
+		return false, fmt.Errorf("commit waitlist offer: %w", err)
+	}
+
+	r.logger.Info("waitlist_offer_closed", zap.Int64("entry_id", entryID), zap.String("status", string(next)))
+	return true, nil
+}
+
+func (r *WaitlistRepository) lockEntry(ctx context.Context, tx *sqlx.Tx, entryID, telegramID int64) (*lockedWaitlistEntry, error) {
+	var e lockedWaitlistEntry
+	start := time.Now()
+	err := tx.GetContext(ctx, &e, lockWaitlistEntryQuery, entryID, telegramID)
+	observeQuery(r.logger, "read", start, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWaitlistEntryNotFound
+		}
+		r.logger.Error("lock_waitlist_entry_failed", zap.Error(err), zap.Int64("entry_id", entryID))
+		return nil, fmt.Errorf("lock waitlist entry: %w", err)
+	}
+	return &e, nil
+}
+
+func (r *WaitlistRepository) setStatus(ctx context.Context, tx *sqlx.Tx, entryID int64, status models.WaitlistStatus, bookingID sql.NullInt64) error {
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, setWaitlistStatusQuery, entryID, status, bookingID)
+	observeQuery(r.logger, "update", start, err)
+	if err != nil {
+		r.logger.Error("update_waitlist_entry_failed", zap.Error(err), zap.Int64("entry_id", entryID))
+		return fmt.Errorf("update waitlist entry: %w", err)
+	}
+	return nil
+}
+
+// offerSlot предлагает свободные места слота первому в листе ожидания, чья группа в них
+// помещается. Места под всю группу остаются занятыми за ним на WaitlistOfferTTL;
+// уведомление и истечение срока планируются заданиями в той же транзакции.
+// Если места под группу занять не удалось, запись возвращается в очередь и место
+// предлагается следующему. Возвращает false, если предложить некому.
+func offerSlot(ctx context.Context, tx *sqlx.Tx, logger *zap.Logger, slotID int64) (bool, error) {
+	var offer struct {
+		ID        int64     `db:"id"`
+		ExpiresAt time.Time `db:"offer_expires_at"`
+		Guests    int       `db:"guests"`
+	}
+	skipped := []int64{}
+	for {
+		start := time.Now()
+		err := tx.GetContext(ctx, &offer, offerSlotQuery, slotID, WaitlistOfferTTL.Seconds(), pq.Array(skipped))
+		observeQuery(logger, "update", start, err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			logger.Error("offer_waitlist_slot_failed", zap.Error(err), zap.Int64("slot_id", slotID))
+			return false, fmt.Errorf("offer waitlist slot: %w", err)
+		}
+
+		held, err := holdSlot(ctx, tx, logger, slotID, offer.ID, offer.Guests)
+		if err != nil {
+			return false, err
+		}
+		if held {
+			break
+		}
+		skipped = append(skipped, offer.ID)
+	}
+
+	payload, err := json.Marshal(waitlistJob{EntryID: offer.ID})
+	if err != nil {
+		return false, fmt.Errorf("marshal waitlist job: %w", err)
+	}
+
+	jobs := []struct {
+		kind  string
+		runAt time.Time
+	}{
+		{JobWaitlistOffer, time.Now()},
+		{JobWaitlistExpire, offer.ExpiresAt},
+	}
+	for _, job := range jobs {
+		start := time.Now()
+		_, err := tx.ExecContext(ctx, scheduleJobQuery, job.kind, fmt.Sprintf("%s:%d", job.kind, offer.ID), payload, job.runAt)
+		observeQuery(logger, "create", start, err)
+		if err != nil {
+			logger.Error("schedule_waitlist_job_failed", zap.Error(err), zap.Int64("entry_id", offer.ID))
+			return false, fmt.Errorf("schedule waitlist job: %w", err)
+		}
+	}
+
+	logger.Info("waitlist_slot_offered", zap.Int64("entry_id", offer.ID), zap.Int64("slot_id", slotID))
+	return true, nil
+}
+
+// holdSlot занимает в слоте guests мест под предложение entryID. Если мест уже не хватает,
+// предложение отзывается, запись возвращается в очередь и возвращается false.
+func holdSlot(ctx context.Context, tx *sqlx.Tx, logger *zap.Logger, slotID, entryID int64, guests int) (bool, error) {
+	start := time.Now()
+	res, err := tx.ExecContext(ctx, holdSlotQuery, slotID, guests)
+	observeQuery(logger, "update", start, err)
+	if err != nil {
+		logger.Error("hold_waitlist_slot_failed", zap.Error(err), zap.Int64("slot_id", slotID))
+		return false, fmt.Errorf("hold waitlist slot: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("hold waitlist slot: %w", err)
+	}
+	if n > 0 {
+		return true, nil
+	}
+
+	logger.Info("waitlist_offer_withdrawn", zap.Int64("entry_id", entryID), zap.Int64("slot_id", slotID), zap.Int("guests", guests))
+	start = time.Now()
+	_, err = tx.ExecContext(ctx, withdrawOfferQuery, entryID)
+	observeQuery(logger, "update", start, err)
+	if err != nil {
+		logger.Error("withdraw_waitlist_offer_failed", zap.Error(err), zap.Int64("entry_id", entryID))
+		return false, fmt.Errorf("withdraw waitlist offer: %w", err)
+	}
+	return false, nil
+}
+
+// WaitlistEntryID извлекает ID записи из задания JobWaitlistOffer или JobWaitlistExpire.
+func WaitlistEntryID(job models.ScheduledJob) (int64, error) {
+	var p waitlistJob
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return 0, fmt.Errorf("decode waitlist job: %w", err)
+	}
+	return p.EntryID, nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+func newWaitlistRepo(t *testing.T) (*WaitlistRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	bookings, mock, cleanup := newBookingRepo(t, time.Now())
+	return NewWaitlistRepository(bookings), mock, cleanup
+}
+
+var lockedEntryColumns = []string{"status", "slot_id", "service_id", "slot_date", "slot_time",
+	"guest_name", "guest_organization", "guest_position", "visit_type", "offer_active"}
+
+func waitlistState() *models.BookingState {
+	return &models.BookingState{
+		UserID:       777,
+		ServiceID:    4,
+		SelectedDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		SelectedTime: "18:00",
+		GuestName:    "Иван Иванов",
+	}
+}
+
+func TestJoinWaitlist_OK(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`INSERT INTO waitlist_entries`).
+		WithArgs(int64(777), 4, "2026-03-14", sql.NullString{String: "18:00", Valid: true}, "Иван Иванов",
+			sql.NullString{}, sql.NullString{}, sql.NullString{}, []byte(`[{"name":"Иван Иванов"}]`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(21)))
+
+	id, err := repo.JoinWaitlist(context.Background(), waitlistState())
+	if err != nil {
+		t.Fatalf("JoinWaitlist err: %v", err)
+	}
+	if id != 21 {
+		t.Fatalf("expected entry 21, got %d", id)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestJoinWaitlist_AlreadyWaiting(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`INSERT INTO waitlist_entries`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(int64(777)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	_, err := repo.JoinWaitlist(context.Background(), waitlistState())
+	if !errors.Is(err, ErrAlreadyInWaitlist) {
+		t.Fatalf("expected ErrAlreadyInWaitlist, got %v", err)
+	}
+}
+
+func TestJoinWaitlist_UserNotRegistered(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`INSERT INTO waitlist_entries`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	_, err := repo.JoinWaitlist(context.Background(), waitlistState())
+	if !errors.Is(err, ErrUserNotRegistered) {
+		t.Fatalf("expected ErrUserNotRegistered, got %v", err)
+	}
+}
+
+func TestClaimOffer_CreatesBookingOnHeldSlot(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	date := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF w`).
+		WithArgs(int64(21), int64(777)).
+		WillReturnRows(sqlmock.NewRows(lockedEntryColumns).
+			AddRow("offered", int64(3), 4, date, "18:00", "Иван Иванов", nil, nil, nil, true))
+	expectNoGradeRule(mock)
+	mock.ExpectQuery(`INSERT INTO bookings`).
+		WithArgs(int64(777), 4, int64(3), "2026-03-14", sql.NullString{String: "18:00", Valid: true}, "Иван Иванов",
+			sql.NullString{}, sql.NullString{}, sql.NullString{}, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+	mock.ExpectExec(`INSERT INTO booking_guests`).
+		WithArgs(int64(42), pq.Array([]string{"Иван Иванов"}), pq.Array([]string{""}), pq.Array([]string{""})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO booking_status_history`).
+		WithArgs(int64(42), nil, models.BookingStatusPending, int64(777)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE waitlist_entries`).
+		WithArgs(int64(21), models.WaitlistStatusClaimed, sql.NullInt64{Int64: 42, Valid: true}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	bookingID, err := repo.ClaimOffer(context.Background(), 777, 21)
+	if err != nil {
+		t.Fatalf("ClaimOffer err: %v", err)
+	}
+	if bookingID != 42 {
+		t.Fatalf("expected booking 42, got %d", bookingID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestClaimOffer_Expired(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF w`).
+		WillReturnRows(sqlmock.NewRows(lockedEntryColumns).
+			AddRow("offered", int64(3), 4, time.Now(), nil, "Иван Иванов", nil, nil, nil, false))
+	mock.ExpectRollback()
+
+	_, err := repo.ClaimOffer(context.Background(), 777, 21)
+	if !errors.Is(err, ErrOfferExpired) {
+		t.Fatalf("expected ErrOfferExpired, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestClaimOffer_ForeignEntry(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF w`).
+		WithArgs(int64(21), int64(555)).
+		WillReturnRows(sqlmock.NewRows(lockedEntryColumns))
+	mock.ExpectRollback()
+
+	_, err := repo.ClaimOffer(context.Background(), 555, 21)
+	if !errors.Is(err, ErrWaitlistEntryNotFound) {
+		t.Fatalf("expected ErrWaitlistEntryNotFound, got %v", err)
+	}
+}
+
+func TestExpireOffer_ReleasesSlotWhenQueueEmpty(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF w`).
+		WithArgs(int64(21), int64(0)).
+		WillReturnRows(sqlmock.NewRows(lockedEntryColumns).
+			AddRow("offered", int64(3), 4, time.Now(), nil, "Иван Иванов", nil, nil, nil, false))
+	mock.ExpectExec(`UPDATE waitlist_entries`).
+		WithArgs(int64(21), models.WaitlistStatusExpired, sql.NullInt64{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE service_slots`).
+		WithArgs(int64(3), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE waitlist_entries`).
+		WithArgs(int64(3), WaitlistOfferTTL.Seconds(), "{}").
+		WillReturnRows(sqlmock.NewRows(offerColumns))
+	mock.ExpectCommit()
+
+	expired, err := repo.ExpireOffer(context.Background(), 21)
+	if err != nil {
+		t.Fatalf("ExpireOffer err: %v", err)
+	}
+	if !expired {
+		t.Fatal("expected offer to expire")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestExpireOffer_SkipsWaiterWhenSeatsCannotBeHeld(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	expires := time.Now().Add(WaitlistOfferTTL)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF w`).
+		WithArgs(int64(21), int64(0)).
+		WillReturnRows(sqlmock.NewRows(lockedEntryColumns).
+			AddRow("offered", int64(3), 4, time.Now(), nil, "Иван Иванов", nil, nil, nil, false))
+	mock.ExpectExec(`UPDATE waitlist_entries`).
+		WithArgs(int64(21), models.WaitlistStatusExpired, sql.NullInt64{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE service_slots`).
+		WithArgs(int64(3), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// группе из двух место предложили, но занять его уже не вышло
+	mock.ExpectQuery(`UPDATE waitlist_entries`).
+		WithArgs(int64(3), WaitlistOfferTTL.Seconds(), "{}").
+		WillReturnRows(sqlmock.NewRows(offerColumns).AddRow(int64(22), expires, 2))
+	mock.ExpectExec(`UPDATE service_slots`).
+		WithArgs(int64(3), 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SET status = 'waiting'`).
+		WithArgs(int64(22)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// место достаётся следующему в очереди
+	mock.ExpectQuery(`UPDATE waitlist_entries`).
+		WithArgs(int64(3), WaitlistOfferTTL.Seconds(), "{22}").
+		WillReturnRows(sqlmock.NewRows(offerColumns).AddRow(int64(23), expires, 1))
+	mock.ExpectExec(`UPDATE service_slots`).
+		WithArgs(int64(3), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO scheduled_jobs`).
+		WithArgs(JobWaitlistOffer, "waitlist_offer:23", []byte(`{"entry_id":23}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO scheduled_jobs`).
+		WithArgs(JobWaitlistExpire, "waitlist_expire:23", []byte(`{"entry_id":23}`), expires).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE waitlist_entries`).
+		WithArgs(int64(3), WaitlistOfferTTL.Seconds(), "{}").
+		WillReturnRows(sqlmock.NewRows(offerColumns))
+	mock.ExpectCommit()
+
+	if _, err := repo.ExpireOffer(context.Background(), 21); err != nil {
+		t.Fatalf("ExpireOffer err: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestExpireOffer_SkipsClaimed(t *testing.T) {
+	repo, mock, cleanup := newWaitlistRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE OF w`).
+		WillReturnRows(sqlmock.NewRows(lockedEntryColumns).
+			AddRow("claimed", int64(3), 4, time.Now(), nil, "Иван Иванов", nil, nil, nil, false))
+	mock.ExpectRollback()
+
+	expired, err := repo.ExpireOffer(context.Background(), 21)
+	if err != nil || expired {
+		t.Fatalf("expected no-op, got expired=%v err=%v", expired, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
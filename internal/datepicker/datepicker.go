@@ -0,0 +1,202 @@
+// Package datepicker строит inline-клавиатуру Telegram с календарём на месяц:
+// сетка дней с понедельника, переключение месяцев и недоступные дни.
+//
+// Callback data имеет вид "<prefix>:<действие>[:<дата>]", например "cal:d:260417"
+// или "cal:n:2605", и укладывается в ограничение Telegram в 64 байта.
+package datepicker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DefaultPrefix — префикс callback data, если он не задан в Calendar.
+const DefaultPrefix = "cal"
+
+const (
+	dayLayout   = "060102"
+	monthLayout = "0601"
+)
+
+// DayState — как показывать день в календаре.
+type DayState int
+
+const (
+	// DayDisabled — день нельзя выбрать, он зачёркнут.
+	DayDisabled DayState = iota
+	// DayAvailable — день можно выбрать.
+	DayAvailable
+	// DayMarked — день можно выбрать, но он помечен (например, запись только в лист ожидания).
+	DayMarked
+)
+
+// Action — что сделал пользователь в календаре.
+type Action int
+
+const (
+	// ActionNone — нажатие на заголовок, пустую клетку или недоступный день.
+	ActionNone Action = iota
+	// ActionSelect — выбран доступный день.
+	ActionSelect
+	// ActionSelectMarked — выбран помеченный день.
+	ActionSelectMarked
+	// ActionMonth — переход к другому месяцу.
+	ActionMonth
+)
+
+const (
+	actionNone     = "-"
+	actionSelect   = "d"
+	actionMarked   = "m"
+	actionMonth    = "n"
+	markedDayLabel = "🔒"
+)
+
+var weekdays = [...]string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+var months = [...]string{
+	"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь",
+}
+
+// Calendar описывает календарь. Prefix отличает его callback data от других кнопок,
+// он должен быть коротким. Min и Max ограничивают переключение месяцев, нулевое значение
+// снимает ограничение. Day возвращает состояние дня; если он не задан, доступны все дни.
+type Calendar struct {
+	Prefix string
+	Min    time.Time
+	Max    time.Time
+	Day    func(date time.Time) DayState
+}
+
+// Keyboard строит клавиатуру на месяц, в который попадает month.
+func (c Calendar) Keyboard(month time.Time) tgbotapi.InlineKeyboardMarkup {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(c.noop(fmt.Sprintf("%s %d", months[first.Month()-1], first.Year()))),
+	}
+
+	header := make([]tgbotapi.InlineKeyboardButton, 0, len(weekdays))
+	for _, wd := range weekdays {
+		header = append(header, c.noop(wd))
+	}
+	rows = append(rows, header)
+
+	// Понедельник — первый столбец.
+	offset := (int(first.Weekday()) + 6) % 7
+	week := make([]tgbotapi.InlineKeyboardButton, 0, len(weekdays))
+	for i := 0; i < offset; i++ {
+		week = append(week, c.noop(" "))
+	}
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		week = append(week, c.dayButton(d))
+		if len(week) == len(weekdays) {
+			rows = append(rows, week)
+			week = make([]tgbotapi.InlineKeyboardButton, 0, len(weekdays))
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < len(weekdays) {
+			week = append(week, c.noop(" "))
+		}
+		rows = append(rows, week)
+	}
+
+	prev, next := first.AddDate(0, -1, 0), first.AddDate(0, 1, 0)
+	nav := tgbotapi.NewInlineKeyboardRow(c.noop(" "), c.noop(" "))
+	if c.Min.IsZero() || !prev.Before(monthStart(c.Min)) {
+		nav[0] = tgbotapi.NewInlineKeyboardButtonData("◀", c.data(actionMonth, prev.Format(monthLayout)))
+	}
+	if c.Max.IsZero() || !next.After(monthStart(c.Max)) {
+		nav[1] = tgbotapi.NewInlineKeyboardButtonData("▶", c.data(actionMonth, next.Format(monthLayout)))
+	}
+	rows = append(rows, nav)
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// Parse разбирает callback data календаря. ok=false, если данные не относятся к нему.
+// Для ActionMonth возвращается первое число месяца, для выбора — сам день, в UTC.
+func (c Calendar) Parse(data string) (action Action, date time.Time, ok bool) {
+	rest, found := strings.CutPrefix(data, c.prefix()+":")
+	if !found {
+		return ActionNone, time.Time{}, false
+	}
+
+	kind, value, _ := strings.Cut(rest, ":")
+	switch kind {
+	case actionNone:
+		return ActionNone, time.Time{}, true
+	case actionSelect, actionMarked:
+		date, err := time.Parse(dayLayout, value)
+		if err != nil {
+			return ActionNone, time.Time{}, false
+		}
+		if kind == actionMarked {
+			return ActionSelectMarked, date, true
+		}
+		return ActionSelect, date, true
+	case actionMonth:
+		date, err := time.Parse(monthLayout, value)
+		if err != nil {
+			return ActionNone, time.Time{}, false
+		}
+		return ActionMonth, date, true
+	}
+	return ActionNone, time.Time{}, false
+}
+
+func (c Calendar) dayButton(d time.Time) tgbotapi.InlineKeyboardButton {
+	state := DayAvailable
+	if c.Day != nil {
+		state = c.Day(d)
+	}
+
+	label := strconv.Itoa(d.Day())
+	switch state {
+	case DayAvailable:
+		return tgbotapi.NewInlineKeyboardButtonData(label, c.data(actionSelect, d.Format(dayLayout)))
+	case DayMarked:
+		return tgbotapi.NewInlineKeyboardButtonData(markedDayLabel+label, c.data(actionMarked, d.Format(dayLayout)))
+	default:
+		return c.noop(strikethrough(label))
+	}
+}
+
+func (c Calendar) noop(text string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, c.data(actionNone, ""))
+}
+
+func (c Calendar) data(action, value string) string {
+	data := c.prefix() + ":" + action
+	if value != "" {
+		data += ":" + value
+	}
+	return data
+}
+
+func (c Calendar) prefix() string {
+	if c.Prefix == "" {
+		return DefaultPrefix
+	}
+	return c.Prefix
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// strikethrough зачёркивает текст комбинируемым символом U+0336.
+func strikethrough(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteRune(r)
+		b.WriteRune('̶')
+	}
+	return b.String()
+}
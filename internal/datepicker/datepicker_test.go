@@ -0,0 +1,105 @@
+package datepicker
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func texts(row []tgbotapi.InlineKeyboardButton) []string {
+	var out []string
+	for _, btn := range row {
+		out = append(out, btn.Text)
+	}
+	return out
+}
+
+func TestKeyboard_MonthGrid(t *testing.T) {
+	// Апрель 2026 начинается в среду.
+	kb := Calendar{}.Keyboard(day(2026, time.April, 17))
+	rows := kb.InlineKeyboard
+
+	require.Len(t, rows, 2+5+1)
+	assert.Equal(t, []string{"Апрель 2026"}, texts(rows[0]))
+	assert.Equal(t, []string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}, texts(rows[1]))
+	assert.Equal(t, []string{" ", " ", "1", "2", "3", "4", "5"}, texts(rows[2]))
+	assert.Equal(t, []string{"27", "28", "29", "30", " ", " ", " "}, texts(rows[6]))
+	assert.Equal(t, "cal:d:260401", *rows[2][2].CallbackData)
+
+	nav := rows[7]
+	assert.Equal(t, "cal:n:2603", *nav[0].CallbackData)
+	assert.Equal(t, "cal:n:2605", *nav[1].CallbackData)
+}
+
+func TestKeyboard_DayStatesAndBounds(t *testing.T) {
+	cal := Calendar{
+		Prefix: "x",
+		Min:    day(2026, time.April, 20),
+		Max:    day(2026, time.April, 30),
+		Day: func(d time.Time) DayState {
+			switch d.Day() {
+			case 20:
+				return DayAvailable
+			case 21:
+				return DayMarked
+			}
+			return DayDisabled
+		},
+	}
+	rows := cal.Keyboard(day(2026, time.April, 1)).InlineKeyboard
+
+	week := rows[5] // 20–26 апреля
+	assert.Equal(t, "20", week[0].Text)
+	assert.Equal(t, "x:d:260420", *week[0].CallbackData)
+	assert.Equal(t, "🔒21", week[1].Text)
+	assert.Equal(t, "x:m:260421", *week[1].CallbackData)
+	assert.Equal(t, "2̶2̶", week[2].Text)
+	assert.Equal(t, "x:-", *week[2].CallbackData)
+
+	nav := rows[len(rows)-1]
+	assert.Equal(t, "x:-", *nav[0].CallbackData)
+	assert.Equal(t, "x:-", *nav[1].CallbackData)
+}
+
+func TestKeyboard_CallbackDataFits(t *testing.T) {
+	for _, row := range (Calendar{}).Keyboard(day(2026, time.December, 1)).InlineKeyboard {
+		for _, btn := range row {
+			assert.LessOrEqual(t, len(*btn.CallbackData), 64)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cal := Calendar{}
+
+	action, date, ok := cal.Parse("cal:d:260417")
+	assert.True(t, ok)
+	assert.Equal(t, ActionSelect, action)
+	assert.Equal(t, day(2026, time.April, 17), date)
+
+	action, date, ok = cal.Parse("cal:m:260417")
+	assert.True(t, ok)
+	assert.Equal(t, ActionSelectMarked, action)
+	assert.Equal(t, day(2026, time.April, 17), date)
+
+	action, date, ok = cal.Parse("cal:n:2612")
+	assert.True(t, ok)
+	assert.Equal(t, ActionMonth, action)
+	assert.Equal(t, day(2026, time.December, 1), date)
+
+	action, _, ok = cal.Parse("cal:-")
+	assert.True(t, ok)
+	assert.Equal(t, ActionNone, action)
+
+	for _, data := range []string{"2026-04-17", "other:d:260417", "cal:d:bad", "cal:z"} {
+		_, _, ok := cal.Parse(data)
+		assert.False(t, ok, data)
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// Кнопки решения администратора. Префикс admin_ закрыт middleware авторизации.
+const (
+	CallbackAdminBookingApprove = "admin_booking_approve:{id:int}"
+	CallbackAdminBookingReject  = "admin_booking_reject:{id:int}"
+)
+
+// AdminBookingsRepository — операции, нужные для подтверждения бронирований.
+type AdminBookingsRepository interface {
+	GetBooking(ctx context.Context, bookingID int64) (*models.Booking, error)
+	UpdateBookingStatus(ctx context.Context, bookingID int64, next models.BookingStatus, changedBy int64) error
+}
+
+// AdminBookingsHandler отправляет новые бронирования в чат администраторов
+// и обрабатывает их подтверждение или отклонение.
+type AdminBookingsHandler struct {
+	repo        AdminBookingsRepository
+	sender      MessageSender
+	adminChatID int64
+	logger      *zap.Logger
+}
+
+func NewAdminBookingsHandler(repo AdminBookingsRepository, sender MessageSender, adminChatID int64, logger *zap.Logger) *AdminBookingsHandler {
+	return &AdminBookingsHandler{
+		repo:        repo,
+		sender:      sender,
+		adminChatID: adminChatID,
+		logger:      logger,
+	}
+}
+
+// NotifyNewBooking отправляет бронирование в чат администраторов с кнопками решения.
+// Подходит как BookingHook.
+func (h *AdminBookingsHandler) NotifyNewBooking(ctx context.Context, bookingID int64) error {
+	if h.adminChatID == 0 {
+		h.logger.Warn("admin_chat_not_configured", zap.Int64("booking_id", bookingID))
+		return nil
+	}
+
+	b, err := h.repo.GetBooking(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("get booking: %w", err)
+	}
+
+	text := fmt.Sprintf("🆕 Новое бронирование #%d\n\n%s", b.ID, composeBookingDetails(b))
+	buttons := [][]Button{{
+		{Text: "✅ Подтвердить", CallbackData: fmt.Sprintf("admin_booking_approve:%d", b.ID)},
+		{Text: "❌ Отклонить", CallbackData: fmt.Sprintf("admin_booking_reject:%d", b.ID)},
+	}}
+
+	if err := h.sender.SendMessage(h.adminChatID, text, buttons); err != nil {
+		return fmt.Errorf("send booking to admin chat: %w", err)
+	}
+
+	h.logger.Info("booking_sent_to_admins", zap.Int64("booking_id", b.ID), zap.Int64("admin_chat_id", h.adminChatID))
+	return nil
+}
+
+// Approve подтверждает бронирование.
+func (h *AdminBookingsHandler) Approve(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	return h.decide(ctx, q, models.BookingStatusConfirmed)
+}
+
+// Reject отклоняет бронирование.
+func (h *AdminBookingsHandler) Reject(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	return h.decide(ctx, q, models.BookingStatusCancelled)
+}
+
+func (h *AdminBookingsHandler) decide(ctx context.Context, q *tgbotapi.CallbackQuery, next models.BookingStatus) error {
+	id, ok := CallbackArgsFromContext(ctx).Int("id")
+	if !ok {
+		return fmt.Errorf("booking id is missing in callback %q", q.Data)
+	}
+	bookingID := int64(id)
+
+	replyTo := q.From.ID
+	if q.Message != nil {
+		replyTo = q.Message.Chat.ID
+	}
+
+	err := h.repo.UpdateBookingStatus(ctx, bookingID, next, q.From.ID)
+	switch {
+	case errors.Is(err, repository.ErrBookingNotFound):
+		return h.sender.SendMessage(replyTo, fmt.Sprintf("Бронирование #%d не найдено.", bookingID), nil)
+	case errors.Is(err, models.ErrInvalidStatusTransition):
+		return h.sender.SendMessage(replyTo, fmt.Sprintf("Бронирование #%d уже обработано.", bookingID), nil)
+	case err != nil:
+		h.logger.Error("failed_to_update_booking_status", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return err
+	}
+
+	h.logger.Info("booking_decision",
+		zap.Int64("booking_id", bookingID),
+		zap.Int64("admin_id", q.From.ID),
+		zap.String("status", string(next)),
+	)
+
+	b, err := h.repo.GetBooking(ctx, bookingID)
+	if err != nil {
+		h.logger.Error("failed_to_get_booking", zap.Error(err), zap.Int64("booking_id", bookingID))
+		return err
+	}
+
+	if err := h.sender.SendMessage(b.UserTelegramID, decisionMessage(b), [][]Button{
+		{{Text: "📋 Мои бронирования", CallbackData: CallbackMyBookings}},
+	}); err != nil {
+		// решение уже сохранено, пользователь увидит его в «Моих бронированиях»
+		h.logger.Error("failed_to_notify_user_about_decision", zap.Error(err), zap.Int64("booking_id", bookingID))
+	}
+
+	return h.sender.SendMessage(replyTo, fmt.Sprintf("Бронирование #%d: %s (%s)", bookingID, next.Title(), adminName(q.From)), nil)
+}
+
+func decisionMessage(b *models.Booking) string {
+	if b.Status == models.BookingStatusConfirmed {
+		return fmt.Sprintf("✅ Ваше бронирование «%s» на %s подтверждено.", b.ServiceTitle, b.When())
+	}
+	return fmt.Sprintf("❌ К сожалению, бронирование «%s» на %s отклонено.", b.ServiceTitle, b.When())
+}
+
+func adminName(u *tgbotapi.User) string {
+	if u.UserName != "" {
+		return "@" + u.UserName
+	}
+	return fmt.Sprintf("id %d", u.ID)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+type sentMessage struct {
+	chatID  int64
+	text    string
+	buttons [][]Button
+}
+
+// recordingSender запоминает все отправленные сообщения.
+type recordingSender struct {
+	sent []sentMessage
+}
+
+func (r *recordingSender) SendMessage(userID int64, text string, buttons [][]Button) error {
+	r.sent = append(r.sent, sentMessage{chatID: userID, text: text, buttons: buttons})
+	return nil
+}
+
+type fakeAdminRepo struct {
+	booking   models.Booking
+	changedBy int64
+}
+
+func (f *fakeAdminRepo) GetBooking(ctx context.Context, bookingID int64) (*models.Booking, error) {
+	b := f.booking
+	return &b, nil
+}
+
+func (f *fakeAdminRepo) UpdateBookingStatus(ctx context.Context, bookingID int64, next models.BookingStatus, changedBy int64) error {
+	if err := f.booking.Status.ValidateTransition(next); err != nil {
+		return err
+	}
+	f.booking.Status = next
+	f.changedBy = changedBy
+	return nil
+}
+
+func newPendingBooking() models.Booking {
+	return models.Booking{
+		ID:             12,
+		UserTelegramID: 777,
+		ServiceTitle:   "Третьяковская галерея",
+		BookingDate:    time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		GuestName:      "Иван Иванов",
+		Status:         models.BookingStatusPending,
+	}
+}
+
+func TestAdminBookings_NotifyNewBooking(t *testing.T) {
+	rs := &recordingSender{}
+	h := NewAdminBookingsHandler(&fakeAdminRepo{booking: newPendingBooking()}, rs, -100500, zap.NewNop())
+
+	require.NoError(t, h.NotifyNewBooking(context.Background(), 12))
+	require.Len(t, rs.sent, 1)
+	assert.Equal(t, int64(-100500), rs.sent[0].chatID)
+	assert.Contains(t, rs.sent[0].text, "#12")
+	assert.Equal(t, "admin_booking_approve:12", rs.sent[0].buttons[0][0].CallbackData)
+	assert.Equal(t, "admin_booking_reject:12", rs.sent[0].buttons[0][1].CallbackData)
+}
+
+func TestAdminBookings_NotifySkippedWithoutChat(t *testing.T) {
+	rs := &recordingSender{}
+	h := NewAdminBookingsHandler(&fakeAdminRepo{booking: newPendingBooking()}, rs, 0, zap.NewNop())
+
+	require.NoError(t, h.NotifyNewBooking(context.Background(), 12))
+	assert.Empty(t, rs.sent)
+}
+
+func adminCallback(id int) (context.Context, *tgbotapi.CallbackQuery) {
+	ctx := WithCallbackArgs(context.Background(), CallbackArgs{"id": id})
+	return ctx, &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: 1, UserName: "admin"},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100500}},
+	}
+}
+
+func TestAdminBookings_ApproveNotifiesUser(t *testing.T) {
+	repo := &fakeAdminRepo{booking: newPendingBooking()}
+	rs := &recordingSender{}
+	h := NewAdminBookingsHandler(repo, rs, -100500, zap.NewNop())
+
+	ctx, q := adminCallback(12)
+	require.NoError(t, h.Approve(ctx, q))
+
+	assert.Equal(t, models.BookingStatusConfirmed, repo.booking.Status)
+	assert.Equal(t, int64(1), repo.changedBy)
+	require.Len(t, rs.sent, 2)
+	assert.Equal(t, int64(777), rs.sent[0].chatID)
+	assert.Contains(t, rs.sent[0].text, "подтверждено")
+	assert.Equal(t, int64(-100500), rs.sent[1].chatID)
+	assert.Contains(t, rs.sent[1].text, "@admin")
+}
+
+func TestAdminBookings_RejectTwice(t *testing.T) {
+	repo := &fakeAdminRepo{booking: newPendingBooking()}
+	rs := &recordingSender{}
+	h := NewAdminBookingsHandler(repo, rs, -100500, zap.NewNop())
+
+	ctx, q := adminCallback(12)
+	require.NoError(t, h.Reject(ctx, q))
+	assert.Contains(t, rs.sent[0].text, "отклонено")
+
+	rs.sent = nil
+	require.NoError(t, h.Approve(ctx, q))
+	require.Len(t, rs.sent, 1)
+	assert.Equal(t, "Бронирование #12 уже обработано.", rs.sent[0].text)
+	assert.Equal(t, models.BookingStatusCancelled, repo.booking.Status)
+}
+
+func TestAdminBookings_NotifyListsGuests(t *testing.T) {
+	b := newPendingBooking()
+	b.GuestCount = 2
+	b.GuestNames = sql.NullString{String: "Иван Иванов, Пётр Петров", Valid: true}
+	rs := &recordingSender{}
+	h := NewAdminBookingsHandler(&fakeAdminRepo{booking: b}, rs, -100500, zap.NewNop())
+
+	require.NoError(t, h.NotifyNewBooking(context.Background(), 12))
+	assert.Contains(t, rs.sent[0].text, "Гости (2): Иван Иванов, Пётр Петров")
+}
@@ -0,0 +1,642 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/schedule"
+	"github.com/yandex-development-2-team/Go/internal/state"
+)
+
+// AdminServicesState — имя диалога редактора услуг в user_sessions.current_state.
+const AdminServicesState = "admin_services"
+
+// CallbackAdminServices — префикс кнопок редактора услуг. Префикс admin_ закрыт
+// middleware авторизации, дальше идёт действие и его аргумент: "admin_svc:open:4".
+const CallbackAdminServices = "admin_svc"
+
+// Действия редактора услуг.
+const (
+	adminSvcList      = "list"
+	adminSvcNew       = "new"
+	adminSvcOpen      = "open"
+	adminSvcEdit      = "edit"
+	adminSvcField     = "field"
+	adminSvcBooking   = "booking"
+	adminSvcCategory  = "cat"
+	adminSvcCatSet    = "cat_set"
+	adminSvcOptions   = "opts"
+	adminSvcOptAdd    = "opt_add"
+	adminSvcOptEdit   = "opt_edit"
+	adminSvcOptRemove = "opt_rm"
+	adminSvcOptType   = "opt_type"
+	adminSvcUp        = "up"
+	adminSvcDown      = "down"
+	adminSvcHide      = "hide"
+	adminSvcShow      = "show"
+	adminSvcDelete    = "delete"
+	adminSvcDeleteYes = "delete_yes"
+	adminSvcSave      = "save"
+	adminSvcClose     = "close"
+	adminSvcNoop      = "noop"
+)
+
+// Поля карточки, которые вводятся текстом.
+const (
+	adminFieldTitle       = "title"
+	adminFieldDescription = "description"
+	adminFieldRules       = "rules"
+	adminFieldSchedule    = "schedule"
+	adminFieldOption      = "option"
+)
+
+// adminFieldPrompts — вопрос для каждого текстового поля и ограничение длины.
+var adminFieldPrompts = map[string]struct {
+	prompt string
+	min    int
+	max    int
+}{
+	adminFieldTitle:       {"Введите название услуги (2–100 символов):", 2, 100},
+	adminFieldDescription: {"Введите описание услуги:", 2, 1000},
+	adminFieldRules:       {"Введите правила посещения:", 2, 1000},
+	adminFieldSchedule:    {"Введите часы работы по дням недели, каждый диапазон с новой строки:\nПН-ПТ: 10:00-18:00\nСБ: 11:00-14:00, 15:00-19:00\n\n«-» — без расписания: услуга не показывает часы работы и не бронируется.", 0, 500},
+	adminFieldOption:      {"Введите название варианта посещения (2–64 символа):", 2, 64},
+}
+
+const adminServicesHelp = "Команды администратора:\n/admin services — каталог услуг"
+
+// AdminServicesRepository — операции с каталогом для редактора (см. repository.ServiceRepository).
+// Все изменения записываются в журнал действий администраторов.
+type AdminServicesRepository interface {
+	ListServices(ctx context.Context) ([]models.Service, error)
+	ListServiceCategories(ctx context.Context) ([]models.ServiceCategory, error)
+	GetServiceByID(ctx context.Context, serviceID int) (*models.Service, error)
+	CreateService(ctx context.Context, adminID int64, svc models.Service) (*models.Service, error)
+	UpdateService(ctx context.Context, adminID int64, svc models.Service) error
+	SetServiceActive(ctx context.Context, adminID int64, serviceID int, active bool) error
+	MoveService(ctx context.Context, adminID int64, serviceID int, up bool) error
+	DeleteService(ctx context.Context, adminID int64, serviceID int) error
+}
+
+// AdminChecker проверяет права администратора (см. repository.UserRepository).
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, telegramID int64) (bool, error)
+}
+
+// AdminServicesHandler — редактор каталога услуг в боте: /admin services.
+// Кнопки редактора защищены middleware по префиксу admin_, а текстовые ответы
+// проверяются здесь, потому что приходят как обычные сообщения.
+type AdminServicesHandler struct {
+	repo   AdminServicesRepository
+	admins AdminChecker
+	store  state.Store[models.AdminServiceState]
+	sender MessageSender
+	logger *zap.Logger
+}
+
+func NewAdminServicesHandler(
+	repo AdminServicesRepository,
+	admins AdminChecker,
+	store state.Store[models.AdminServiceState],
+	sender MessageSender,
+	logger *zap.Logger,
+) *AdminServicesHandler {
+	if store == nil {
+		store = state.NewMemoryStore[models.AdminServiceState](AdminServicesState)
+	}
+	return &AdminServicesHandler{
+		repo:   repo,
+		admins: admins,
+		store:  store,
+		sender: sender,
+		logger: logger,
+	}
+}
+
+// HandleCommand обрабатывает /admin: "/admin services" открывает каталог.
+func (h *AdminServicesHandler) HandleCommand(ctx context.Context, msg *tgbotapi.Message) error {
+	if strings.TrimSpace(msg.CommandArguments()) != "services" {
+		return h.sender.SendMessage(msg.Chat.ID, adminServicesHelp, nil)
+	}
+	return h.sendList(ctx, msg.Chat.ID)
+}
+
+// Handle обрабатывает кнопки редактора.
+func (h *AdminServicesHandler) Handle(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	adminID, chatID := q.From.ID, q.From.ID
+	if q.Message != nil {
+		chatID = q.Message.Chat.ID
+	}
+
+	action, arg := parseAdminServicesData(q.Data)
+	switch action {
+	case adminSvcNoop:
+		return nil
+	case adminSvcList:
+		return h.sendList(ctx, chatID)
+	case adminSvcClose:
+		if err := h.store.Delete(ctx, adminID); err != nil {
+			return err
+		}
+		return h.sender.SendMessage(chatID, "Редактор услуг закрыт.", nil)
+	case adminSvcNew:
+		st := &models.AdminServiceState{Draft: models.Service{IsActive: true}, Dirty: true}
+		return h.askField(ctx, adminID, chatID, st, adminFieldTitle, 0)
+	case adminSvcOpen:
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid service id in callback %q", q.Data)
+		}
+		return h.open(ctx, adminID, chatID, id)
+	}
+
+	st, ok, err := h.store.Load(ctx, adminID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// черновик удалён по TTL или закрыт — начинаем со списка
+		return h.sendList(ctx, chatID)
+	}
+
+	switch action {
+	case adminSvcEdit:
+		return h.showEditor(ctx, adminID, chatID, &st)
+	case adminSvcField:
+		if _, ok := adminFieldPrompts[arg]; !ok || arg == adminFieldOption {
+			return fmt.Errorf("unknown service field %q", arg)
+		}
+		return h.askField(ctx, adminID, chatID, &st, arg, 0)
+	case adminSvcBooking:
+		st.Draft.HasBooking = !st.Draft.HasBooking
+		st.Dirty = true
+		return h.showEditor(ctx, adminID, chatID, &st)
+	case adminSvcCategory:
+		return h.showCategories(ctx, adminID, chatID, &st)
+	case adminSvcCatSet:
+		id, err := strconv.Atoi(arg)
+		if err != nil || id < 0 {
+			return h.showCategories(ctx, adminID, chatID, &st)
+		}
+		st.Draft.CategoryID = id
+		st.Dirty = true
+		return h.showEditor(ctx, adminID, chatID, &st)
+	case adminSvcOptions:
+		return h.showOptions(ctx, adminID, chatID, &st)
+	case adminSvcOptAdd:
+		return h.askField(ctx, adminID, chatID, &st, adminFieldOption, 0)
+	case adminSvcOptEdit, adminSvcOptRemove:
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > len(st.Draft.VisitOptions) {
+			return h.showOptions(ctx, adminID, chatID, &st)
+		}
+		if action == adminSvcOptEdit {
+			return h.askField(ctx, adminID, chatID, &st, adminFieldOption, n)
+		}
+		st.Draft.VisitOptions = append(st.Draft.VisitOptions[:n-1:n-1], st.Draft.VisitOptions[n:]...)
+		st.Dirty = true
+		return h.showOptions(ctx, adminID, chatID, &st)
+	case adminSvcOptType:
+		if st.Option < 1 || st.Option > len(st.Draft.VisitOptions) || (arg != "private" && arg != "public") {
+			return h.showOptions(ctx, adminID, chatID, &st)
+		}
+		st.Draft.VisitOptions[st.Option-1].VisitType = arg
+		st.Option = 0
+		st.Dirty = true
+		return h.showOptions(ctx, adminID, chatID, &st)
+	case adminSvcSave:
+		return h.save(ctx, adminID, chatID, &st)
+	}
+
+	// действия ниже меняют сохранённую услугу сразу, у нового черновика их нет
+	if st.Draft.ID == 0 {
+		return h.showEditor(ctx, adminID, chatID, &st)
+	}
+	serviceID := st.Draft.ID
+
+	switch action {
+	case adminSvcUp, adminSvcDown:
+		if err := h.repo.MoveService(ctx, adminID, serviceID, action == adminSvcUp); err != nil {
+			return h.fail(chatID, "move_service_failed", serviceID, err)
+		}
+		h.logger.Info("admin_service_moved", zap.Int64("admin_id", adminID), zap.Int("service_id", serviceID), zap.String("direction", action))
+		return h.sendList(ctx, chatID)
+	case adminSvcHide, adminSvcShow:
+		active := action == adminSvcShow
+		if err := h.repo.SetServiceActive(ctx, adminID, serviceID, active); err != nil {
+			return h.fail(chatID, "set_service_active_failed", serviceID, err)
+		}
+		h.logger.Info("admin_service_visibility_changed", zap.Int64("admin_id", adminID), zap.Int("service_id", serviceID), zap.Bool("active", active))
+		st.Draft.IsActive = active
+		return h.showEditor(ctx, adminID, chatID, &st)
+	case adminSvcDelete:
+		return h.sender.SendMessage(chatID, fmt.Sprintf("Удалить услугу «%s»? Это нельзя отменить.", st.Draft.Title), [][]Button{{
+			{Text: "🗑 Да, удалить", CallbackData: adminServicesData(adminSvcDeleteYes, "")},
+			{Text: "Отмена", CallbackData: adminServicesData(adminSvcEdit, "")},
+		}})
+	case adminSvcDeleteYes:
+		err := h.repo.DeleteService(ctx, adminID, serviceID)
+		if errors.Is(err, repository.ErrServiceInUse) {
+			return h.sender.SendMessage(chatID, "У услуги есть бронирования, удалить её нельзя. Скройте её из каталога.", [][]Button{{
+				{Text: "🙈 Скрыть", CallbackData: adminServicesData(adminSvcHide, "")},
+				{Text: "Назад", CallbackData: adminServicesData(adminSvcEdit, "")},
+			}})
+		}
+		if err != nil {
+			return h.fail(chatID, "delete_service_failed", serviceID, err)
+		}
+		h.logger.Info("admin_service_deleted", zap.Int64("admin_id", adminID), zap.Int("service_id", serviceID))
+		if err := h.store.Delete(ctx, adminID); err != nil {
+			return err
+		}
+		return h.sendList(ctx, chatID)
+	}
+
+	return nil
+}
+
+// IsActive сообщает, ждёт ли редактор от администратора текст.
+func (h *AdminServicesHandler) IsActive(ctx context.Context, userID int64) bool {
+	st, ok, err := h.store.Load(ctx, userID)
+	if err != nil {
+		h.logger.Error("get admin services state error", zap.Int64("user_id", userID), zap.Error(err))
+		return false
+	}
+	return ok && st.Step == models.AdminServiceStepInput
+}
+
+// HandleUpdate принимает текстовое значение поля. Кнопки редактора сюда не попадают:
+// они зарегистрированы по префиксу CallbackAdminServices.
+func (h *AdminServicesHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) error {
+	msg := update.Message
+	if msg == nil || msg.From == nil {
+		return nil
+	}
+
+	ok, err := h.admins.IsAdmin(ctx, msg.From.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		h.logger.Warn("admin_services_access_denied", zap.Int64("user_id", msg.From.ID))
+		return h.store.Delete(ctx, msg.From.ID)
+	}
+
+	st, found, err := h.store.Load(ctx, msg.From.ID)
+	if err != nil || !found {
+		return err
+	}
+	return h.saveField(ctx, msg.From.ID, msg.Chat.ID, &st, strings.TrimSpace(msg.Text))
+}
+
+func (h *AdminServicesHandler) open(ctx context.Context, adminID, chatID int64, serviceID int) error {
+	svc, err := h.repo.GetServiceByID(ctx, serviceID)
+	if errors.Is(err, repository.ErrServiceNotFound) {
+		if err := h.sender.SendMessage(chatID, "Услуга не найдена.", nil); err != nil {
+			return err
+		}
+		return h.sendList(ctx, chatID)
+	}
+	if err != nil {
+		return err
+	}
+	return h.showEditor(ctx, adminID, chatID, &models.AdminServiceState{Draft: *svc})
+}
+
+// askField переводит редактор в ожидание текста для поля. option — номер изменяемого
+// варианта посещения с 1, 0 — новый вариант.
+func (h *AdminServicesHandler) askField(ctx context.Context, adminID, chatID int64, st *models.AdminServiceState, field string, option int) error {
+	st.Step = models.AdminServiceStepInput
+	st.Field = field
+	st.Option = option
+	if err := h.setState(ctx, adminID, st); err != nil {
+		return err
+	}
+
+	cancel := adminSvcEdit
+	if field == adminFieldOption {
+		cancel = adminSvcOptions
+	}
+	if st.Draft.ID == 0 && st.Draft.Title == "" {
+		cancel = adminSvcList
+	}
+	return h.sender.SendMessage(chatID, adminFieldPrompts[field].prompt, [][]Button{{
+		{Text: "Отмена", CallbackData: adminServicesData(cancel, "")},
+	}})
+}
+
+// saveField записывает введённый текст в черновик.
+func (h *AdminServicesHandler) saveField(ctx context.Context, adminID, chatID int64, st *models.AdminServiceState, text string) error {
+	spec, ok := adminFieldPrompts[st.Field]
+	if !ok {
+		return h.showEditor(ctx, adminID, chatID, st)
+	}
+	if st.Field == adminFieldSchedule && text == "-" {
+		text = ""
+	}
+	if n := utf8.RuneCountInString(text); n < spec.min || n > spec.max {
+		return h.sender.SendMessage(chatID, fmt.Sprintf("Нужно от %d до %d символов. %s", spec.min, spec.max, spec.prompt), nil)
+	}
+
+	st.Dirty = true
+	switch st.Field {
+	case adminFieldTitle:
+		// у новой услуги после названия сразу выбираем категорию, чтобы она не попала в «Другое» случайно
+		first := st.Draft.ID == 0 && st.Draft.Title == ""
+		st.Draft.Title = text
+		if first {
+			return h.showCategories(ctx, adminID, chatID, st)
+		}
+	case adminFieldDescription:
+		st.Draft.Description = text
+	case adminFieldRules:
+		st.Draft.Rules = text
+	case adminFieldSchedule:
+		weekly, err := schedule.ParseWeekly(text)
+		var perr *schedule.ParseError
+		if errors.As(err, &perr) {
+			return h.sender.SendMessage(chatID, fmt.Sprintf("Не удалось разобрать строку «%s». %s", perr.Line, spec.prompt), nil)
+		}
+		if err != nil {
+			return err
+		}
+		st.Draft.Schedule = weekly
+	case adminFieldOption:
+		if st.Option < 1 || st.Option > len(st.Draft.VisitOptions) {
+			st.Draft.VisitOptions = append(st.Draft.VisitOptions, models.VisitOption{Title: text})
+			st.Option = len(st.Draft.VisitOptions)
+		} else {
+			st.Draft.VisitOptions[st.Option-1].Title = text
+		}
+		st.Step = models.AdminServiceStepEdit
+		st.Field = ""
+		if err := h.setState(ctx, adminID, st); err != nil {
+			return err
+		}
+		return h.sender.SendMessage(chatID, fmt.Sprintf("Тип посещения для «%s»:", text), [][]Button{{
+			{Text: "Приватное", CallbackData: adminServicesData(adminSvcOptType, "private")},
+			{Text: "Групповое", CallbackData: adminServicesData(adminSvcOptType, "public")},
+		}})
+	}
+	return h.showEditor(ctx, adminID, chatID, st)
+}
+
+func (h *AdminServicesHandler) save(ctx context.Context, adminID, chatID int64, st *models.AdminServiceState) error {
+	if st.Draft.Title == "" {
+		return h.askField(ctx, adminID, chatID, st, adminFieldTitle, 0)
+	}
+	for _, opt := range st.Draft.VisitOptions {
+		if opt.VisitType == "" {
+			return h.sender.SendMessage(chatID, fmt.Sprintf("У варианта «%s» не выбран тип посещения.", opt.Title), [][]Button{{
+				{Text: "🎟 Варианты посещения", CallbackData: adminServicesData(adminSvcOptions, "")},
+			}})
+		}
+	}
+
+	serviceID := st.Draft.ID
+	if serviceID == 0 {
+		created, err := h.repo.CreateService(ctx, adminID, st.Draft)
+		if err != nil {
+			return h.fail(chatID, "create_service_failed", 0, err)
+		}
+		serviceID = created.ID
+	} else if err := h.repo.UpdateService(ctx, adminID, st.Draft); err != nil {
+		return h.fail(chatID, "update_service_failed", serviceID, err)
+	}
+	h.logger.Info("admin_service_saved", zap.Int64("admin_id", adminID), zap.Int("service_id", serviceID))
+
+	if err := h.store.Delete(ctx, adminID); err != nil {
+		return err
+	}
+	if err := h.sender.SendMessage(chatID, fmt.Sprintf("✅ Услуга «%s» сохранена.", st.Draft.Title), nil); err != nil {
+		return err
+	}
+	return h.sendList(ctx, chatID)
+}
+
+func (h *AdminServicesHandler) sendList(ctx context.Context, chatID int64) error {
+	services, err := h.repo.ListServices(ctx)
+	if err != nil {
+		h.logger.Error("list_services_failed", zap.Error(err))
+		return err
+	}
+
+	buttons := make([][]Button, 0, len(services)+2)
+	for _, svc := range services {
+		title := svc.Title
+		if !svc.IsActive {
+			title = "🙈 " + title
+		}
+		buttons = append(buttons, []Button{{Text: title, CallbackData: adminServicesData(adminSvcOpen, strconv.Itoa(svc.ID))}})
+	}
+	buttons = append(buttons,
+		[]Button{{Text: "➕ Добавить услугу", CallbackData: adminServicesData(adminSvcNew, "")}},
+		[]Button{{Text: "✖️ Закрыть", CallbackData: adminServicesData(adminSvcClose, "")}},
+	)
+	return h.sender.SendMessage(chatID, "🛠 Каталог услуг. Скрытые услуги отмечены 🙈.\nВыберите услугу:", buttons)
+}
+
+// showEditor отправляет предпросмотр карточки в том виде, в каком её увидят пользователи,
+// и меню редактирования под ним.
+func (h *AdminServicesHandler) showEditor(ctx context.Context, adminID, chatID int64, st *models.AdminServiceState) error {
+	st.Step = models.AdminServiceStepEdit
+	st.Field = ""
+	if err := h.setState(ctx, adminID, st); err != nil {
+		return err
+	}
+
+	text, buttons := servicePreview(st.Draft)
+	if err := h.sender.SendMessage(chatID, text, buttons); err != nil {
+		return err
+	}
+	return h.sender.SendMessage(chatID, adminEditorText(st), adminEditorButtons(st.Draft))
+}
+
+func (h *AdminServicesHandler) showOptions(ctx context.Context, adminID, chatID int64, st *models.AdminServiceState) error {
+	st.Step = models.AdminServiceStepEdit
+	st.Field = ""
+	if err := h.setState(ctx, adminID, st); err != nil {
+		return err
+	}
+
+	var buttons [][]Button
+	for i, opt := range st.Draft.VisitOptions {
+		n := strconv.Itoa(i + 1)
+		buttons = append(buttons, []Button{
+			{Text: fmt.Sprintf("%s. %s (%s)", n, opt.Title, visitTypeLabel(opt.VisitType)), CallbackData: adminServicesData(adminSvcOptEdit, n)},
+			{Text: "🗑", CallbackData: adminServicesData(adminSvcOptRemove, n)},
+		})
+	}
+	buttons = append(buttons,
+		[]Button{{Text: "➕ Добавить вариант", CallbackData: adminServicesData(adminSvcOptAdd, "")}},
+		[]Button{{Text: "← К услуге", CallbackData: adminServicesData(adminSvcEdit, "")}},
+	)
+
+	text := "Варианты посещения. Если они есть, вместо кнопки «Забронировать» пользователь выбирает вариант."
+	if len(st.Draft.VisitOptions) == 0 {
+		text = "Вариантов посещения нет."
+	}
+	return h.sender.SendMessage(chatID, text, buttons)
+}
+
+// showCategories предлагает выбрать категорию каталога; текущая отмечена ✅.
+func (h *AdminServicesHandler) showCategories(ctx context.Context, adminID, chatID int64, st *models.AdminServiceState) error {
+	st.Step = models.AdminServiceStepEdit
+	st.Field = ""
+	if err := h.setState(ctx, adminID, st); err != nil {
+		return err
+	}
+
+	categories, err := h.repo.ListServiceCategories(ctx)
+	if err != nil {
+		h.logger.Error("list_service_categories_failed", zap.Error(err))
+		return err
+	}
+
+	buttons := make([][]Button, 0, len(categories)+2)
+	for _, c := range categories {
+		buttons = append(buttons, []Button{{Text: categoryLabel(c.Title, c.ID == st.Draft.CategoryID), CallbackData: adminServicesData(adminSvcCatSet, strconv.Itoa(c.ID))}})
+	}
+	buttons = append(buttons,
+		[]Button{{Text: categoryLabel("Без категории («Другое»)", st.Draft.CategoryID == 0), CallbackData: adminServicesData(adminSvcCatSet, "0")}},
+		[]Button{{Text: "← К услуге", CallbackData: adminServicesData(adminSvcEdit, "")}},
+	)
+	return h.sender.SendMessage(chatID, "Выберите категорию услуги в каталоге:", buttons)
+}
+
+func (h *AdminServicesHandler) setState(ctx context.Context, adminID int64, st *models.AdminServiceState) error {
+	st.UpdatedAt = time.Now()
+	return h.store.Save(ctx, adminID, *st)
+}
+
+func (h *AdminServicesHandler) fail(chatID int64, event string, serviceID int, err error) error {
+	h.logger.Error(event, zap.Int("service_id", serviceID), zap.Error(err))
+	// наружу возвращаем ошибку сохранения, а не отправки ответа о ней
+	if serr := h.sender.SendMessage(chatID, "Не удалось сохранить изменения, попробуйте ещё раз.", nil); serr != nil {
+		h.logger.Error("failed_to_send_error_reply", zap.Int64("chat_id", chatID), zap.Error(serr))
+	}
+	return err
+}
+
+// servicePreview повторяет карточку из HandleServiceDetail; кнопки в предпросмотре не работают.
+func servicePreview(svc models.Service) (string, [][]Button) {
+	text := composeMessage(svc)
+	if len(svc.VisitOptions) > 0 {
+		text += "\n\nВыберите тип посещения:"
+	}
+
+	buttons := buildButtons(svc, "")
+	for _, row := range buttons {
+		for i := range row {
+			row[i].CallbackData = adminServicesData(adminSvcNoop, "")
+		}
+	}
+	return text, buttons
+}
+
+func adminEditorText(st *models.AdminServiceState) string {
+	title := "Новая услуга"
+	if st.Draft.ID != 0 {
+		title = fmt.Sprintf("Услуга #%d", st.Draft.ID)
+	}
+
+	lines := []string{"Предпросмотр выше. " + title + "."}
+	if !st.Draft.IsActive {
+		lines = append(lines, "🙈 Скрыта из каталога.")
+	}
+	if st.Dirty {
+		lines = append(lines, "Есть несохранённые изменения.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func adminEditorButtons(svc models.Service) [][]Button {
+	booking := "Бронирование: выкл"
+	if svc.HasBooking {
+		booking = "Бронирование: вкл"
+	}
+
+	buttons := [][]Button{
+		{
+			{Text: "✏️ Название", CallbackData: adminServicesData(adminSvcField, adminFieldTitle)},
+			{Text: "📝 Описание", CallbackData: adminServicesData(adminSvcField, adminFieldDescription)},
+		},
+		{
+			{Text: "📋 Правила", CallbackData: adminServicesData(adminSvcField, adminFieldRules)},
+			{Text: "🕒 Расписание", CallbackData: adminServicesData(adminSvcField, adminFieldSchedule)},
+		},
+		{
+			{Text: fmt.Sprintf("🎟 Варианты посещения (%d)", len(svc.VisitOptions)), CallbackData: adminServicesData(adminSvcOptions, "")},
+			{Text: booking, CallbackData: adminServicesData(adminSvcBooking, "")},
+		},
+		{
+			{Text: "🗂 Категория", CallbackData: adminServicesData(adminSvcCategory, "")},
+		},
+	}
+
+	if svc.ID != 0 {
+		visibility := Button{Text: "🙈 Скрыть", CallbackData: adminServicesData(adminSvcHide, "")}
+		if !svc.IsActive {
+			visibility = Button{Text: "👁 Показать", CallbackData: adminServicesData(adminSvcShow, "")}
+		}
+		buttons = append(buttons,
+			[]Button{
+				{Text: "⬆️ Выше", CallbackData: adminServicesData(adminSvcUp, "")},
+				{Text: "⬇️ Ниже", CallbackData: adminServicesData(adminSvcDown, "")},
+			},
+			[]Button{visibility, {Text: "🗑 Удалить", CallbackData: adminServicesData(adminSvcDelete, "")}},
+		)
+	}
+
+	return append(buttons, []Button{
+		{Text: "💾 Сохранить", CallbackData: adminServicesData(adminSvcSave, "")},
+		{Text: "← К списку", CallbackData: adminServicesData(adminSvcList, "")},
+	})
+}
+
+func categoryLabel(title string, selected bool) string {
+	if selected {
+		return "✅ " + title
+	}
+	return title
+}
+
+func visitTypeLabel(visitType string) string {
+	switch visitType {
+	case "private":
+		return "приватное"
+	case "public":
+		return "групповое"
+	default:
+		return "тип не выбран"
+	}
+}
+
+func adminServicesData(action, arg string) string {
+	data := CallbackAdminServices + ":" + action
+	if arg != "" {
+		data += ":" + arg
+	}
+	return data
+}
+
+// parseAdminServicesData разбирает "admin_svc:<действие>[:<аргумент>]"; без действия — список.
+func parseAdminServicesData(data string) (action, arg string) {
+	rest, _ := strings.CutPrefix(data, CallbackAdminServices)
+	rest = strings.TrimPrefix(rest, ":")
+	if rest == "" {
+		return adminSvcList, ""
+	}
+	action, arg, _ = strings.Cut(rest, ":")
+	return action, arg
+}
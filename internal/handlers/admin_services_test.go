@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/state"
+)
+
+type fakeServicesAdminRepo struct {
+	services   map[int]models.Service
+	categories []models.ServiceCategory
+	created    []models.Service
+	updated    []models.Service
+	deleted    []int
+	inUse      bool
+}
+
+func (f *fakeServicesAdminRepo) ListServices(ctx context.Context) ([]models.Service, error) {
+	var list []models.Service
+	for _, svc := range f.services {
+		list = append(list, svc)
+	}
+	return list, nil
+}
+
+func (f *fakeServicesAdminRepo) ListServiceCategories(ctx context.Context) ([]models.ServiceCategory, error) {
+	return f.categories, nil
+}
+
+func (f *fakeServicesAdminRepo) GetServiceByID(ctx context.Context, serviceID int) (*models.Service, error) {
+	svc, ok := f.services[serviceID]
+	if !ok {
+		return nil, repository.ErrServiceNotFound
+	}
+	return &svc, nil
+}
+
+func (f *fakeServicesAdminRepo) CreateService(ctx context.Context, adminID int64, svc models.Service) (*models.Service, error) {
+	svc.ID = 100 + len(f.created)
+	f.created = append(f.created, svc)
+	return &svc, nil
+}
+
+func (f *fakeServicesAdminRepo) UpdateService(ctx context.Context, adminID int64, svc models.Service) error {
+	f.updated = append(f.updated, svc)
+	return nil
+}
+
+func (f *fakeServicesAdminRepo) SetServiceActive(ctx context.Context, adminID int64, serviceID int, active bool) error {
+	svc := f.services[serviceID]
+	svc.IsActive = active
+	f.services[serviceID] = svc
+	return nil
+}
+
+func (f *fakeServicesAdminRepo) MoveService(ctx context.Context, adminID int64, serviceID int, up bool) error {
+	return nil
+}
+
+func (f *fakeServicesAdminRepo) DeleteService(ctx context.Context, adminID int64, serviceID int) error {
+	if f.inUse {
+		return repository.ErrServiceInUse
+	}
+	f.deleted = append(f.deleted, serviceID)
+	return nil
+}
+
+type fakeAdmins map[int64]bool
+
+func (f fakeAdmins) IsAdmin(ctx context.Context, telegramID int64) (bool, error) {
+	return f[telegramID], nil
+}
+
+const testAdminID = 42
+
+func newAdminServicesTest(repo *fakeServicesAdminRepo) (*AdminServicesHandler, *recordingSender) {
+	sender := &recordingSender{}
+	h := NewAdminServicesHandler(repo, fakeAdmins{testAdminID: true},
+		state.NewMemoryStore[models.AdminServiceState](AdminServicesState), sender, zap.NewNop())
+	return h, sender
+}
+
+func serviceEditorCallback(data string) *tgbotapi.CallbackQuery {
+	return &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: testAdminID},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: testAdminID}},
+		Data:    data,
+	}
+}
+
+func serviceEditorText(userID int64, text string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: userID},
+		Chat: &tgbotapi.Chat{ID: userID},
+		Text: text,
+	}}
+}
+
+func TestAdminServices_CreateService(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeServicesAdminRepo{services: map[int]models.Service{}}
+	h, sender := newAdminServicesTest(repo)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:new")))
+	assert.True(t, h.IsActive(ctx, testAdminID), "редактор ждёт название")
+
+	require.NoError(t, h.HandleUpdate(ctx, serviceEditorText(testAdminID, "Экскурсия")))
+	assert.False(t, h.IsActive(ctx, testAdminID))
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:opt_add")))
+	require.NoError(t, h.HandleUpdate(ctx, serviceEditorText(testAdminID, "Приватный тур")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:opt_type:private")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:save")))
+
+	require.Len(t, repo.created, 1)
+	created := repo.created[0]
+	assert.Equal(t, "Экскурсия", created.Title)
+	assert.True(t, created.IsActive)
+	assert.Equal(t, models.VisitOptions{{Title: "Приватный тур", VisitType: "private"}}, created.VisitOptions)
+
+	_, ok, err := h.store.Load(ctx, testAdminID)
+	require.NoError(t, err)
+	assert.False(t, ok, "после сохранения черновик удаляется")
+	assert.Contains(t, sender.sent[len(sender.sent)-2].text, "сохранена")
+}
+
+func TestAdminServices_NewServiceAsksCategory(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeServicesAdminRepo{
+		services:   map[int]models.Service{},
+		categories: []models.ServiceCategory{{ID: 1, Title: "Музеи"}, {ID: 3, Title: "Спорт"}},
+	}
+	h, sender := newAdminServicesTest(repo)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:new")))
+	require.NoError(t, h.HandleUpdate(ctx, serviceEditorText(testAdminID, "Теннис")))
+
+	picker := sender.sent[len(sender.sent)-1]
+	assert.Contains(t, picker.text, "категорию")
+	require.Len(t, picker.buttons, 4)
+	assert.Equal(t, "admin_svc:cat_set:3", picker.buttons[1][0].CallbackData)
+	assert.Equal(t, "✅ Без категории («Другое»)", picker.buttons[2][0].Text)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:cat_set:3")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:save")))
+
+	require.Len(t, repo.created, 1)
+	assert.Equal(t, 3, repo.created[0].CategoryID)
+}
+
+func TestAdminServices_ChangeCategory(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeServicesAdminRepo{
+		services:   map[int]models.Service{1: {ID: 1, Title: "Галерея", IsActive: true, CategoryID: 1}},
+		categories: []models.ServiceCategory{{ID: 1, Title: "Музеи"}, {ID: 2, Title: "Театры"}},
+	}
+	h, sender := newAdminServicesTest(repo)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:open:1")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:cat")))
+	assert.Equal(t, "✅ Музеи", sender.sent[len(sender.sent)-1].buttons[0][0].Text)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:cat_set:0")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:save")))
+
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, 0, repo.updated[0].CategoryID)
+}
+
+func TestAdminServices_PreviewButtonsAreInert(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeServicesAdminRepo{services: map[int]models.Service{
+		1: {ID: 1, Title: "Галерея", Description: "Описание", IsActive: true,
+			VisitOptions: models.VisitOptions{{Title: "Групповой тур", VisitType: "public"}}},
+	}}
+	h, sender := newAdminServicesTest(repo)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:open:1")))
+	require.Len(t, sender.sent, 2)
+
+	preview := sender.sent[0]
+	assert.Contains(t, preview.text, "Галерея")
+	for _, row := range preview.buttons {
+		for _, b := range row {
+			assert.Equal(t, "admin_svc:noop", b.CallbackData)
+		}
+	}
+	assert.Contains(t, flattenButtons(sender.sent[1].buttons), "admin_svc:hide")
+}
+
+func TestAdminServices_EditValidatesLength(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeServicesAdminRepo{services: map[int]models.Service{1: {ID: 1, Title: "Галерея", IsActive: true}}}
+	h, sender := newAdminServicesTest(repo)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:open:1")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:field:title")))
+	require.NoError(t, h.HandleUpdate(ctx, serviceEditorText(testAdminID, "Г")))
+
+	assert.Contains(t, sender.sent[len(sender.sent)-1].text, "Нужно от 2 до 100 символов")
+	assert.True(t, h.IsActive(ctx, testAdminID), "ввод повторяется")
+
+	require.NoError(t, h.HandleUpdate(ctx, serviceEditorText(testAdminID, "Новая галерея")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:save")))
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, "Новая галерея", repo.updated[0].Title)
+}
+
+func TestAdminServices_DeleteInUseSuggestsHiding(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeServicesAdminRepo{
+		services: map[int]models.Service{1: {ID: 1, Title: "Галерея", IsActive: true}},
+		inUse:    true,
+	}
+	h, sender := newAdminServicesTest(repo)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:open:1")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:delete_yes")))
+
+	last := sender.sent[len(sender.sent)-1]
+	assert.Contains(t, last.text, "Скройте")
+	assert.Contains(t, flattenButtons(last.buttons), "admin_svc:hide")
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:hide")))
+	assert.False(t, repo.services[1].IsActive)
+}
+
+func TestAdminServices_TextFromNonAdminIsIgnored(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeServicesAdminRepo{services: map[int]models.Service{}}
+	h, _ := newAdminServicesTest(repo)
+
+	const userID = 7
+	require.NoError(t, h.store.Save(ctx, userID, models.AdminServiceState{Step: models.AdminServiceStepInput, Field: adminFieldTitle}))
+	require.NoError(t, h.HandleUpdate(ctx, serviceEditorText(userID, "Экскурсия")))
+
+	_, ok, err := h.store.Load(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, repo.created)
+}
+
+// failingSender не может доставить первое сообщение, остальные уходят.
+type failingSender struct {
+	err   error
+	calls int
+}
+
+func (f *failingSender) SendMessage(userID int64, text string, buttons [][]Button) error {
+	f.calls++
+	if f.calls == 1 {
+		return f.err
+	}
+	return nil
+}
+
+func TestAdminServices_SendErrorIsReturned(t *testing.T) {
+	ctx := context.Background()
+	sendErr := errors.New("telegram unavailable")
+	h := NewAdminServicesHandler(&fakeServicesAdminRepo{services: map[int]models.Service{}}, fakeAdmins{testAdminID: true},
+		state.NewMemoryStore[models.AdminServiceState](AdminServicesState), &failingSender{err: sendErr}, zap.NewNop())
+
+	err := h.Handle(ctx, serviceEditorCallback("admin_svc:open:7"))
+	assert.ErrorIs(t, err, sendErr, "ответ «Услуга не найдена» не дошёл")
+}
+
+func TestParseAdminServicesData(t *testing.T) {
+	cases := map[string][2]string{
+		"admin_svc":             {adminSvcList, ""},
+		"admin_svc:open:4":      {adminSvcOpen, "4"},
+		"admin_svc:field:rules": {adminSvcField, "rules"},
+		"admin_svc:save":        {adminSvcSave, ""},
+	}
+	for data, want := range cases {
+		action, arg := parseAdminServicesData(data)
+		assert.Equal(t, want, [2]string{action, arg}, data)
+	}
+}
+
+func flattenButtons(rows [][]Button) []string {
+	var data []string
+	for _, row := range rows {
+		for _, b := range row {
+			data = append(data, b.CallbackData)
+		}
+	}
+	return data
+}
+
+func TestAdminServices_ScheduleIsParsed(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeServicesAdminRepo{services: map[int]models.Service{1: {ID: 1, Title: "Галерея", IsActive: true}}}
+	h, sender := newAdminServicesTest(repo)
+
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:open:1")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:field:schedule")))
+	require.NoError(t, h.HandleUpdate(ctx, serviceEditorText(testAdminID, "ПН-ПТ 10:00-18:00")))
+	assert.Contains(t, sender.sent[len(sender.sent)-1].text, "Не удалось разобрать строку «ПН-ПТ 10:00-18:00»")
+	assert.True(t, h.IsActive(ctx, testAdminID))
+
+	require.NoError(t, h.HandleUpdate(ctx, serviceEditorText(testAdminID, "ВТ-ВС: 10:00-18:00")))
+	require.NoError(t, h.Handle(ctx, serviceEditorCallback("admin_svc:save")))
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, "ВТ-ВС: 10:00-18:00", repo.updated[0].Schedule.String())
+}
@@ -2,216 +2,1079 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
 
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/datepicker"
+	"github.com/yandex-development-2-team/Go/internal/metrics"
 	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/state"
 )
 
+// BookingFormState — имя диалога формы бронирования в user_sessions.current_state.
+const BookingFormState = "booking_form"
+
+const slotTakenMessage = "К сожалению, это время только что заняли 😔\n\nВыберите, пожалуйста, другую дату или время."
+
+const slotUnavailableMessage = "Эти дата или время недоступны для бронирования 😔\n\nВыберите, пожалуйста, другую дату или время."
+
+const gradeNotAllowedMessage = "Эта услуга недоступна для вашего уровня."
+
+const monthlyLimitMessage = "Вы уже исчерпали лимит бронирований этой услуги на месяц.\n\nМожно выбрать дату в следующем месяце."
+
+const bookingExpiredMessage = "Ваша заявка истекла: форма долго оставалась без ответа. Начните бронирование заново."
+
+const formCancelledMessage = "Бронирование отменено. Можно начать заново из меню."
+
+const bookingCreatedMessage = "Готово! Заявка на бронирование отправлена ✅\n\nМы сообщим, когда администратор её подтвердит."
+
+// slotTimeLayout — формат времени слота в callback data.
+const slotTimeLayout = "15:04"
+
+const waitlistJoinedMessage = "Вы в листе ожидания ✅\n\nЕсли место освободится, мы пришлём предложение — на ответ будет %d минут."
+
+// slotsPerRow — сколько кнопок со временем помещается в одну строку клавиатуры.
+const slotsPerRow = 4
+
+// Callback data кнопок подтверждения. confirm_no — прежняя кнопка «Изменить дату»,
+// сообщения с ней могли остаться в чатах, поэтому она работает как edit_date.
+const (
+	confirmYesData   = "confirm_yes"
+	confirmNoData    = "confirm_no"
+	editDateData     = "edit_date"
+	editNameData     = "edit_name"
+	editOrgData      = "edit_org"
+	editPositionData = "edit_position"
+)
+
+// formCancelData — кнопка «Отмена», которая есть на каждом шаге формы.
+const formCancelData = "form_cancel"
+
+// Callback data шага со списком гостей.
+const (
+	guestAddData    = "guest_add"
+	guestEditData   = "guest_edit:"   // + номер гостя с 1
+	guestRemoveData = "guest_remove:" // + номер гостя с 1
+	guestsDoneData  = "guests_done"
+	guestsEditData  = "guests_review" // вернуться к списку гостей из подтверждения
+)
+
+// waitlistPrefix отмечает в callback data занятую дату или время, на которые можно встать в очередь.
+const waitlistPrefix = "wl:"
+
 type BookingRepository interface {
-	SaveBooking(ctx context.Context, state *models.BookingState) error
+	SaveBooking(ctx context.Context, state *models.BookingState) (int64, error)
+	CheckBookingAllowed(ctx context.Context, telegramID int64, serviceID int, visitType string) error
+	GetMaxGuests(ctx context.Context, serviceID int) (int, error)
 	GetAvailableDates(ctx context.Context, serviceID int) ([]time.Time, error)
+	GetAvailableSlots(ctx context.Context, serviceID int, date time.Time) ([]string, bool, error)
+}
+
+// BookingWaitlist ставит в лист ожидания на занятые даты и слоты.
+type BookingWaitlist interface {
+	JoinWaitlist(ctx context.Context, state *models.BookingState) (int64, error)
+	GetFullDates(ctx context.Context, serviceID int) ([]time.Time, error)
+	GetTakenSlots(ctx context.Context, serviceID int, date time.Time) ([]string, error)
 }
 
+// BookingHook вызывается после создания или изменения бронирования. Ошибка хука логируется
+// и не откатывает само изменение.
+type BookingHook func(ctx context.Context, bookingID int64) error
+
 type BookingFormHandler struct {
-	bot   *tgbotapi.BotAPI
-	db    BookingRepository
-	log   *zap.Logger
-	store map[int64]*models.BookingState
-	mu    sync.RWMutex
+	bot       *tgbotapi.BotAPI
+	db        BookingRepository
+	log       *zap.Logger
+	store     state.Store[models.BookingState]
+	waitlist  BookingWaitlist
+	onCreated []BookingHook
+	ttl       time.Duration
+	services  ServiceCatalog
 }
 
+// NewBookingFormHandler создаёт обработчик формы бронирования. Если store не задан,
+// состояние форм хранится в памяти процесса.
 func NewBookingFormHandler(
 	bot *tgbotapi.BotAPI,
 	db BookingRepository,
+	store state.Store[models.BookingState],
 	log *zap.Logger,
 ) *BookingFormHandler {
+	if store == nil {
+		store = state.NewMemoryStore[models.BookingState](BookingFormState)
+	}
 	return &BookingFormHandler{
 		bot:   bot,
 		db:    db,
 		log:   log,
-		store: make(map[int64]*models.BookingState),
+		store: store,
 	}
 }
 
-func (h *BookingFormHandler) Start(userID int64, serviceID int, visitType string) {
-	h.mu.Lock()
-	h.store[userID] = &models.BookingState{
+// OnBookingCreated регистрирует хук, вызываемый после сохранения бронирования.
+func (h *BookingFormHandler) OnBookingCreated(hook BookingHook) {
+	h.onCreated = append(h.onCreated, hook)
+}
+
+// EnableWaitlist показывает в форме занятые даты и слоты, на которые можно встать в очередь.
+func (h *BookingFormHandler) EnableWaitlist(w BookingWaitlist) {
+	h.waitlist = w
+}
+
+// SetServiceCatalog подключает каталог услуг: из него форма берёт тип посещения
+// для выбранного варианта и не даёт бронировать неактивные услуги.
+func (h *BookingFormHandler) SetServiceCatalog(c ServiceCatalog) {
+	h.services = c
+}
+
+// SetTTL задаёт, сколько форма живёт без действий пользователя. После этого
+// на следующее действие приходит bookingExpiredMessage, а состояние удаляется.
+func (h *BookingFormHandler) SetTTL(ttl time.Duration) {
+	h.ttl = ttl
+}
+
+func (h *BookingFormHandler) Start(ctx context.Context, userID int64, serviceID int, visitType string) error {
+	maxGuests, err := h.db.GetMaxGuests(ctx, serviceID)
+	if err != nil {
+		h.log.Error("get max guests error", zap.Error(err))
+		return err
+	}
+
+	return h.setState(ctx, userID, &models.BookingState{
 		UserID:    userID,
 		ServiceID: serviceID,
 		VisitType: visitType,
+		MaxGuests: maxGuests,
 		Step:      models.BookingStepSelectDate,
 		CreatedAt: time.Now(),
+	})
+}
+
+// Handle запускает форму бронирования по кнопкам карточки услуги.
+// Ожидает маршруты вида "option:{service:int}:{idx:int}" и "book_now:{service:int}".
+func (h *BookingFormHandler) Handle(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	args := CallbackArgsFromContext(ctx)
+
+	serviceID, ok := args.Int("service")
+	if !ok {
+		err := fmt.Errorf("service id is missing in callback %q", q.Data)
+		h.log.Error("invalid_booking_start_data", zap.String("data", q.Data), zap.Error(err))
+		return err
+	}
+
+	chatID := q.From.ID
+	if q.Message != nil {
+		chatID = q.Message.Chat.ID
+	}
+
+	var visitType string
+	if h.services != nil {
+		service, err := h.services.GetServiceByID(ctx, serviceID)
+		if errors.Is(err, repository.ErrServiceNotFound) || err == nil && !service.IsActive {
+			h.bot.Send(tgbotapi.NewMessage(chatID, serviceUnavailableMessage))
+			return nil
+		}
+		if err != nil {
+			h.log.Error("get service error", zap.Int("service_id", serviceID), zap.Error(err))
+			return err
+		}
+		if idx, ok := args.Int("idx"); ok {
+			visitType = service.VisitOptions.VisitType(idx)
+		}
+	}
+
+	h.log.Info("booking_form_started",
+		zap.Int64("user_id", q.From.ID),
+		zap.Int("service_id", serviceID),
+		zap.String("visit_type", visitType),
+	)
+
+	// уровень пользователя проверяем сразу, чтобы не заполнять форму впустую
+	err := h.db.CheckBookingAllowed(ctx, q.From.ID, serviceID, visitType)
+	if errors.Is(err, repository.ErrGradeNotAllowed) {
+		h.bot.Send(tgbotapi.NewMessage(chatID, gradeNotAllowedMessage))
+		return nil
+	}
+	if err != nil {
+		h.log.Error("check booking allowed error", zap.Error(err))
+		return err
+	}
+
+	if err := h.Start(ctx, q.From.ID, serviceID, visitType); err != nil {
+		return err
+	}
+	h.sendDateSelection(ctx, chatID, serviceID)
+	return nil
+}
+
+// IsActive сообщает, заполняет ли пользователь форму бронирования.
+func (h *BookingFormHandler) IsActive(ctx context.Context, userID int64) bool {
+	_, ok, err := h.getState(ctx, userID)
+	if err != nil {
+		h.log.Error("get booking state error", zap.Int64("user_id", userID), zap.Error(err))
+		return false
 	}
-	h.mu.Unlock()
+	return ok
 }
 
-func (h *BookingFormHandler) HandleUpdate(update tgbotapi.Update) {
+func (h *BookingFormHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) error {
 	if update.CallbackQuery != nil {
-		h.handleCallback(update.CallbackQuery)
-		return
+		return h.handleCallback(ctx, update.CallbackQuery)
 	}
 
 	if update.Message != nil {
-		h.handleMessage(update.Message)
+		return h.handleMessage(ctx, update.Message)
 	}
+	return nil
 }
 
-func (h *BookingFormHandler) getState(userID int64) (*models.BookingState, bool) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	state, ok := h.store[userID]
-	return state, ok
+func (h *BookingFormHandler) getState(ctx context.Context, userID int64) (*models.BookingState, bool, error) {
+	state, ok, err := h.store.Load(ctx, userID)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &state, true, nil
 }
 
-func (h *BookingFormHandler) setState(userID int64, state *models.BookingState) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	h.store[userID] = state
+func (h *BookingFormHandler) setState(ctx context.Context, userID int64, state *models.BookingState) error {
+	state.UpdatedAt = time.Now()
+	return h.store.Save(ctx, userID, *state)
 }
 
-func (h *BookingFormHandler) clearState(userID int64) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	delete(h.store, userID)
+// expire удаляет истёкшую форму и предлагает начать бронирование заново.
+func (h *BookingFormHandler) expire(ctx context.Context, userID, chatID int64, form *models.BookingState) error {
+	if err := h.clearState(ctx, userID); err != nil {
+		return err
+	}
+	step := strconv.Itoa(form.Step)
+	state.CountAbandoned(metrics.Default, BookingFormState, step)
+	h.log.Info("booking_form_expired",
+		zap.Int64("user_id", userID),
+		zap.Int("service_id", form.ServiceID),
+		zap.String("step", step),
+	)
+
+	h.sendExpired(chatID, form.ServiceID)
+	return nil
 }
 
-func (h *BookingFormHandler) handleCallback(q *tgbotapi.CallbackQuery) {
-	state, ok := h.getState(q.From.ID)
-	if !ok {
+// NotifyExpired сообщает пользователю об истечении формы, которую удалил state.Sweeper:
+// после удаления состояния handleCallback уже не узнает о форме. Сессии других диалогов
+// пропускаются. Регистрируется через state.Sweeper.OnExpired.
+func (h *BookingFormHandler) NotifyExpired(ctx context.Context, session models.UserSession) {
+	if session.CurrentState != BookingFormState {
+		return
+	}
+	form, err := state.Decode[models.BookingState](session)
+	if err != nil {
+		h.log.Error("decode_expired_booking_form_failed", zap.Error(err), zap.Int64("session_user_id", session.UserID))
 		return
 	}
+	if form.UserID == 0 {
+		return
+	}
+
+	h.log.Info("booking_form_expired",
+		zap.Int64("user_id", form.UserID),
+		zap.Int("service_id", form.ServiceID),
+		zap.String("step", strconv.Itoa(form.Step)),
+	)
+	// личный чат с ботом: ID чата совпадает с Telegram ID пользователя
+	h.sendExpired(form.UserID, form.ServiceID)
+}
+
+// sendExpired предлагает начать бронирование услуги заново.
+func (h *BookingFormHandler) sendExpired(chatID int64, serviceID int) {
+	msg := tgbotapi.NewMessage(chatID, bookingExpiredMessage)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Начать заново", fmt.Sprintf("book_now:%d", serviceID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", CallbackBackToMain),
+		),
+	)
+	h.bot.Send(msg)
+}
+
+func (h *BookingFormHandler) clearState(ctx context.Context, userID int64) error {
+	return h.store.Delete(ctx, userID)
+}
+
+func (h *BookingFormHandler) handleCallback(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	state, ok, err := h.getState(ctx, q.From.ID)
+	if err != nil || !ok {
+		return err
+	}
+	// у callback из старого или недоступного сообщения Message не заполнен
+	chatID := q.From.ID
+	if q.Message != nil {
+		chatID = q.Message.Chat.ID
+	}
+	if state.Expired(time.Now(), h.ttl) {
+		return h.expire(ctx, q.From.ID, chatID, state)
+	}
+
+	if q.Data == formCancelData {
+		return h.cancel(ctx, q.From.ID, chatID)
+	}
 
 	switch state.Step {
 	case models.BookingStepSelectDate:
-		date, err := time.Parse("2006-01-02", q.Data)
+		date, waitlist, ok := h.parseDateCallback(q.Data)
+		if !ok {
+			if action, month, ok := datePicker.Parse(q.Data); ok && action == datepicker.ActionMonth {
+				if q.Message == nil {
+					h.sendDateSelection(ctx, chatID, state.ServiceID)
+					return nil
+				}
+				return h.showDateMonth(ctx, chatID, q.Message.MessageID, state.ServiceID, month)
+			}
+			return nil
+		}
+
+		slots, timed, err := h.db.GetAvailableSlots(ctx, state.ServiceID, date)
 		if err != nil {
-			return
+			h.log.Error("get slots error", zap.Error(err))
+			return err
+		}
+
+		var taken []string
+		if timed && h.waitlist != nil {
+			if taken, err = h.waitlist.GetTakenSlots(ctx, state.ServiceID, date); err != nil {
+				h.log.Error("get taken slots error", zap.Error(err))
+				return err
+			}
+		}
+		if timed && len(slots) == 0 && len(taken) == 0 {
+			h.bot.Send(tgbotapi.NewMessage(chatID, "На эту дату свободного времени не осталось, выберите другую дату"))
+			h.sendDateSelection(ctx, chatID, state.ServiceID)
+			return nil
+		}
+		if !timed {
+			// дата могла прийти со старой клавиатуры: сверяем с тем, что предложили бы сейчас
+			offered, err := h.dateOffered(ctx, state.ServiceID, date, waitlist)
+			if err != nil {
+				return err
+			}
+			if !offered {
+				h.bot.Send(tgbotapi.NewMessage(chatID, slotUnavailableMessage))
+				h.sendDateSelection(ctx, chatID, state.ServiceID)
+				return nil
+			}
 		}
 
 		state.SelectedDate = date
+		state.SelectedTime = ""
+		state.Waitlist = waitlist && !timed
 		state.Step = models.BookingStepGuestName
-		h.setState(q.From.ID, state)
+		if timed {
+			state.Step = models.BookingStepSelectTime
+		}
+		if err := h.setState(ctx, q.From.ID, state); err != nil {
+			return err
+		}
+
+		if timed {
+			h.sendTimeSelection(chatID, slots, taken)
+			return nil
+		}
+		if state.Editing {
+			return h.backToConfirmation(ctx, q.From.ID, chatID, state)
+		}
+		text := guestNamePrompt(state)
+		if state.Waitlist {
+			text = "Свободных мест на эту дату нет — заполните заявку, и мы поставим вас в лист ожидания.\n\n" + text
+		}
+		h.ask(chatID, text)
+
+	case models.BookingStepSelectTime:
+		data, waitlist := h.cutWaitlistPrefix(q.Data)
+		if _, err := time.Parse(slotTimeLayout, data); err != nil {
+			return nil
+		}
 
-		msg := tgbotapi.NewMessage(q.Message.Chat.ID, "Введите ФИО:")
-		h.bot.Send(msg)
+		// время сверяем со свежим расписанием: кнопки старых клавиатур остаются в чате
+		slots, _, err := h.db.GetAvailableSlots(ctx, state.ServiceID, state.SelectedDate)
+		if err != nil {
+			h.log.Error("get slots error", zap.Error(err))
+			return err
+		}
+		var taken []string
+		if h.waitlist != nil {
+			if taken, err = h.waitlist.GetTakenSlots(ctx, state.ServiceID, state.SelectedDate); err != nil {
+				h.log.Error("get taken slots error", zap.Error(err))
+				return err
+			}
+		}
+		offered := slots
+		if waitlist {
+			offered = taken
+		}
+		if !slices.Contains(offered, data) {
+			h.log.Info("booking_time_unavailable",
+				zap.Int64("user_id", q.From.ID),
+				zap.Int("service_id", state.ServiceID),
+				zap.String("time", data),
+			)
+			h.bot.Send(tgbotapi.NewMessage(chatID, slotUnavailableMessage))
+			if len(slots) == 0 && len(taken) == 0 {
+				state.Step = models.BookingStepSelectDate
+				if err := h.setState(ctx, q.From.ID, state); err != nil {
+					return err
+				}
+				h.sendDateSelection(ctx, chatID, state.ServiceID)
+				return nil
+			}
+			h.sendTimeSelection(chatID, slots, taken)
+			return nil
+		}
+
+		state.SelectedTime = data
+		state.Waitlist = waitlist
+		if state.Editing {
+			return h.backToConfirmation(ctx, q.From.ID, chatID, state)
+		}
+
+		state.Step = models.BookingStepGuestName
+		if err := h.setState(ctx, q.From.ID, state); err != nil {
+			return err
+		}
+
+		text := guestNamePrompt(state)
+		if waitlist {
+			text = "Это время занято — заполните заявку, и мы поставим вас в лист ожидания.\n\n" + text
+		}
+		h.ask(chatID, text)
+
+	case models.BookingStepGuests:
+		return h.handleGuestsCallback(ctx, q, chatID, state)
 
 	case models.BookingStepConfirm:
-		if q.Data == "confirm_yes" {
-			err := h.db.SaveBooking(context.Background(), state)
+		if q.Data == guestsEditData && state.MaxGuests > 1 {
+			state.Step = models.BookingStepGuests
+			if err := h.setState(ctx, q.From.ID, state); err != nil {
+				return err
+			}
+			h.sendGuestReview(chatID, state)
+			return nil
+		}
+
+		if step, ok := editSteps[q.Data]; ok {
+			return h.editField(ctx, q.From.ID, chatID, state, step)
+		}
+
+		if q.Data == confirmYesData && state.Waitlist {
+			return h.joinWaitlist(ctx, q, chatID, state)
+		}
+
+		if q.Data == confirmYesData {
+			bookingID, err := h.db.SaveBooking(ctx, state)
+			if errors.Is(err, repository.ErrSlotTaken) || errors.Is(err, repository.ErrSlotUnavailable) {
+				// пока пользователь заполнял форму, последнее место заняли или время прошло —
+				// предлагаем другую дату
+				text := slotTakenMessage
+				if errors.Is(err, repository.ErrSlotUnavailable) {
+					text = slotUnavailableMessage
+				}
+				state.Step = models.BookingStepSelectDate
+				state.SelectedTime = ""
+				state.Waitlist = false
+				state.Editing = true
+				if err := h.setState(ctx, q.From.ID, state); err != nil {
+					return err
+				}
+				h.bot.Send(tgbotapi.NewMessage(chatID, text))
+				h.sendDateSelection(ctx, chatID, state.ServiceID)
+				return nil
+			}
+			if errors.Is(err, repository.ErrMonthlyLimitReached) {
+				state.Step = models.BookingStepSelectDate
+				state.SelectedTime = ""
+				state.Editing = true
+				if err := h.setState(ctx, q.From.ID, state); err != nil {
+					return err
+				}
+				h.bot.Send(tgbotapi.NewMessage(chatID, monthlyLimitMessage))
+				h.sendDateSelection(ctx, chatID, state.ServiceID)
+				return nil
+			}
+			if errors.Is(err, repository.ErrGradeNotAllowed) {
+				if err := h.clearState(ctx, q.From.ID); err != nil {
+					return err
+				}
+				h.bot.Send(tgbotapi.NewMessage(chatID, gradeNotAllowedMessage))
+				return nil
+			}
 			if err != nil {
 				h.log.Error("save booking error", zap.Error(err))
-				return
+				return err
 			}
 
-			h.clearState(q.From.ID)
+			if err := h.clearState(ctx, q.From.ID); err != nil {
+				return err
+			}
 
-			msg := tgbotapi.NewMessage(q.Message.Chat.ID, "Готово! Возврат на главную")
+			msg := tgbotapi.NewMessage(chatID, bookingCreatedMessage)
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", CallbackBackToMain),
+			))
 			h.bot.Send(msg)
 
-			// тут можно вызвать функцию возврата в меню
+			for _, hook := range h.onCreated {
+				if err := hook(ctx, bookingID); err != nil {
+					h.log.Error("booking_created_hook_failed", zap.Int64("booking_id", bookingID), zap.Error(err))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// joinWaitlist ставит пользователя в очередь на выбранные в форме дату и время.
+func (h *BookingFormHandler) joinWaitlist(ctx context.Context, q *tgbotapi.CallbackQuery, chatID int64, state *models.BookingState) error {
+	_, err := h.waitlist.JoinWaitlist(ctx, state)
+	if err != nil && !errors.Is(err, repository.ErrAlreadyInWaitlist) {
+		h.log.Error("join waitlist error", zap.Error(err))
+		return err
+	}
+
+	if err := h.clearState(ctx, q.From.ID); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf(waitlistJoinedMessage, int(repository.WaitlistOfferTTL.Minutes()))
+	if err != nil {
+		text = "Вы уже стоите в листе ожидания на это время — мы напишем, как только место освободится."
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", CallbackBackToMain),
+	))
+	h.bot.Send(msg)
+	return nil
+}
+
+// cutWaitlistPrefix снимает пометку листа ожидания с callback data.
+// Без подключённого листа ожидания пометка не принимается.
+func (h *BookingFormHandler) cutWaitlistPrefix(data string) (string, bool) {
+	rest, ok := strings.CutPrefix(data, waitlistPrefix)
+	if ok && h.waitlist == nil {
+		return "", false
+	}
+	return rest, ok
+}
+
+// editSteps — шаг формы для каждой кнопки изменения поля на подтверждении.
+var editSteps = map[string]int{
+	confirmNoData:    models.BookingStepSelectDate,
+	editDateData:     models.BookingStepSelectDate,
+	editNameData:     models.BookingStepGuestName,
+	editOrgData:      models.BookingStepOrg,
+	editPositionData: models.BookingStepPosition,
+}
+
+// editField переводит форму на шаг изменяемого поля; после него форма вернётся к подтверждению.
+func (h *BookingFormHandler) editField(ctx context.Context, userID, chatID int64, state *models.BookingState, step int) error {
+	state.Step = step
+	state.Editing = true
+	if step == models.BookingStepSelectDate {
+		state.SelectedTime = ""
+		state.Waitlist = false
+	}
+	if err := h.setState(ctx, userID, state); err != nil {
+		return err
+	}
+
+	switch step {
+	case models.BookingStepSelectDate:
+		h.sendDateSelection(ctx, chatID, state.ServiceID)
+	case models.BookingStepGuestName:
+		h.ask(chatID, guestNamePrompt(state))
+	case models.BookingStepOrg:
+		h.ask(chatID, "Введите организацию:")
+	case models.BookingStepPosition:
+		h.ask(chatID, "Введите должность:")
+	}
+	return nil
+}
+
+// backToConfirmation завершает изменение поля и снова показывает подтверждение.
+func (h *BookingFormHandler) backToConfirmation(ctx context.Context, userID, chatID int64, state *models.BookingState) error {
+	state.Step = models.BookingStepConfirm
+	state.Editing = false
+	if err := h.setState(ctx, userID, state); err != nil {
+		return err
+	}
+	h.sendConfirmation(chatID, state)
+	return nil
+}
+
+// cancel сбрасывает форму по кнопке «Отмена» на любом шаге.
+func (h *BookingFormHandler) cancel(ctx context.Context, userID, chatID int64) error {
+	if err := h.clearState(ctx, userID); err != nil {
+		return err
+	}
+	h.log.Info("booking_form_cancelled", zap.Int64("user_id", userID))
+
+	msg := tgbotapi.NewMessage(chatID, formCancelledMessage)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", CallbackBackToMain),
+	))
+	h.bot.Send(msg)
+	return nil
+}
+
+// ask отправляет вопрос формы с кнопкой «Отмена».
+func (h *BookingFormHandler) ask(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(cancelRow())
+	h.bot.Send(msg)
+}
+
+func cancelRow() []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Отмена", formCancelData))
+}
+
+// handleGuestsCallback обрабатывает кнопки списка гостей: добавить, изменить, удалить, продолжить.
+func (h *BookingFormHandler) handleGuestsCallback(ctx context.Context, q *tgbotapi.CallbackQuery, chatID int64, state *models.BookingState) error {
+	switch {
+	case q.Data == guestAddData:
+		if len(state.Guests) >= state.MaxGuests {
+			h.sendGuestReview(chatID, state)
+			return nil
+		}
+		state.EditGuest = 0
+		state.Step = models.BookingStepGuestName
+
+	case strings.HasPrefix(q.Data, guestEditData):
+		n, ok := guestNumber(q.Data, guestEditData, state)
+		if !ok {
+			return nil
 		}
+		state.EditGuest = n
+		state.Step = models.BookingStepGuestName
 
-		if q.Data == "confirm_no" {
-			state.Step = models.BookingStepSelectDate
-			h.setState(q.From.ID, state)
-			h.sendDateSelection(q.Message.Chat.ID, state.ServiceID)
+	case strings.HasPrefix(q.Data, guestRemoveData):
+		n, ok := guestNumber(q.Data, guestRemoveData, state)
+		if !ok {
+			return nil
+		}
+		state.Guests = slices.Delete(state.Guests, n-1, n)
+		if len(state.Guests) == 0 {
+			// без гостей бронировать нельзя — сразу просим ввести нового
+			state.EditGuest = 0
+			state.Step = models.BookingStepGuestName
 		}
+
+	case q.Data == guestsDoneData:
+		state.Step = models.BookingStepConfirm
+
+	default:
+		return nil
+	}
+
+	if err := h.setState(ctx, q.From.ID, state); err != nil {
+		return err
+	}
+
+	switch state.Step {
+	case models.BookingStepGuestName:
+		h.ask(chatID, guestNamePrompt(state))
+	case models.BookingStepConfirm:
+		h.sendConfirmation(chatID, state)
+	default:
+		h.sendGuestReview(chatID, state)
 	}
+	return nil
 }
 
-func (h *BookingFormHandler) handleMessage(msg *tgbotapi.Message) {
-	state, ok := h.getState(msg.From.ID)
-	if !ok {
-		return
+// saveGuest переносит заполненного гостя из полей Guest* в список: новый гость
+// добавляется в конец, изменённый заменяет прежнюю запись.
+func saveGuest(state *models.BookingState) {
+	guest := models.BookingGuest{
+		Name:         state.GuestName,
+		Organization: state.GuestOrganization,
+		Position:     state.GuestPosition,
+	}
+	if state.EditGuest > 0 && state.EditGuest <= len(state.Guests) {
+		state.Guests[state.EditGuest-1] = guest
+	} else {
+		state.Guests = append(state.Guests, guest)
+	}
+	state.EditGuest = 0
+}
+
+// guestNumber разбирает номер гостя из callback data и проверяет, что такой гость есть.
+func guestNumber(data, prefix string, state *models.BookingState) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(data, prefix))
+	if err != nil || n < 1 || n > len(state.Guests) {
+		return 0, false
+	}
+	return n, true
+}
+
+// guestNamePrompt возвращает вопрос про ФИО с номером гостя, если гостей может быть несколько.
+func guestNamePrompt(state *models.BookingState) string {
+	if state.MaxGuests <= 1 {
+		return "Введите ФИО:"
+	}
+	n := len(state.Guests) + 1
+	if state.EditGuest > 0 {
+		n = state.EditGuest
+	}
+	return fmt.Sprintf("Гость %d. Введите ФИО:", n)
+}
+
+func (h *BookingFormHandler) handleMessage(ctx context.Context, msg *tgbotapi.Message) error {
+	state, ok, err := h.getState(ctx, msg.From.ID)
+	if err != nil || !ok {
+		return err
+	}
+	if state.Expired(time.Now(), h.ttl) {
+		return h.expire(ctx, msg.From.ID, msg.Chat.ID, state)
 	}
 
 	text := strings.TrimSpace(msg.Text)
+	if strings.EqualFold(text, "отмена") {
+		return h.cancel(ctx, msg.From.ID, msg.Chat.ID)
+	}
 
 	switch state.Step {
 
 	case models.BookingStepGuestName:
 		if len(text) < 3 || len(text) > 100 {
-			h.bot.Send(tgbotapi.NewMessage(msg.Chat.ID,
-				"Ошибка: ФИО должно быть от 3 до 100 символов. Введите снова:"))
-			return
+			h.ask(msg.Chat.ID, "Ошибка: ФИО должно быть от 3 до 100 символов. Введите снова:")
+			return nil
 		}
 
 		state.GuestName = text
+		if state.Editing {
+			return h.backToConfirmation(ctx, msg.From.ID, msg.Chat.ID, state)
+		}
 		state.Step = models.BookingStepOrg
-		h.setState(msg.From.ID, state)
+		if err := h.setState(ctx, msg.From.ID, state); err != nil {
+			return err
+		}
 
-		h.bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Введите организацию:"))
+		h.ask(msg.Chat.ID, "Введите организацию:")
 
 	case models.BookingStepOrg:
 		if len(text) < 2 || len(text) > 255 {
-			h.bot.Send(tgbotapi.NewMessage(msg.Chat.ID,
-				"Ошибка: организация должна быть от 2 до 255 символов. Введите снова:"))
-			return
+			h.ask(msg.Chat.ID, "Ошибка: организация должна быть от 2 до 255 символов. Введите снова:")
+			return nil
 		}
 
 		state.GuestOrganization = text
+		if state.Editing {
+			return h.backToConfirmation(ctx, msg.From.ID, msg.Chat.ID, state)
+		}
 		state.Step = models.BookingStepPosition
-		h.setState(msg.From.ID, state)
+		if err := h.setState(ctx, msg.From.ID, state); err != nil {
+			return err
+		}
 
-		h.bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Введите должность:"))
+		h.ask(msg.Chat.ID, "Введите должность:")
 
 	case models.BookingStepPosition:
 		if len(text) < 2 || len(text) > 100 {
-			h.bot.Send(tgbotapi.NewMessage(msg.Chat.ID,
-				"Ошибка: должность должна быть от 2 до 100 символов. Введите снова:"))
-			return
+			h.ask(msg.Chat.ID, "Ошибка: должность должна быть от 2 до 100 символов. Введите снова:")
+			return nil
 		}
 
 		state.GuestPosition = text
-		state.Step = models.BookingStepConfirm
-		h.setState(msg.From.ID, state)
+		if state.MaxGuests <= 1 {
+			return h.backToConfirmation(ctx, msg.From.ID, msg.Chat.ID, state)
+		}
 
-		h.sendConfirmation(msg.Chat.ID, state)
+		saveGuest(state)
+		state.Step = models.BookingStepGuests
+		if err := h.setState(ctx, msg.From.ID, state); err != nil {
+			return err
+		}
+		h.sendGuestReview(msg.Chat.ID, state)
 	}
+	return nil
 }
 
-func (h *BookingFormHandler) sendDateSelection(chatID int64, serviceID int) {
-	dates, err := h.db.GetAvailableDates(context.Background(), serviceID)
+func (h *BookingFormHandler) sendDateSelection(ctx context.Context, chatID int64, serviceID int) {
+	if h.db == nil {
+		h.log.Error("booking repository is not configured")
+		h.bot.Send(tgbotapi.NewMessage(chatID, "Бронирование временно недоступно, попробуйте позже"))
+		return
+	}
+
+	cal, dates, hasFull, err := h.dateCalendar(ctx, serviceID)
 	if err != nil {
 		h.log.Error("get dates error", zap.Error(err))
 		return
 	}
+	if len(dates) == 0 {
+		h.ask(chatID, "Свободных дат на ближайшие две недели нет, попробуйте позже")
+		return
+	}
 
-	var buttons [][]tgbotapi.InlineKeyboardButton
-	for _, d := range dates {
-		dateStr := d.Format("2006-01-02")
-		btn := tgbotapi.NewInlineKeyboardButtonData(dateStr, dateStr)
-		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(btn))
+	text := "Выберите дату:"
+	if hasFull {
+		text = "Выберите дату. На заполненные дни (🔒) можно встать в лист ожидания:"
 	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = dateKeyboard(cal, dates[0])
 
-	msg := tgbotapi.NewMessage(chatID, "Выберите дату:")
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons...)
+	h.bot.Send(msg)
+}
+
+// showDateMonth переключает календарь в сообщении на другой месяц.
+func (h *BookingFormHandler) showDateMonth(ctx context.Context, chatID int64, messageID, serviceID int, month time.Time) error {
+	cal, _, _, err := h.dateCalendar(ctx, serviceID)
+	if err != nil {
+		h.log.Error("get dates error", zap.Error(err))
+		return err
+	}
+
+	h.bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, dateKeyboard(cal, month)))
+	return nil
+}
+
+// dateCalendar собирает календарь по свободным и, если подключён лист ожидания, заполненным
+// датам услуги. dates — все даты, которые можно выбрать, по возрастанию.
+func (h *BookingFormHandler) dateCalendar(ctx context.Context, serviceID int) (cal datepicker.Calendar, dates []time.Time, hasFull bool, err error) {
+	free, err := h.db.GetAvailableDates(ctx, serviceID)
+	if err != nil {
+		return cal, nil, false, err
+	}
+
+	var full []time.Time
+	if h.waitlist != nil {
+		if full, err = h.waitlist.GetFullDates(ctx, serviceID); err != nil {
+			return cal, nil, false, err
+		}
+	}
+
+	dates = mergeDates(free, full)
+	return newDatePicker(free, full, dates), dates, len(full) > 0, nil
+}
+
+// datePicker разбирает callback data календаря формы бронирования.
+var datePicker = datepicker.Calendar{Prefix: datePickerPrefix}
+
+const datePickerPrefix = "bd"
+
+// newDatePicker возвращает календарь, в котором можно выбрать только даты из free и full;
+// даты из full помечены замком.
+func newDatePicker(free, full, dates []time.Time) datepicker.Calendar {
+	cal := datePicker
+	if len(dates) > 0 {
+		cal.Min, cal.Max = dates[0], dates[len(dates)-1]
+	}
+	cal.Day = func(date time.Time) datepicker.DayState {
+		switch {
+		case slices.ContainsFunc(free, sameDay(date)):
+			return datepicker.DayAvailable
+		case slices.ContainsFunc(full, sameDay(date)):
+			return datepicker.DayMarked
+		default:
+			return datepicker.DayDisabled
+		}
+	}
+	return cal
+}
+
+func dateKeyboard(cal datepicker.Calendar, month time.Time) tgbotapi.InlineKeyboardMarkup {
+	kb := cal.Keyboard(month)
+	kb.InlineKeyboard = append(kb.InlineKeyboard, cancelRow())
+	return kb
+}
+
+func sameDay(date time.Time) func(time.Time) bool {
+	return func(d time.Time) bool {
+		return d.Year() == date.Year() && d.Month() == date.Month() && d.Day() == date.Day()
+	}
+}
+
+// dateOffered сообщает, есть ли дата среди свободных или, для листа ожидания, заполненных
+// дат услуги на ближайшие дни.
+func (h *BookingFormHandler) dateOffered(ctx context.Context, serviceID int, date time.Time, waitlist bool) (bool, error) {
+	var dates []time.Time
+	var err error
+	if waitlist {
+		dates, err = h.waitlist.GetFullDates(ctx, serviceID)
+	} else {
+		dates, err = h.db.GetAvailableDates(ctx, serviceID)
+	}
+	if err != nil {
+		h.log.Error("get dates error", zap.Error(err), zap.Int("service_id", serviceID))
+		return false, err
+	}
+	return slices.ContainsFunc(dates, sameDay(date)), nil
+}
+
+// parseDateCallback разбирает выбор даты: из календаря или с кнопок прежнего вида
+// "2006-01-02" и "wl:2006-01-02", которые могли остаться в чатах.
+func (h *BookingFormHandler) parseDateCallback(data string) (date time.Time, waitlist, ok bool) {
+	switch action, date, ok := datePicker.Parse(data); {
+	case ok && action == datepicker.ActionSelect:
+		return date, false, true
+	case ok && action == datepicker.ActionSelectMarked:
+		return date, h.waitlist != nil, true
+	case ok:
+		return time.Time{}, false, false
+	}
+
+	data, waitlist = h.cutWaitlistPrefix(data)
+	date, err := time.Parse("2006-01-02", data)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	return date, waitlist, true
+}
+
+// sendTimeSelection показывает свободные слоты и, если подключён лист ожидания, занятые
+// слоты с замком — на них можно встать в очередь.
+func (h *BookingFormHandler) sendTimeSelection(chatID int64, slots, taken []string) {
+	all := append(slices.Clone(slots), taken...)
+	slices.Sort(all)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(all); i += slotsPerRow {
+		end := min(i+slotsPerRow, len(all))
+
+		var row []tgbotapi.InlineKeyboardButton
+		for _, slot := range all[i:end] {
+			btn := tgbotapi.NewInlineKeyboardButtonData(slot, slot)
+			if slices.Contains(taken, slot) {
+				btn = tgbotapi.NewInlineKeyboardButtonData("🔒 "+slot, waitlistPrefix+slot)
+			}
+			row = append(row, btn)
+		}
+		rows = append(rows, row)
+	}
+
+	text := "Выберите время:"
+	if len(taken) > 0 {
+		text = "Выберите время. На занятое (🔒) можно встать в лист ожидания:"
+	}
+	rows = append(rows, cancelRow())
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	h.bot.Send(msg)
+}
+
+// sendGuestReview показывает введённых гостей с кнопками изменения и удаления.
+func (h *BookingFormHandler) sendGuestReview(chatID int64, state *models.BookingState) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := range state.Guests {
+		n := i + 1
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✏️ Гость %d", n), fmt.Sprintf("%s%d", guestEditData, n)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить", fmt.Sprintf("%s%d", guestRemoveData, n)),
+		))
+	}
+	if len(state.Guests) < state.MaxGuests {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➕ Добавить гостя", guestAddData),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Далее", guestsDoneData),
+	), cancelRow())
+
+	text := fmt.Sprintf("Гости (%d из %d):\n\n%s", len(state.Guests), state.MaxGuests, formatGuests(state.Guests))
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
 
 	h.bot.Send(msg)
 }
 
+// formatGuests нумерует гостей: ФИО, организация, должность.
+func formatGuests(guests []models.BookingGuest) string {
+	lines := make([]string, 0, len(guests))
+	for i, g := range guests {
+		line := fmt.Sprintf("%d. %s", i+1, g.Name)
+		for _, extra := range []string{g.Organization, g.Position} {
+			if extra != "" {
+				line += ", " + extra
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (h *BookingFormHandler) sendConfirmation(chatID int64, state *models.BookingState) {
+	when := state.SelectedDate.Format("02.01.2006")
+	if state.SelectedTime != "" {
+		when += " " + state.SelectedTime
+	}
+
+	title := "Подтвердите бронирование:"
+	if state.Waitlist {
+		title = "Подтвердите запись в лист ожидания:"
+	}
+
 	text := fmt.Sprintf(
-		"Подтвердите бронирование:\n\nДата: %s\nФИО: %s\nОрганизация: %s\nДолжность: %s",
-		state.SelectedDate.Format("02.01.2006"),
+		"%s\n\nДата: %s\nФИО: %s\nОрганизация: %s\nДолжность: %s",
+		title,
+		when,
 		state.GuestName,
 		state.GuestOrganization,
 		state.GuestPosition,
 	)
-
-	yes := tgbotapi.NewInlineKeyboardButtonData("Подтвердить", "confirm_yes")
-	no := tgbotapi.NewInlineKeyboardButtonData("Изменить дату", "confirm_no")
+	if len(state.Guests) > 0 {
+		text = fmt.Sprintf("%s\n\nДата: %s\nГости:\n%s", title, when, formatGuests(state.Guests))
+	}
 
 	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(yes, no),
-	)
+	msg.ReplyMarkup = confirmationKeyboard(state)
 
 	h.bot.Send(msg)
-}
\ No newline at end of file
+}
+
+// confirmationKeyboard собирает кнопки подтверждения: отправку заявки, изменение
+// отдельных полей и отмену. При нескольких гостях их данные меняются на шаге списка гостей.
+func confirmationKeyboard(state *models.BookingState) tgbotapi.InlineKeyboardMarkup {
+	yes := tgbotapi.NewInlineKeyboardButtonData("Подтвердить", confirmYesData)
+	if state.Waitlist {
+		yes = tgbotapi.NewInlineKeyboardButtonData("Встать в очередь", confirmYesData)
+	}
+	date := tgbotapi.NewInlineKeyboardButtonData("📅 Дата", editDateData)
+
+	rows := [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(yes)}
+	if state.MaxGuests > 1 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			date,
+			tgbotapi.NewInlineKeyboardButtonData("👥 Изменить гостей", guestsEditData),
+		))
+	} else {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				date,
+				tgbotapi.NewInlineKeyboardButtonData("👤 ФИО", editNameData),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🏢 Организация", editOrgData),
+				tgbotapi.NewInlineKeyboardButtonData("💼 Должность", editPositionData),
+			),
+		)
+	}
+	rows = append(rows, cancelRow())
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// mergeDates объединяет свободные и заполненные даты в порядке возрастания.
+func mergeDates(free, full []time.Time) []time.Time {
+	dates := append(slices.Clone(free), full...)
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+	return dates
+}
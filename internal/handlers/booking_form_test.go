@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yandex-development-2-team/Go/internal/datepicker"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+func TestSaveGuest_AppendsAndReplaces(t *testing.T) {
+	state := &models.BookingState{MaxGuests: 3, GuestName: "Иван Иванов", GuestOrganization: "Яндекс"}
+	saveGuest(state)
+
+	state.GuestName, state.GuestOrganization, state.GuestPosition = "Пётр Петров", "", "Дизайнер"
+	saveGuest(state)
+	assert.Len(t, state.Guests, 2)
+
+	// изменение первого гостя не добавляет нового
+	state.EditGuest = 1
+	state.GuestName, state.GuestOrganization, state.GuestPosition = "Иван Сидоров", "Яндекс", "Менеджер"
+	saveGuest(state)
+
+	assert.Equal(t, []models.BookingGuest{
+		{Name: "Иван Сидоров", Organization: "Яндекс", Position: "Менеджер"},
+		{Name: "Пётр Петров", Position: "Дизайнер"},
+	}, state.Guests)
+	assert.Zero(t, state.EditGuest)
+	assert.Equal(t, "Гость 3. Введите ФИО:", guestNamePrompt(state))
+}
+
+func TestGuestNumber(t *testing.T) {
+	state := &models.BookingState{Guests: make([]models.BookingGuest, 2)}
+
+	n, ok := guestNumber("guest_remove:2", guestRemoveData, state)
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+
+	for _, data := range []string{"guest_remove:0", "guest_remove:3", "guest_remove:x"} {
+		_, ok := guestNumber(data, guestRemoveData, state)
+		assert.False(t, ok, data)
+	}
+}
+
+func TestFormatGuests(t *testing.T) {
+	text := formatGuests([]models.BookingGuest{
+		{Name: "Иван Иванов", Organization: "Яндекс", Position: "Разработчик"},
+		{Name: "Пётр Петров"},
+	})
+	assert.Equal(t, "1. Иван Иванов, Яндекс, Разработчик\n2. Пётр Петров", text)
+}
+
+func TestConfirmationKeyboard(t *testing.T) {
+	data := func(kb tgbotapi.InlineKeyboardMarkup) [][]string {
+		var rows [][]string
+		for _, row := range kb.InlineKeyboard {
+			var r []string
+			for _, btn := range row {
+				r = append(r, *btn.CallbackData)
+			}
+			rows = append(rows, r)
+		}
+		return rows
+	}
+
+	assert.Equal(t, [][]string{
+		{confirmYesData},
+		{editDateData, editNameData},
+		{editOrgData, editPositionData},
+		{formCancelData},
+	}, data(confirmationKeyboard(&models.BookingState{MaxGuests: 1})))
+
+	assert.Equal(t, [][]string{
+		{confirmYesData},
+		{editDateData, guestsEditData},
+		{formCancelData},
+	}, data(confirmationKeyboard(&models.BookingState{MaxGuests: 4})))
+}
+
+func TestEditSteps(t *testing.T) {
+	assert.Equal(t, models.BookingStepSelectDate, editSteps[confirmNoData])
+	assert.Equal(t, models.BookingStepSelectDate, editSteps[editDateData])
+	assert.Equal(t, models.BookingStepGuestName, editSteps[editNameData])
+	assert.Equal(t, models.BookingStepOrg, editSteps[editOrgData])
+	assert.Equal(t, models.BookingStepPosition, editSteps[editPositionData])
+}
+
+func TestNewDatePicker(t *testing.T) {
+	free := []time.Time{time.Date(2026, 4, 28, 0, 0, 0, 0, time.UTC)}
+	full := []time.Time{time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)}
+	cal := newDatePicker(free, full, mergeDates(free, full))
+
+	assert.Equal(t, datepicker.DayAvailable, cal.Day(free[0]))
+	assert.Equal(t, datepicker.DayMarked, cal.Day(full[0]))
+	assert.Equal(t, datepicker.DayDisabled, cal.Day(time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, free[0], cal.Min)
+	assert.Equal(t, full[0], cal.Max)
+}
+
+func TestParseDateCallback(t *testing.T) {
+	h := &BookingFormHandler{waitlist: &fakeBookingWaitlist{}}
+	want := time.Date(2026, 4, 28, 0, 0, 0, 0, time.UTC)
+
+	for data, waitlist := range map[string]bool{
+		"bd:d:260428":   false,
+		"bd:m:260428":   true,
+		"2026-04-28":    false,
+		"wl:2026-04-28": true,
+	} {
+		date, wl, ok := h.parseDateCallback(data)
+		assert.True(t, ok, data)
+		assert.Equal(t, want, date, data)
+		assert.Equal(t, waitlist, wl, data)
+	}
+
+	for _, data := range []string{"bd:n:2605", "bd:-", "confirm_yes"} {
+		_, _, ok := h.parseDateCallback(data)
+		assert.False(t, ok, data)
+	}
+}
+
+// fakeBookingWaitlist нужен там, где важно только, что лист ожидания подключён.
+type fakeBookingWaitlist struct{ BookingWaitlist }
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// BotSender реализует MessageSender поверх Telegram Bot API.
+// Сообщение отправляется в личный чат пользователя (chat_id совпадает с user_id).
+type BotSender struct {
+	bot *tgbotapi.BotAPI
+}
+
+func NewBotSender(bot *tgbotapi.BotAPI) *BotSender {
+	return &BotSender{bot: bot}
+}
+
+func (s *BotSender) SendMessage(userID int64, text string, buttons [][]Button) error {
+	msg := tgbotapi.NewMessage(userID, text)
+	if len(buttons) > 0 {
+		msg.ReplyMarkup = inlineKeyboard(buttons)
+	}
+	_, err := s.bot.Send(msg)
+	return err
+}
+
+// SendDocument отправляет файл пользователю.
+func (s *BotSender) SendDocument(userID int64, name string, data []byte, caption string) error {
+	doc := tgbotapi.NewDocument(userID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	doc.Caption = caption
+	_, err := s.bot.Send(doc)
+	return err
+}
+
+// SendPhoto отправляет фотографию с подписью и кнопками.
+func (s *BotSender) SendPhoto(userID int64, photo models.ServicePhoto, caption string, buttons [][]Button) (SentPhoto, error) {
+	msg := tgbotapi.NewPhoto(userID, photoFile(photo))
+	msg.Caption = caption
+	if len(buttons) > 0 {
+		msg.ReplyMarkup = inlineKeyboard(buttons)
+	}
+	sent, err := s.bot.Send(msg)
+	if err != nil {
+		return SentPhoto{}, err
+	}
+	return SentPhoto{FileID: largestPhotoID(sent), MessageID: sent.MessageID}, nil
+}
+
+// SendMediaGroup отправляет фотографии альбомом, подпись — у первой. Возвращает фотографии
+// в порядке отправки.
+func (s *BotSender) SendMediaGroup(userID int64, photos []models.ServicePhoto, caption string) ([]SentPhoto, error) {
+	if len(photos) == 0 {
+		return nil, errors.New("empty media group")
+	}
+	media := make([]interface{}, 0, len(photos))
+	for i, p := range photos {
+		item := tgbotapi.NewInputMediaPhoto(photoFile(p))
+		if i == 0 {
+			item.Caption = caption
+		}
+		media = append(media, item)
+	}
+	sent, err := s.bot.SendMediaGroup(tgbotapi.NewMediaGroup(userID, media))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SentPhoto, len(sent))
+	for i, m := range sent {
+		out[i] = SentPhoto{FileID: largestPhotoID(m), MessageID: m.MessageID}
+	}
+	return out, nil
+}
+
+// SendText отправляет сообщение с кнопками и возвращает его ID.
+func (s *BotSender) SendText(userID int64, text string, buttons [][]Button) (int, error) {
+	msg := tgbotapi.NewMessage(userID, text)
+	if len(buttons) > 0 {
+		msg.ReplyMarkup = inlineKeyboard(buttons)
+	}
+	sent, err := s.bot.Send(msg)
+	if err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
+
+// DeleteMessages удаляет сообщения из чата пользователя. Пытается удалить все
+// и возвращает первую ошибку.
+func (s *BotSender) DeleteMessages(userID int64, messageIDs []int) error {
+	var first error
+	for _, id := range messageIDs {
+		if _, err := s.bot.Request(tgbotapi.NewDeleteMessage(userID, id)); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// photoFile возвращает уже загруженную в Telegram фотографию по file_id или файл с диска.
+func photoFile(p models.ServicePhoto) tgbotapi.RequestFileData {
+	if p.FileID != "" {
+		return tgbotapi.FileID(p.FileID)
+	}
+	return tgbotapi.FilePath(p.Path)
+}
+
+// largestPhotoID возвращает file_id самого крупного размера фотографии из сообщения.
+func largestPhotoID(m tgbotapi.Message) string {
+	if len(m.Photo) == 0 {
+		return ""
+	}
+	return m.Photo[len(m.Photo)-1].FileID
+}
+
+// inlineKeyboard преобразует кнопки в inline-клавиатуру Telegram.
+func inlineKeyboard(buttons [][]Button) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(buttons))
+	for _, row := range buttons {
+		var r []tgbotapi.InlineKeyboardButton
+		for _, b := range row {
+			r = append(r, tgbotapi.NewInlineKeyboardButtonData(b.Text, b.CallbackData))
+		}
+		rows = append(rows, r)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
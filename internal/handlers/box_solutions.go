@@ -2,98 +2,301 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/yandex-development-2-team/Go/internal/database/repository"
 	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/pager"
 )
 
 const (
 	callback     = "box_"
 	callbackMenu = "box_solutions"
+
+	CallbackBackToBoxSolutions = "back_to_box_solutions"
+
+	// CallbackBoxCategories — страницы списка категорий: "boxc:<страница>[:<категория>]".
+	CallbackBoxCategories = "boxc"
+	// CallbackBoxServices — страницы услуг категории: "boxs:<категория>:<страница>[:<услуга>]".
+	CallbackBoxServices = "boxs"
 )
 
+// boxSolutionsPerPage — сколько категорий или услуг помещается на одну страницу меню.
+const boxSolutionsPerPage = 6
+
+var categoriesPager = pager.Pager{Prefix: CallbackBoxCategories, PerPage: boxSolutionsPerPage}
+
+// servicesPager возвращает список услуг категории; ID категории входит в префикс,
+// чтобы страница и кнопка «Назад» знали, к какой категории относятся.
+func servicesPager(categoryID int) pager.Pager {
+	return pager.Pager{
+		Prefix:  CallbackBoxServices + ":" + strconv.Itoa(categoryID),
+		PerPage: boxSolutionsPerPage,
+	}
+}
+
+// BoxSolutionsCatalog — каталог коробочных решений по категориям (см. repository.ServiceRepository).
+type BoxSolutionsCatalog interface {
+	ServiceCatalog
+	GetBoxSolutionCategories(ctx context.Context, telegramID int64) ([]models.ServiceCategory, error)
+	GetServicesOfCategory(ctx context.Context, telegramID int64, categoryID int) ([]models.Service, error)
+	IsServiceVisible(ctx context.Context, telegramID int64, serviceID int) (bool, error)
+}
+
+// BoxSolutionsHandler показывает каталог в два уровня: категории, затем услуги категории,
+// оба списка — по страницам.
 type BoxSolutionsHandler struct {
 	bot      *tgbotapi.BotAPI
 	logger   *zap.Logger
-	services *repository.ServiceRepository
+	services BoxSolutionsCatalog
+	cards    *ServiceCards
 }
 
-func NewBoxSolutionsHandler(bot *tgbotapi.BotAPI, logger *zap.Logger, services *repository.ServiceRepository) *BoxSolutionsHandler {
+// NewBoxSolutionsHandler создаёт обработчик каталога. cards отправляет карточки с фотографиями;
+// nil — карточки всегда текстовые.
+func NewBoxSolutionsHandler(bot *tgbotapi.BotAPI, logger *zap.Logger, services BoxSolutionsCatalog, cards *ServiceCards) *BoxSolutionsHandler {
 	return &BoxSolutionsHandler{
 		bot:      bot,
 		logger:   logger,
 		services: services,
+		cards:    cards,
 	}
 }
 
 func (h *BoxSolutionsHandler) Handle(ctx context.Context, query *tgbotapi.CallbackQuery) error {
 	data := query.Data
 	userID := query.From.ID
-	chatID := query.Message.Chat.ID
-	messageID := query.Message.MessageID
 
-	if data == callbackMenu {
-		h.logger.Info("box_solutions_menu_opened", zap.Int64("user_id", userID), zap.Int64("chat_id", chatID))
+	if data == callbackMenu || data == CallbackBackToBoxSolutions {
+		return h.showCategories(ctx, query, 1)
+	}
 
-		services, err := h.services.GetServicesOfBoxSolutions(ctx)
+	if page, value, ok := categoriesPager.Parse(data); ok {
+		if page == 0 {
+			return nil // счётчик страниц
+		}
+		if value == "" {
+			return h.showCategories(ctx, query, page)
+		}
+		categoryID, err := strconv.Atoi(value)
 		if err != nil {
-			h.logger.Error("failed_to_get_services", zap.Error(err))
+			h.logger.Error("invalid_category_id", zap.String("data", data), zap.Error(err))
 			return err
 		}
+		return h.showServices(ctx, query, categoryID, 1)
+	}
 
-		var rows [][]tgbotapi.InlineKeyboardButton
+	if categoryID, ok := parseServicesCategory(data); ok {
+		list := servicesPager(categoryID)
+		page, value, ok := list.Parse(data)
+		switch {
+		case !ok:
+			h.logger.Error("invalid_box_services_data", zap.String("data", data))
+			return nil
+		case page == 0:
+			return nil
+		case value == "":
+			return h.showServices(ctx, query, categoryID, page)
+		}
+		serviceID, err := strconv.Atoi(value)
+		if err != nil {
+			h.logger.Error("invalid_service_id", zap.String("data", data), zap.Error(err))
+			return err
+		}
+		return h.openService(ctx, userID, serviceID, list.PageData(page))
+	}
 
-		for _, svc := range services {
-			callbackData := fmt.Sprintf("%s%d", callback, svc.ID)
-			button := tgbotapi.NewInlineKeyboardButtonData(svc.Title, callbackData)
-			rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+	if strings.HasPrefix(data, callback) {
+		// ID приходит из маршрута "box_{id:int}"; при регистрации по префиксу разбираем data сами.
+		// Такие кнопки остались в старых сообщениях с плоским списком услуг.
+		serviceID, ok := CallbackArgsFromContext(ctx).Int("id")
+		if !ok {
+			var err error
+			serviceID, err = strconv.Atoi(strings.TrimPrefix(data, callback))
+			if err != nil {
+				h.logger.Error("invalid_service_id", zap.String("data", data), zap.Error(err))
+				return err
+			}
 		}
+		return h.openService(ctx, userID, serviceID, "")
+	}
 
-		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Назад", CallbackBackToMain),
-		))
+	if data == CallbackBackToMain {
+		return NewMainMenuHandler(h.bot, h.logger).Handle(ctx, query)
+	}
 
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return nil
+}
 
-		text := "📦 *Коробочные решения*\n\n" +
-			"Выберите интересующее вас предложение:"
+// showCategories показывает страницу списка категорий.
+func (h *BoxSolutionsHandler) showCategories(ctx context.Context, query *tgbotapi.CallbackQuery, page int) error {
+	userID := query.From.ID
+	h.logger.Info("box_solutions_menu_opened", zap.Int64("user_id", userID), zap.Int64("chat_id", query.Message.Chat.ID), zap.Int("page", page))
 
-		message := tgbotapi.NewEditMessageTextAndMarkup(
-			chatID,
-			messageID,
-			text,
-			keyboard,
-		)
-		message.ParseMode = "Markdown"
+	categories, err := h.services.GetBoxSolutionCategories(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed_to_get_categories", zap.Error(err))
+		return err
+	}
+
+	text, keyboard := categoriesMenu(categories, page)
+	return h.show(query, text, keyboard)
+}
+
+// showServices показывает страницу услуг категории.
+func (h *BoxSolutionsHandler) showServices(ctx context.Context, query *tgbotapi.CallbackQuery, categoryID, page int) error {
+	userID := query.From.ID
+	h.logger.Info("box_category_opened", zap.Int64("user_id", userID), zap.Int("category_id", categoryID), zap.Int("page", page))
+
+	categories, err := h.services.GetBoxSolutionCategories(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed_to_get_categories", zap.Error(err))
+		return err
+	}
+	idx := categoryIndex(categories, categoryID)
+	if idx < 0 {
+		// категорию убрали или в ней не осталось видимых услуг — возвращаем к списку категорий
+		h.logger.Info("box_category_unavailable", zap.Int64("user_id", userID), zap.Int("category_id", categoryID))
+		return h.showCategories(ctx, query, 1)
+	}
+
+	services, err := h.services.GetServicesOfCategory(ctx, userID, categoryID)
+	if err != nil {
+		h.logger.Error("failed_to_get_services", zap.Error(err), zap.Int("category_id", categoryID))
+		return err
+	}
 
+	text, keyboard := servicesMenu(categories[idx], services, page, categoriesPager.PageOf(idx))
+	return h.show(query, text, keyboard)
+}
+
+// openService отправляет карточку услуги. back — куда ведёт «Назад» на карточке.
+func (h *BoxSolutionsHandler) openService(ctx context.Context, userID int64, serviceID int, back string) error {
+	visible, err := h.services.IsServiceVisible(ctx, userID, serviceID)
+	if err != nil {
+		h.logger.Error("failed_to_check_service_visibility", zap.Error(err), zap.Int("service_id", serviceID))
+		return err
+	}
+	if back == "" {
+		back = CallbackBackToBoxSolutions
+	}
+	if !visible {
+		h.logger.Info("service_hidden_for_grade", zap.Int64("user_id", userID), zap.Int("service_id", serviceID))
+		return Sender.SendMessage(userID, "Эта услуга недоступна для вашего уровня.", [][]Button{
+			{{Text: "Назад", CallbackData: back}},
+		})
+	}
+
+	h.logger.Info("service_selected", zap.Int64("user_id", userID), zap.Int("service_id", serviceID))
+
+	err = HandleServiceDetail(ctx, h.services, h.cards, serviceID, userID, back)
+	if errors.Is(err, ErrServiceNotFound) {
+		h.logger.Info("service_unavailable", zap.Int64("user_id", userID), zap.Int("service_id", serviceID))
+		return sendServiceUnavailable(userID, back)
+	}
+	return err
+}
+
+// show заменяет сообщение с кнопкой, на которую нажали, страницей меню.
+func (h *BoxSolutionsHandler) show(query *tgbotapi.CallbackQuery, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	userID := query.From.ID
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	// «Назад» с карточки-фотографии: текст у фото не отредактировать, поэтому карточку
+	// удаляем и присылаем меню новым сообщением
+	if len(query.Message.Photo) > 0 {
+		if _, err := h.bot.Request(tgbotapi.NewDeleteMessage(chatID, messageID)); err != nil {
+			h.logger.Warn("failed_to_delete_service_card", zap.Error(err), zap.Int64("user_id", userID), zap.Int("message_id", messageID))
+		}
+		message := tgbotapi.NewMessage(chatID, text)
+		message.ParseMode = "Markdown"
+		message.ReplyMarkup = keyboard
 		if _, err := h.bot.Send(message); err != nil {
-			h.logger.Error("failed_to_edit_box_solutions_message", zap.Error(err), zap.Int64("user_id", userID), zap.Int("message_id", messageID))
+			h.logger.Error("failed_to_send_box_solutions_message", zap.Error(err), zap.Int64("user_id", userID))
 			return err
 		}
 		return nil
 	}
 
-	if strings.HasPrefix(data, callback) {
+	// кнопки карточки-альбома — отдельное сообщение, его редактируем, а альбом над ним убираем
+	h.cards.Dismiss(chatID, messageID)
 
-		serviceIDStr := strings.TrimPrefix(data, callback)
-		serviceID, err := strconv.Atoi(serviceIDStr)
-		if err != nil {
-			h.logger.Error("invalid_service_id", zap.String("data", data), zap.Error(err))
-			return err
-		}
+	message := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	message.ParseMode = "Markdown"
 
-		h.logger.Info("service_selected", zap.Int64("user_id", userID), zap.Int("service_id", serviceID))
+	if _, err := h.bot.Send(message); err != nil {
+		h.logger.Error("failed_to_edit_box_solutions_message", zap.Error(err), zap.Int64("user_id", userID), zap.Int("message_id", messageID))
+		return err
+	}
+	return nil
+}
 
-		return HandleServiceDetail(serviceID, userID)
+// categoriesMenu формирует страницу списка категорий.
+func categoriesMenu(categories []models.ServiceCategory, page int) (string, tgbotapi.InlineKeyboardMarkup) {
+	items := make([]pager.Item, 0, len(categories))
+	for _, c := range categories {
+		items = append(items, pager.Item{
+			Text:  fmt.Sprintf("%s (%d)", c.Title, c.Services),
+			Value: strconv.Itoa(c.ID),
+		})
 	}
 
-	if data == CallbackBackToMain {
-		return NewMainMenuHandler(h.bot, h.logger).Handle(ctx, query)
+	text := "📦 *Коробочные решения*\n\n" +
+		"Выберите категорию:"
+	if len(categories) == 0 {
+		text = "📦 *Коробочные решения*\n\n" +
+			"Сейчас нет доступных предложений."
 	}
 
-	return nil
+	back := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Назад", CallbackBackToMain))
+	return text, categoriesPager.Keyboard(items, page, back)
+}
+
+// servicesMenu формирует страницу услуг категории. categoriesPage — страница списка категорий,
+// на которую ведёт «Назад».
+func servicesMenu(category models.ServiceCategory, services []models.Service, page, categoriesPage int) (string, tgbotapi.InlineKeyboardMarkup) {
+	items := make([]pager.Item, 0, len(services))
+	for _, svc := range services {
+		items = append(items, pager.Item{Text: svc.Title, Value: strconv.Itoa(svc.ID)})
+	}
+
+	text := "📦 *" + markdownEscaper.Replace(category.Title) + "*\n\n" +
+		"Выберите интересующее вас предложение:"
+
+	back := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Назад", categoriesPager.PageData(categoriesPage)),
+	)
+	return text, servicesPager(category.ID).Keyboard(items, page, back)
+}
+
+// parseServicesCategory достаёт ID категории из callback data списка услуг.
+func parseServicesCategory(data string) (int, bool) {
+	rest, ok := strings.CutPrefix(data, CallbackBoxServices+":")
+	if !ok {
+		return 0, false
+	}
+	id, _, _ := strings.Cut(rest, ":")
+	categoryID, err := strconv.Atoi(id)
+	if err != nil || categoryID < 0 {
+		return 0, false
+	}
+	return categoryID, true
+}
+
+// markdownEscaper экранирует спецсимволы Markdown в названиях из каталога.
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+func categoryIndex(categories []models.ServiceCategory, categoryID int) int {
+	for i, c := range categories {
+		if c.ID == categoryID {
+			return i
+		}
+	}
+	return -1
 }
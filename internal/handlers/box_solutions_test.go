@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+func keyboardData(kb tgbotapi.InlineKeyboardMarkup) [][]string {
+	var rows [][]string
+	for _, row := range kb.InlineKeyboard {
+		var r []string
+		for _, btn := range row {
+			r = append(r, *btn.CallbackData)
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+func TestCategoriesMenu_Paginated(t *testing.T) {
+	var categories []models.ServiceCategory
+	for i := 1; i <= boxSolutionsPerPage+2; i++ {
+		categories = append(categories, models.ServiceCategory{ID: i, Title: fmt.Sprintf("Категория %d", i), Services: i})
+	}
+
+	_, kb := categoriesMenu(categories, 2)
+	rows := keyboardData(kb)
+	if len(rows) != 2+1+1 {
+		t.Fatalf("expected 2 categories, navigation and back, got %v", rows)
+	}
+	if rows[0][0] != fmt.Sprintf("boxc:2:%d", boxSolutionsPerPage+1) {
+		t.Fatalf("category button must keep the page, got %q", rows[0][0])
+	}
+	if kb.InlineKeyboard[0][0].Text != fmt.Sprintf("Категория %d (%d)", boxSolutionsPerPage+1, boxSolutionsPerPage+1) {
+		t.Fatalf("unexpected category label %q", kb.InlineKeyboard[0][0].Text)
+	}
+	if kb.InlineKeyboard[2][1].Text != "2 из 2" || rows[2][0] != "boxc:1" {
+		t.Fatalf("unexpected navigation %v", rows[2])
+	}
+	if rows[3][0] != CallbackBackToMain {
+		t.Fatalf("last row must lead to main menu, got %v", rows[3])
+	}
+}
+
+func TestCategoriesMenu_Empty(t *testing.T) {
+	text, kb := categoriesMenu(nil, 1)
+	if len(kb.InlineKeyboard) != 1 || text == "" {
+		t.Fatalf("expected only back button, got %v", keyboardData(kb))
+	}
+}
+
+func TestServicesMenu_BackToCategoryPage(t *testing.T) {
+	category := models.ServiceCategory{ID: 3, Title: "Спорт_и_отдых"}
+	services := []models.Service{{ID: 4, Title: "Теннис в Лужниках"}, {ID: 5, Title: "Падел корт в Сити"}}
+
+	text, kb := servicesMenu(category, services, 1, 2)
+	rows := keyboardData(kb)
+	if len(rows) != 3 || rows[1][0] != "boxs:3:1:5" {
+		t.Fatalf("unexpected services keyboard %v", rows)
+	}
+	if rows[2][0] != "boxc:2" {
+		t.Fatalf("back must return to categories page 2, got %q", rows[2][0])
+	}
+	if want := "📦 *Спорт\\_и\\_отдых*\n\nВыберите интересующее вас предложение:"; text != want {
+		t.Fatalf("unexpected text %q", text)
+	}
+}
+
+func TestParseServicesCategory(t *testing.T) {
+	cases := map[string]struct {
+		id int
+		ok bool
+	}{
+		"boxs:3:2:17": {3, true},
+		"boxs:0:1":    {0, true},
+		"boxs:x:1":    {0, false},
+		"boxc:1:3":    {0, false},
+		"box_3":       {0, false},
+	}
+	for data, want := range cases {
+		id, ok := parseServicesCategory(data)
+		if id != want.id || ok != want.ok {
+			t.Errorf("parseServicesCategory(%q) = %d, %v; want %d, %v", data, id, ok, want.id, want.ok)
+		}
+	}
+}
+
+func TestServiceCardBackKeepsPage(t *testing.T) {
+	fs := &fakeSender{}
+	Sender = fs
+	defer func() { Sender = defaultSender{} }()
+
+	back := servicesPager(3).PageData(2)
+	if err := sendServiceUnavailable(42, back); err != nil {
+		t.Fatal(err)
+	}
+	if fs.lastBtns[0][0].CallbackData != "boxs:3:2" {
+		t.Fatalf("unexpected back %q", fs.lastBtns[0][0].CallbackData)
+	}
+	if row := buildButtons(testCatalog[2], back)[0]; row[len(row)-1].CallbackData != "boxs:3:2" {
+		t.Fatalf("card back must lead to the services page, got %+v", row)
+	}
+}
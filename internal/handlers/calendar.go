@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/ics"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// CallbackCalendarFeed показывает ссылку на календарь бронирований.
+const CallbackCalendarFeed = "calendar_feed"
+
+// DocumentSender отправляет файлы пользователю.
+type DocumentSender interface {
+	SendDocument(userID int64, name string, data []byte, caption string) error
+}
+
+// CalendarBookings загружает бронирование для приглашения в календарь.
+type CalendarBookings interface {
+	GetBooking(ctx context.Context, bookingID int64) (*models.Booking, error)
+}
+
+// CalendarHandler отправляет бронирования в виде .ics и ссылку на календарь пользователя.
+type CalendarHandler struct {
+	repo    CalendarBookings
+	docs    DocumentSender
+	sender  MessageSender
+	feedURL func(telegramID int64) string
+	loc     *time.Location
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewCalendarHandler создаёт обработчик. feedURL возвращает адрес календаря пользователя;
+// nil — календарь по ссылке не настроен. loc — часовой пояс для услуг без своего пояса.
+func NewCalendarHandler(repo CalendarBookings, docs DocumentSender, sender MessageSender, feedURL func(int64) string, loc *time.Location, logger *zap.Logger) *CalendarHandler {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &CalendarHandler{
+		repo:    repo,
+		docs:    docs,
+		sender:  sender,
+		feedURL: feedURL,
+		loc:     loc,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// SendInvite отправляет пользователю .ics с бронированием. Подходит как BookingHook.
+func (h *CalendarHandler) SendInvite(ctx context.Context, bookingID int64) error {
+	b, err := h.repo.GetBooking(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("get booking: %w", err)
+	}
+
+	data := ics.Encode(ics.Calendar{Events: []ics.Event{ics.BookingEvent(b, h.loc)}}, h.now())
+	name := fmt.Sprintf("booking-%d.ics", b.ID)
+	if err := h.docs.SendDocument(b.UserTelegramID, name, data, "📅 Добавьте посещение в календарь"); err != nil {
+		return fmt.Errorf("send ics: %w", err)
+	}
+
+	h.logger.Info("booking_ics_sent", zap.Int64("booking_id", b.ID))
+	return nil
+}
+
+// HandleCommand обрабатывает команду /calendar.
+func (h *CalendarHandler) HandleCommand(ctx context.Context, msg *tgbotapi.Message) error {
+	if msg == nil || msg.From == nil {
+		return fmt.Errorf("invalid message from user")
+	}
+	return h.sendFeedLink(msg.From.ID)
+}
+
+// Handle обрабатывает кнопку CallbackCalendarFeed.
+func (h *CalendarHandler) Handle(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	return h.sendFeedLink(q.From.ID)
+}
+
+func (h *CalendarHandler) sendFeedLink(userID int64) error {
+	if h.feedURL == nil {
+		return h.sender.SendMessage(userID, "Подписка на календарь пока недоступна.", nil)
+	}
+
+	text := "📅 Ссылка на календарь ваших подтверждённых бронирований:\n\n" + h.feedURL(userID) +
+		"\n\nДобавьте её в Google Календарь, Apple Календарь или Outlook как календарь по ссылке (URL). " +
+		"Не передавайте ссылку другим: по ней видны ваши бронирования."
+	return h.sender.SendMessage(userID, text, [][]Button{
+		{{Text: "📋 Мои бронирования", CallbackData: CallbackMyBookings}},
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type sentDocument struct {
+	chatID int64
+	name   string
+	data   []byte
+}
+
+type recordingDocs struct {
+	sent []sentDocument
+}
+
+func (r *recordingDocs) SendDocument(userID int64, name string, data []byte, caption string) error {
+	r.sent = append(r.sent, sentDocument{chatID: userID, name: name, data: data})
+	return nil
+}
+
+func TestCalendar_SendInvite(t *testing.T) {
+	docs := &recordingDocs{}
+	h := NewCalendarHandler(&fakeAdminRepo{booking: newPendingBooking()}, docs, &recordingSender{}, nil, nil, zap.NewNop())
+
+	require.NoError(t, h.SendInvite(context.Background(), 12))
+	require.Len(t, docs.sent, 1)
+	assert.Equal(t, int64(777), docs.sent[0].chatID)
+	assert.Equal(t, "booking-12.ics", docs.sent[0].name)
+	assert.Contains(t, string(docs.sent[0].data), "SUMMARY:Третьяковская галерея")
+}
+
+func TestCalendar_FeedLink(t *testing.T) {
+	msg := &tgbotapi.Message{From: &tgbotapi.User{ID: 777}}
+
+	rs := &recordingSender{}
+	h := NewCalendarHandler(nil, nil, rs, func(id int64) string { return "https://bot.example.com/calendar/777.ics?token=t" }, nil, zap.NewNop())
+	require.NoError(t, h.HandleCommand(context.Background(), msg))
+	require.Len(t, rs.sent, 1)
+	assert.Contains(t, rs.sent[0].text, "https://bot.example.com/calendar/777.ics?token=t")
+
+	rs = &recordingSender{}
+	h = NewCalendarHandler(nil, nil, rs, nil, nil, zap.NewNop())
+	require.NoError(t, h.HandleCommand(context.Background(), msg))
+	assert.Contains(t, rs.sent[0].text, "недоступна")
+}
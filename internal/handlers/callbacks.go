@@ -3,16 +3,21 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/yandex-development-2-team/Go/internal/metrics"
-
 	"go.uber.org/zap"
 )
 
 type CallbackRouter struct {
+	// handlers — обработчики для точного совпадения callback data
 	handlers map[string]CallbackHandler
+	// routes — обработчики по префиксу и по шаблону с параметрами, в порядке регистрации
+	routes   []callbackRoute
+	fallback CallbackHandler
 	logger   *zap.Logger
 }
 
@@ -20,53 +25,256 @@ type CallbackHandler interface {
 	Handle(ctx context.Context, query *tgbotapi.CallbackQuery) error
 }
 
-func HandleCallback(router *CallbackRouter, query *tgbotapi.CallbackQuery) error {
-	start := time.Now()
+// CallbackHandlerFunc позволяет использовать обычную функцию как CallbackHandler.
+type CallbackHandlerFunc func(ctx context.Context, query *tgbotapi.CallbackQuery) error
+
+func (f CallbackHandlerFunc) Handle(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	return f(ctx, query)
+}
+
+// CallbackArgs содержит параметры, разобранные из callback data по шаблону маршрута.
+// Значения имеют тип int, string или time.Time в зависимости от типа параметра.
+type CallbackArgs map[string]interface{}
 
-	metrics.Default.ActiveUsers.Inc()
-	defer metrics.Default.ActiveUsers.Dec()
+// Int возвращает целочисленный параметр.
+func (a CallbackArgs) Int(name string) (int, bool) {
+	v, ok := a[name].(int)
+	return v, ok
+}
 
-	metrics.Default.CallbacksReceived.Inc()
-	// Получаем и находим нужный handler в карте handlers
-	button := query.Data
+// String возвращает строковый параметр.
+func (a CallbackArgs) String(name string) (string, bool) {
+	v, ok := a[name].(string)
+	return v, ok
+}
 
-	var handlerErr error
-	defer func() {
-		dur := time.Since(start).Seconds()
-		metrics.Default.CallbacksProcessingDuration.Observe(dur)
+// Date возвращает параметр-дату (формат 2006-01-02).
+func (a CallbackArgs) Date(name string) (time.Time, bool) {
+	v, ok := a[name].(time.Time)
+	return v, ok
+}
 
-		router.logger.Info("handle_callback_metrics",
-			zap.String("button", button),
-			zap.Float64("duration_seconds", dur),
-			zap.Bool("success", handlerErr == nil),
-		)
-	}()
+type callbackArgsKey struct{}
+
+// CallbackArgsFromContext возвращает параметры маршрута, с которым был вызван обработчик.
+func CallbackArgsFromContext(ctx context.Context) CallbackArgs {
+	args, _ := ctx.Value(callbackArgsKey{}).(CallbackArgs)
+	if args == nil {
+		return CallbackArgs{}
+	}
+	return args
+}
+
+// WithCallbackArgs кладёт параметры маршрута в контекст обработчика.
+func WithCallbackArgs(ctx context.Context, args CallbackArgs) context.Context {
+	return context.WithValue(ctx, callbackArgsKey{}, args)
+}
+
+type callbackRoute struct {
+	prefix  string
+	pattern *callbackPattern
+	handler CallbackHandler
+}
+
+func (r callbackRoute) match(data string) (CallbackArgs, bool) {
+	if r.pattern != nil {
+		return r.pattern.match(data)
+	}
+	if strings.HasPrefix(data, r.prefix) {
+		return CallbackArgs{}, true
+	}
+	return nil, false
+}
 
-	handler, ok := router.handlers[button]
+const callbackDateLayout = "2006-01-02"
+
+// callbackPattern — скомпилированный шаблон вида "option:{service:int}:{idx:int}".
+type callbackPattern struct {
+	re    *regexp.Regexp
+	names []string
+	types []string
+}
+
+var placeholderRe = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)(?::([a-z]+))?\}`)
+
+// compileCallbackPattern разбирает шаблон. Поддерживаемые типы параметров:
+// int, string (по умолчанию) и date (2006-01-02).
+func compileCallbackPattern(pattern string) (*callbackPattern, error) {
+	p := &callbackPattern{}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	last := 0
+	for _, loc := range placeholderRe.FindAllStringSubmatchIndex(pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		last = loc[1]
+
+		name := pattern[loc[2]:loc[3]]
+		typ := "string"
+		if loc[4] >= 0 {
+			typ = pattern[loc[4]:loc[5]]
+		}
+
+		switch typ {
+		case "int":
+			expr.WriteString(`(-?\d+)`)
+		case "string":
+			expr.WriteString(`(.+?)`)
+		case "date":
+			expr.WriteString(`(\d{4}-\d{2}-\d{2})`)
+		default:
+			return nil, fmt.Errorf("unknown parameter type %q in pattern %q", typ, pattern)
+		}
+
+		p.names = append(p.names, name)
+		p.types = append(p.types, typ)
+	}
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("compile pattern %q: %w", pattern, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+func (p *callbackPattern) match(data string) (CallbackArgs, bool) {
+	m := p.re.FindStringSubmatch(data)
+	if m == nil {
+		return nil, false
+	}
+
+	args := make(CallbackArgs, len(p.names))
+	for i, name := range p.names {
+		raw := m[i+1]
+		switch p.types[i] {
+		case "int":
+			v, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, false
+			}
+			args[name] = v
+		case "date":
+			v, err := time.Parse(callbackDateLayout, raw)
+			if err != nil {
+				return nil, false
+			}
+			args[name] = v
+		default:
+			args[name] = raw
+		}
+	}
+	return args, true
+}
+
+func NewCallbackRouter(logger *zap.Logger) *CallbackRouter {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &CallbackRouter{
+		handlers: make(map[string]CallbackHandler),
+		logger:   logger,
+	}
+}
+
+// Handle регистрирует обработчик. Шаблон без параметров сравнивается с callback data
+// целиком, шаблон с параметрами ({name:type}) разбирается, а параметры передаются
+// обработчику через контекст (см. CallbackArgsFromContext).
+// Некорректный шаблон — ошибка программиста, поэтому вызывает панику.
+func (r *CallbackRouter) Handle(pattern string, h CallbackHandler) {
+	if !placeholderRe.MatchString(pattern) {
+		r.handlers[pattern] = h
+		return
+	}
+
+	p, err := compileCallbackPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	r.routes = append(r.routes, callbackRoute{pattern: p, handler: h})
+}
+
+// HandlePrefix регистрирует обработчик для всех callback data с указанным префиксом.
+func (r *CallbackRouter) HandlePrefix(prefix string, h CallbackHandler) {
+	r.routes = append(r.routes, callbackRoute{prefix: prefix, handler: h})
+}
+
+// SetFallback задаёт обработчик для callback data, не подошедших ни под один маршрут.
+func (r *CallbackRouter) SetFallback(h CallbackHandler) {
+	r.fallback = h
+}
+
+// Match ищет обработчик для callback data: сначала точное совпадение,
+// затем префиксы и шаблоны в порядке регистрации.
+func (r *CallbackRouter) Match(data string) (CallbackHandler, CallbackArgs, bool) {
+	if h, ok := r.handlers[data]; ok {
+		return h, CallbackArgs{}, true
+	}
+	for _, route := range r.routes {
+		if args, ok := route.match(data); ok {
+			return route.handler, args, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Route вызывает обработчик, подходящий под callback data, или fallback-обработчик.
+func (r *CallbackRouter) Route(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	handler, args, ok := r.Match(query.Data)
 	if !ok {
-		// Если handler не найден, возвращаем ошибку или логируем событие
-		err := fmt.Errorf("oбработчик для идентификатора кнопки не найден")
-		router.logger.Error("handler не найден для кнопки", zap.Error(err), zap.String("button", button))
-		return err
+		if r.fallback == nil {
+			err := fmt.Errorf("oбработчик для идентификатора кнопки не найден")
+			r.logger.Error("handler не найден для кнопки", zap.Error(err), zap.String("button", query.Data))
+			return err
+		}
+		r.logger.Warn("callback_fallback", zap.String("button", query.Data))
+		handler, args = r.fallback, CallbackArgs{}
 	}
 
-	// Вызываем метод Handle у найденного handler'а
+	return handler.Handle(WithCallbackArgs(ctx, args), query)
+}
 
+// HandleCallback маршрутизирует callback query вне диспетчера.
+// Метрики и логирование длительности выполняются middleware (см. пакет middleware).
+func HandleCallback(router *CallbackRouter, query *tgbotapi.CallbackQuery) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
-	err := handler.Handle(ctx, query)
-	if err != nil {
-		handlerErr = err
-		metrics.Default.MessagesErrorsTotal.Inc()
+
+	if err := router.Route(ctx, query); err != nil {
 		return err
 	}
-	/* //когда будет bot
-	_, err = bot.AnswerCallbackQuery(tgbotapi.CallbackQueryID{CallbackQueryID: query.ID, Text: "Вы нажали " + button})
-	*/
+
 	router.logger.Info("callback handled",
-		zap.String("button", button),
+		zap.String("button", query.Data),
 		zap.String("callback_id", query.ID),
 	)
-	return err
+	return nil
+}
 
+const notAvailableMessage = "Этот раздел пока недоступен 🛠\n\nНажмите /start, чтобы вернуться в главное меню."
+
+// NotAvailableHandler отвечает на кнопки разделов, которые ещё не реализованы.
+// Используется как fallback-обработчик CallbackRouter.
+type NotAvailableHandler struct {
+	bot    *tgbotapi.BotAPI
+	logger *zap.Logger
+}
+
+func NewNotAvailableHandler(bot *tgbotapi.BotAPI, logger *zap.Logger) *NotAvailableHandler {
+	return &NotAvailableHandler{bot: bot, logger: logger}
+}
+
+func (h *NotAvailableHandler) Handle(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	if query.Message == nil {
+		return nil
+	}
+
+	if _, err := h.bot.Send(tgbotapi.NewMessage(query.Message.Chat.ID, notAvailableMessage)); err != nil {
+		h.logger.Error("failed_to_send_not_available", zap.Error(err), zap.String("button", query.Data))
+		return err
+	}
+	return nil
 }
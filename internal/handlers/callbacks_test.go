@@ -43,3 +43,96 @@ func TestHandleCallback(t *testing.T) {
 
 	assert.NoError(t, err, "Ошибка при обработке обратного вызова")
 }
+
+type argsRecorder struct {
+	args CallbackArgs
+}
+
+func (r *argsRecorder) Handle(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	r.args = CallbackArgsFromContext(ctx)
+	return nil
+}
+
+func TestCallbackRouter_Pattern(t *testing.T) {
+	router := NewCallbackRouter(zap.NewNop())
+
+	option := &argsRecorder{}
+	date := &argsRecorder{}
+	router.Handle("option:{service:int}:{idx:int}", option)
+	router.Handle("{day:date}", date)
+
+	assert.NoError(t, router.Route(context.Background(), &tgbotapi.CallbackQuery{Data: "option:12:1"}))
+	service, ok := option.args.Int("service")
+	assert.True(t, ok)
+	assert.Equal(t, 12, service)
+	idx, _ := option.args.Int("idx")
+	assert.Equal(t, 1, idx)
+
+	assert.NoError(t, router.Route(context.Background(), &tgbotapi.CallbackQuery{Data: "2026-03-14"}))
+	day, ok := date.args.Date("day")
+	assert.True(t, ok)
+	assert.Equal(t, "2026-03-14", day.Format("2006-01-02"))
+}
+
+func TestCallbackRouter_PatternRejectsWrongType(t *testing.T) {
+	router := NewCallbackRouter(zap.NewNop())
+	router.Handle("book_now:{service:int}", &argsRecorder{})
+
+	_, _, ok := router.Match("book_now:abc")
+	assert.False(t, ok)
+
+	_, _, ok = router.Match("book_now:5:extra")
+	assert.False(t, ok)
+}
+
+func TestCallbackRouter_ExactBeforePattern(t *testing.T) {
+	router := NewCallbackRouter(zap.NewNop())
+
+	exact := &argsRecorder{}
+	pattern := &argsRecorder{}
+	router.Handle("box_{name}", pattern)
+	router.Handle("box_solutions", exact)
+
+	h, _, ok := router.Match("box_solutions")
+	assert.True(t, ok)
+	assert.Same(t, exact, h)
+
+	h, args, ok := router.Match("box_gallery")
+	assert.True(t, ok)
+	assert.Same(t, pattern, h)
+	name, _ := args.String("name")
+	assert.Equal(t, "gallery", name)
+}
+
+func TestCallbackRouter_Prefix(t *testing.T) {
+	router := NewCallbackRouter(zap.NewNop())
+
+	prefix := &argsRecorder{}
+	router.HandlePrefix("admin_", prefix)
+
+	h, _, ok := router.Match("admin_anything:1")
+	assert.True(t, ok)
+	assert.Same(t, prefix, h)
+}
+
+func TestCallbackRouter_Fallback(t *testing.T) {
+	router := NewCallbackRouter(zap.NewNop())
+
+	err := router.Route(context.Background(), &tgbotapi.CallbackQuery{Data: "unknown"})
+	assert.Error(t, err, "без fallback неизвестная кнопка — ошибка")
+
+	fallback := &argsRecorder{}
+	router.SetFallback(fallback)
+
+	err = router.Route(context.Background(), &tgbotapi.CallbackQuery{Data: "unknown"})
+	assert.NoError(t, err)
+	assert.NotNil(t, fallback.args)
+}
+
+func TestCallbackRouter_InvalidPatternPanics(t *testing.T) {
+	router := NewCallbackRouter(zap.NewNop())
+
+	assert.Panics(t, func() {
+		router.Handle("x:{id:float}", &argsRecorder{})
+	})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// HandleMyChatMember логирует изменение статуса бота в чате:
+// пользователь заблокировал бота ("kicked") или снова запустил его ("member").
+func HandleMyChatMember(ctx context.Context, upd *tgbotapi.ChatMemberUpdated, logger *zap.Logger) error {
+	if upd == nil {
+		return nil
+	}
+
+	fields := []zap.Field{
+		zap.Int64("user_id", upd.From.ID),
+		zap.Int64("chat_id", upd.Chat.ID),
+		zap.String("old_status", upd.OldChatMember.Status),
+		zap.String("new_status", upd.NewChatMember.Status),
+	}
+
+	switch upd.NewChatMember.Status {
+	case "kicked":
+		logger.Info("bot_blocked_by_user", fields...)
+	case "member":
+		logger.Info("bot_unblocked_by_user", fields...)
+	default:
+		logger.Info("bot_chat_member_updated", fields...)
+	}
+	return nil
+}
@@ -36,6 +36,9 @@ func (h *MainMenuHandler) Handle(ctx context.Context, query *tgbotapi.CallbackQu
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("📦 Коробочные решения", CallbackBoxSolutions),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📋 Мои бронирования", CallbackMyBookings),
+		),
 	)
 
 	message := tgbotapi.NewEditMessageTextAndMarkup(
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MessageHandler обрабатывает входящее сообщение: команду или текст.
+type MessageHandler interface {
+	Handle(ctx context.Context, msg *tgbotapi.Message) error
+}
+
+// MessageHandlerFunc позволяет использовать обычную функцию как MessageHandler.
+type MessageHandlerFunc func(ctx context.Context, msg *tgbotapi.Message) error
+
+func (f MessageHandlerFunc) Handle(ctx context.Context, msg *tgbotapi.Message) error {
+	return f(ctx, msg)
+}
@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// Маршруты раздела «Мои бронирования».
+const (
+	CallbackMyBookings         = "my_bookings"
+	CallbackMyBookingsPage     = "my_bookings:{page:int}"
+	CallbackBookingDetails     = "booking:{id:int}"
+	CallbackBookingCancel      = "booking_cancel:{id:int}"
+	CallbackBookingCancelYes   = "booking_cancel_yes:{id:int}"
+	CallbackBookingReschedule  = "booking_reschedule:{id:int}"
+	CallbackRescheduleDate     = "reschedule:{id:int}:{date:date}"
+	CallbackRescheduleDateTime = "reschedule:{id:int}:{date:date}:{time:string}"
+)
+
+const myBookingsPageSize = 5
+
+const (
+	myBookingsEmptyMessage      = "У вас пока нет бронирований.\n\nВыберите услугу в разделе «Коробочные решения»."
+	bookingNotFoundMessage      = "Бронирование не найдено."
+	bookingNotChangeableMessage = "Это бронирование уже нельзя изменить."
+)
+
+// MyBookingsRepository — операции с бронированиями пользователя.
+type MyBookingsRepository interface {
+	ListUserBookings(ctx context.Context, telegramID int64, limit, offset int) ([]models.Booking, int, error)
+	GetUserBooking(ctx context.Context, telegramID, bookingID int64) (*models.Booking, error)
+	CancelBooking(ctx context.Context, telegramID, bookingID int64) error
+	RescheduleBooking(ctx context.Context, telegramID, bookingID int64, date time.Time, slotTime string) error
+	GetAvailableDates(ctx context.Context, serviceID int) ([]time.Time, error)
+	GetAvailableSlots(ctx context.Context, serviceID int, date time.Time) ([]string, bool, error)
+}
+
+// MyBookingsHandler показывает бронирования пользователя и позволяет отменить или перенести их.
+type MyBookingsHandler struct {
+	repo          MyBookingsRepository
+	sender        MessageSender
+	logger        *zap.Logger
+	onRescheduled []BookingHook
+	now           func() time.Time
+}
+
+func NewMyBookingsHandler(repo MyBookingsRepository, sender MessageSender, logger *zap.Logger) *MyBookingsHandler {
+	return &MyBookingsHandler{repo: repo, sender: sender, logger: logger, now: time.Now}
+}
+
+// OnBookingRescheduled регистрирует хук, вызываемый после переноса бронирования.
+func (h *MyBookingsHandler) OnBookingRescheduled(hook BookingHook) {
+	h.onRescheduled = append(h.onRescheduled, hook)
+}
+
+// HandleCommand обрабатывает команду /mybookings.
+func (h *MyBookingsHandler) HandleCommand(ctx context.Context, msg *tgbotapi.Message) error {
+	if msg == nil || msg.From == nil {
+		return fmt.Errorf("invalid message from user")
+	}
+	return h.showPage(ctx, msg.From.ID, 0)
+}
+
+// List показывает страницу списка бронирований ("my_bookings" и "my_bookings:{page:int}").
+func (h *MyBookingsHandler) List(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	page, _ := CallbackArgsFromContext(ctx).Int("page")
+	return h.showPage(ctx, q.From.ID, page)
+}
+
+func (h *MyBookingsHandler) showPage(ctx context.Context, userID int64, page int) error {
+	if page < 0 {
+		page = 0
+	}
+
+	bookings, total, err := h.repo.ListUserBookings(ctx, userID, myBookingsPageSize, page*myBookingsPageSize)
+	if err != nil {
+		h.logger.Error("failed_to_list_bookings", zap.Error(err), zap.Int64("user_id", userID))
+		return err
+	}
+
+	h.logger.Info("my_bookings_opened", zap.Int64("user_id", userID), zap.Int("page", page), zap.Int("total", total))
+
+	if total == 0 {
+		return h.sender.SendMessage(userID, myBookingsEmptyMessage, [][]Button{
+			{{Text: "📦 Коробочные решения", CallbackData: CallbackBoxSolutions}},
+			{{Text: "Назад", CallbackData: CallbackBackToMain}},
+		})
+	}
+
+	return h.sender.SendMessage(userID, composeBookingsPage(page, total), bookingsPageButtons(bookings, page, total, h.now()))
+}
+
+// Details показывает карточку бронирования с действиями.
+func (h *MyBookingsHandler) Details(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	b, ok, err := h.loadBooking(ctx, q)
+	if err != nil || !ok {
+		return err
+	}
+
+	var buttons [][]Button
+	if changeable(b, h.now()) {
+		buttons = append(buttons, []Button{
+			{Text: "🔁 Перенести", CallbackData: fmt.Sprintf("booking_reschedule:%d", b.ID)},
+			{Text: "❌ Отменить", CallbackData: fmt.Sprintf("booking_cancel:%d", b.ID)},
+		})
+	}
+	buttons = append(buttons, []Button{{Text: "Назад", CallbackData: CallbackMyBookings}})
+
+	return h.sender.SendMessage(q.From.ID, composeBookingDetails(b), buttons)
+}
+
+// Cancel просит подтвердить отмену бронирования.
+func (h *MyBookingsHandler) Cancel(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	b, ok, err := h.loadBooking(ctx, q)
+	if err != nil || !ok {
+		return err
+	}
+	if !changeable(b, h.now()) {
+		return h.sender.SendMessage(q.From.ID, bookingNotChangeableMessage, nil)
+	}
+
+	text := fmt.Sprintf("Отменить бронирование «%s» на %s?", b.ServiceTitle, b.When())
+	return h.sender.SendMessage(q.From.ID, text, [][]Button{{
+		{Text: "Да, отменить", CallbackData: fmt.Sprintf("booking_cancel_yes:%d", b.ID)},
+		{Text: "Нет", CallbackData: fmt.Sprintf("booking:%d", b.ID)},
+	}})
+}
+
+// ConfirmCancel отменяет бронирование после подтверждения.
+func (h *MyBookingsHandler) ConfirmCancel(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	b, ok, err := h.loadBooking(ctx, q)
+	if err != nil || !ok {
+		return err
+	}
+	// кнопка подтверждения могла пролежать в чате, пока дата бронирования не прошла
+	if isPast(b, h.now()) {
+		return h.sender.SendMessage(q.From.ID, bookingNotChangeableMessage, nil)
+	}
+
+	err = h.repo.CancelBooking(ctx, q.From.ID, b.ID)
+	switch {
+	case errors.Is(err, repository.ErrBookingNotFound):
+		return h.sender.SendMessage(q.From.ID, bookingNotFoundMessage, nil)
+	case errors.Is(err, models.ErrInvalidStatusTransition):
+		return h.sender.SendMessage(q.From.ID, bookingNotChangeableMessage, nil)
+	case err != nil:
+		h.logger.Error("failed_to_cancel_booking", zap.Error(err), zap.Int64("booking_id", b.ID))
+		return err
+	}
+
+	h.logger.Info("booking_cancelled_by_user", zap.Int64("user_id", q.From.ID), zap.Int64("booking_id", b.ID))
+	return h.sender.SendMessage(q.From.ID, "Бронирование отменено.", [][]Button{
+		{{Text: "📋 Мои бронирования", CallbackData: CallbackMyBookings}},
+	})
+}
+
+// Reschedule предлагает выбрать новую дату.
+func (h *MyBookingsHandler) Reschedule(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	b, ok, err := h.loadBooking(ctx, q)
+	if err != nil || !ok {
+		return err
+	}
+	if !changeable(b, h.now()) {
+		return h.sender.SendMessage(q.From.ID, bookingNotChangeableMessage, nil)
+	}
+	return h.sendRescheduleDates(ctx, q.From.ID, b)
+}
+
+// RescheduleDate обрабатывает выбор новой даты и, для почасовых услуг, времени.
+// Ожидает маршруты CallbackRescheduleDate и CallbackRescheduleDateTime.
+func (h *MyBookingsHandler) RescheduleDate(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	args := CallbackArgsFromContext(ctx)
+	date, ok := args.Date("date")
+	if !ok {
+		return fmt.Errorf("date is missing in callback %q", q.Data)
+	}
+	slotTime, _ := args.String("time")
+
+	b, ok, err := h.loadBooking(ctx, q)
+	if err != nil || !ok {
+		return err
+	}
+	if !changeable(b, h.now()) {
+		return h.sender.SendMessage(q.From.ID, bookingNotChangeableMessage, nil)
+	}
+
+	// дата и время приходят из кнопок, которые могли устареть, — сверяем их с расписанием
+	slots, timed, err := h.repo.GetAvailableSlots(ctx, b.ServiceID, date)
+	if err != nil {
+		h.logger.Error("failed_to_get_slots", zap.Error(err), zap.Int64("booking_id", b.ID))
+		return err
+	}
+	switch {
+	case timed && slotTime == "":
+		return h.sendRescheduleSlots(q.From.ID, b, date, slots)
+	case timed && !slices.Contains(slots, slotTime), !timed && slotTime != "":
+		return h.rescheduleUnavailable(ctx, q.From.ID, b)
+	case !timed:
+		dates, err := h.repo.GetAvailableDates(ctx, b.ServiceID)
+		if err != nil {
+			h.logger.Error("failed_to_get_dates", zap.Error(err), zap.Int64("booking_id", b.ID))
+			return err
+		}
+		if !slices.ContainsFunc(dates, sameDay(date)) {
+			return h.rescheduleUnavailable(ctx, q.From.ID, b)
+		}
+	}
+
+	err = h.repo.RescheduleBooking(ctx, q.From.ID, b.ID, date, slotTime)
+	switch {
+	case errors.Is(err, repository.ErrSlotTaken):
+		if err := h.sender.SendMessage(q.From.ID, slotTakenMessage, nil); err != nil {
+			return err
+		}
+		return h.sendRescheduleDates(ctx, q.From.ID, b)
+	case errors.Is(err, repository.ErrSlotUnavailable):
+		return h.rescheduleUnavailable(ctx, q.From.ID, b)
+	case errors.Is(err, repository.ErrMonthlyLimitReached):
+		if err := h.sender.SendMessage(q.From.ID, monthlyLimitMessage, nil); err != nil {
+			return err
+		}
+		return h.sendRescheduleDates(ctx, q.From.ID, b)
+	case errors.Is(err, repository.ErrGradeNotAllowed):
+		return h.sender.SendMessage(q.From.ID, gradeNotAllowedMessage, nil)
+	case errors.Is(err, models.ErrInvalidStatusTransition):
+		return h.sender.SendMessage(q.From.ID, bookingNotChangeableMessage, nil)
+	case err != nil:
+		h.logger.Error("failed_to_reschedule_booking", zap.Error(err), zap.Int64("booking_id", b.ID))
+		return err
+	}
+
+	when := date.Format("02.01.2006")
+	if slotTime != "" {
+		when += " " + slotTime
+	}
+	h.logger.Info("booking_rescheduled_by_user", zap.Int64("user_id", q.From.ID), zap.Int64("booking_id", b.ID))
+	for _, hook := range h.onRescheduled {
+		if err := hook(ctx, b.ID); err != nil {
+			h.logger.Error("booking_rescheduled_hook_failed", zap.Int64("booking_id", b.ID), zap.Error(err))
+		}
+	}
+	return h.sender.SendMessage(q.From.ID, "Бронирование перенесено на "+when+".", [][]Button{
+		{{Text: "📋 Мои бронирования", CallbackData: CallbackMyBookings}},
+	})
+}
+
+// rescheduleUnavailable сообщает, что выбранные дата или время не входят в расписание,
+// и снова предлагает даты.
+func (h *MyBookingsHandler) rescheduleUnavailable(ctx context.Context, userID int64, b *models.Booking) error {
+	h.logger.Info("reschedule_slot_unavailable", zap.Int64("user_id", userID), zap.Int64("booking_id", b.ID))
+	if err := h.sender.SendMessage(userID, slotUnavailableMessage, nil); err != nil {
+		return err
+	}
+	return h.sendRescheduleDates(ctx, userID, b)
+}
+
+func (h *MyBookingsHandler) sendRescheduleDates(ctx context.Context, userID int64, b *models.Booking) error {
+	dates, err := h.repo.GetAvailableDates(ctx, b.ServiceID)
+	if err != nil {
+		h.logger.Error("failed_to_get_dates", zap.Error(err), zap.Int64("booking_id", b.ID))
+		return err
+	}
+
+	back := []Button{{Text: "Назад", CallbackData: fmt.Sprintf("booking:%d", b.ID)}}
+	if len(dates) == 0 {
+		return h.sender.SendMessage(userID, "Свободных дат на ближайшие две недели нет, попробуйте позже", [][]Button{back})
+	}
+
+	buttons := make([][]Button, 0, len(dates)+1)
+	for _, d := range dates {
+		buttons = append(buttons, []Button{{
+			Text:         d.Format("02.01.2006"),
+			CallbackData: fmt.Sprintf("reschedule:%d:%s", b.ID, d.Format(callbackDateLayout)),
+		}})
+	}
+	buttons = append(buttons, back)
+
+	return h.sender.SendMessage(userID, "Выберите новую дату:", buttons)
+}
+
+func (h *MyBookingsHandler) sendRescheduleSlots(userID int64, b *models.Booking, date time.Time, slots []string) error {
+	back := []Button{{Text: "Назад", CallbackData: fmt.Sprintf("booking_reschedule:%d", b.ID)}}
+	if len(slots) == 0 {
+		return h.sender.SendMessage(userID, "На эту дату свободного времени не осталось, выберите другую дату", [][]Button{back})
+	}
+
+	var buttons [][]Button
+	for i := 0; i < len(slots); i += slotsPerRow {
+		var row []Button
+		for _, slot := range slots[i:min(i+slotsPerRow, len(slots))] {
+			row = append(row, Button{
+				Text:         slot,
+				CallbackData: fmt.Sprintf("reschedule:%d:%s:%s", b.ID, date.Format(callbackDateLayout), slot),
+			})
+		}
+		buttons = append(buttons, row)
+	}
+	buttons = append(buttons, back)
+
+	return h.sender.SendMessage(userID, "Выберите новое время:", buttons)
+}
+
+// loadBooking загружает бронирование из параметра id маршрута. ok == false означает,
+// что пользователю уже отправлено сообщение об отсутствии бронирования.
+func (h *MyBookingsHandler) loadBooking(ctx context.Context, q *tgbotapi.CallbackQuery) (*models.Booking, bool, error) {
+	id, ok := CallbackArgsFromContext(ctx).Int("id")
+	if !ok {
+		return nil, false, fmt.Errorf("booking id is missing in callback %q", q.Data)
+	}
+
+	b, err := h.repo.GetUserBooking(ctx, q.From.ID, int64(id))
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		return nil, false, h.sender.SendMessage(q.From.ID, bookingNotFoundMessage, nil)
+	}
+	if err != nil {
+		h.logger.Error("failed_to_get_booking", zap.Error(err), zap.Int("booking_id", id))
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+// composeBookingsPage формирует заголовок страницы списка бронирований.
+func composeBookingsPage(page, total int) string {
+	pages := (total + myBookingsPageSize - 1) / myBookingsPageSize
+	text := "📋 Мои бронирования\n\nВыберите бронирование, чтобы отменить или перенести его."
+	if pages > 1 {
+		text += fmt.Sprintf("\n\nСтраница %d из %d", page+1, pages)
+	}
+	return text
+}
+
+// bookingsPageButtons формирует кнопки бронирований и навигацию по страницам.
+func bookingsPageButtons(bookings []models.Booking, page, total int, now time.Time) [][]Button {
+	buttons := make([][]Button, 0, len(bookings)+2)
+	for _, b := range bookings {
+		icon := "📅"
+		switch {
+		case b.Status == models.BookingStatusCancelled:
+			icon = "❌"
+		case isPast(&b, now):
+			icon = "🕘"
+		}
+		buttons = append(buttons, []Button{{
+			Text:         fmt.Sprintf("%s %s · %s", icon, b.When(), b.ServiceTitle),
+			CallbackData: fmt.Sprintf("booking:%d", b.ID),
+		}})
+	}
+
+	var nav []Button
+	if page > 0 {
+		nav = append(nav, Button{Text: "◀️", CallbackData: fmt.Sprintf("my_bookings:%d", page-1)})
+	}
+	if (page+1)*myBookingsPageSize < total {
+		nav = append(nav, Button{Text: "▶️", CallbackData: fmt.Sprintf("my_bookings:%d", page+1)})
+	}
+	if len(nav) > 0 {
+		buttons = append(buttons, nav)
+	}
+
+	return append(buttons,
+		[]Button{{Text: "🗓 Календарь по ссылке", CallbackData: CallbackCalendarFeed}},
+		[]Button{{Text: "Назад", CallbackData: CallbackBackToMain}},
+	)
+}
+
+// composeBookingDetails формирует карточку бронирования.
+func composeBookingDetails(b *models.Booking) string {
+	parts := []string{
+		b.ServiceTitle,
+		"",
+		"Дата: " + b.When(),
+		"Статус: " + b.Status.Title(),
+		"ФИО: " + b.GuestName,
+	}
+	if b.GuestOrganization.Valid {
+		parts = append(parts, "Организация: "+b.GuestOrganization.String)
+	}
+	if b.GuestPosition.Valid {
+		parts = append(parts, "Должность: "+b.GuestPosition.String)
+	}
+	if b.GuestCount > 1 {
+		parts = append(parts, fmt.Sprintf("Гости (%d): %s", b.GuestCount, b.GuestNames.String))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// changeable сообщает, можно ли ещё отменить или перенести бронирование: оно активно
+// и его дата не прошла.
+func changeable(b *models.Booking, now time.Time) bool {
+	return b.IsActive() && !isPast(b, now)
+}
+
+// isPast сообщает, прошла ли дата бронирования. «Сегодня» считается в часовом поясе
+// услуги, как и в репозитории, а не в поясе сервера.
+func isPast(b *models.Booking, now time.Time) bool {
+	y, m, d := now.In(b.Location(time.UTC)).Date()
+	return b.BookingDate.Before(time.Date(y, m, d, 0, 0, 0, 0, b.BookingDate.Location()))
+}
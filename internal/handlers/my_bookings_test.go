@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+type fakeMyBookingsRepo struct {
+	bookings      []models.Booking
+	slots         []string
+	timed         bool
+	cancelled     []int64
+	rescheduled   string
+	rescheduleErr error
+}
+
+func (f *fakeMyBookingsRepo) ListUserBookings(ctx context.Context, telegramID int64, limit, offset int) ([]models.Booking, int, error) {
+	if offset >= len(f.bookings) {
+		return nil, len(f.bookings), nil
+	}
+	end := min(offset+limit, len(f.bookings))
+	return f.bookings[offset:end], len(f.bookings), nil
+}
+
+func (f *fakeMyBookingsRepo) GetUserBooking(ctx context.Context, telegramID, bookingID int64) (*models.Booking, error) {
+	for i := range f.bookings {
+		if f.bookings[i].ID == bookingID {
+			return &f.bookings[i], nil
+		}
+	}
+	return nil, repository.ErrBookingNotFound
+}
+
+func (f *fakeMyBookingsRepo) CancelBooking(ctx context.Context, telegramID, bookingID int64) error {
+	f.cancelled = append(f.cancelled, bookingID)
+	return nil
+}
+
+func (f *fakeMyBookingsRepo) RescheduleBooking(ctx context.Context, telegramID, bookingID int64, date time.Time, slotTime string) error {
+	if f.rescheduleErr != nil {
+		return f.rescheduleErr
+	}
+	f.rescheduled = strings.TrimSpace(date.Format("2006-01-02") + " " + slotTime)
+	return nil
+}
+
+func (f *fakeMyBookingsRepo) GetAvailableDates(ctx context.Context, serviceID int) ([]time.Time, error) {
+	return []time.Time{time.Now().AddDate(0, 0, 1)}, nil
+}
+
+func (f *fakeMyBookingsRepo) GetAvailableSlots(ctx context.Context, serviceID int, date time.Time) ([]string, bool, error) {
+	return f.slots, f.timed, nil
+}
+
+func upcomingBooking(id int64) models.Booking {
+	return models.Booking{
+		ID:           id,
+		ServiceID:    1,
+		ServiceTitle: "Третьяковская галерея",
+		BookingDate:  time.Now().AddDate(0, 0, 3),
+		GuestName:    "Иван Иванов",
+		Status:       models.BookingStatusPending,
+	}
+}
+
+func callbackWithArgs(args CallbackArgs) (context.Context, *tgbotapi.CallbackQuery) {
+	return WithCallbackArgs(context.Background(), args), &tgbotapi.CallbackQuery{From: &tgbotapi.User{ID: 42}}
+}
+
+func TestMyBookings_ListPagination(t *testing.T) {
+	repo := &fakeMyBookingsRepo{}
+	for i := int64(1); i <= 7; i++ {
+		repo.bookings = append(repo.bookings, upcomingBooking(i))
+	}
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(repo, fs, zap.NewNop())
+
+	ctx, q := callbackWithArgs(CallbackArgs{})
+	require.NoError(t, h.List(ctx, q))
+
+	assert.Contains(t, fs.lastText, "Страница 1 из 2")
+	// 5 бронирований, навигация, календарь, «Назад»
+	require.Len(t, fs.lastBtns, 8)
+	assert.Equal(t, "booking:1", fs.lastBtns[0][0].CallbackData)
+	assert.Equal(t, []Button{{Text: "▶️", CallbackData: "my_bookings:1"}}, fs.lastBtns[5])
+
+	ctx, q = callbackWithArgs(CallbackArgs{"page": 1})
+	require.NoError(t, h.List(ctx, q))
+	require.Len(t, fs.lastBtns, 5)
+	assert.Equal(t, "booking:6", fs.lastBtns[0][0].CallbackData)
+	assert.Equal(t, []Button{{Text: "◀️", CallbackData: "my_bookings:0"}}, fs.lastBtns[2])
+}
+
+func TestMyBookings_Empty(t *testing.T) {
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(&fakeMyBookingsRepo{}, fs, zap.NewNop())
+
+	require.NoError(t, h.HandleCommand(context.Background(), &tgbotapi.Message{From: &tgbotapi.User{ID: 42}}))
+	assert.Equal(t, myBookingsEmptyMessage, fs.lastText)
+	assert.Equal(t, int64(42), fs.lastUser)
+}
+
+func TestMyBookings_DetailsActions(t *testing.T) {
+	active := upcomingBooking(1)
+	active.GuestOrganization = sql.NullString{String: "Яндекс", Valid: true}
+	cancelled := upcomingBooking(2)
+	cancelled.Status = models.BookingStatusCancelled
+
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(&fakeMyBookingsRepo{bookings: []models.Booking{active, cancelled}}, fs, zap.NewNop())
+
+	ctx, q := callbackWithArgs(CallbackArgs{"id": 1})
+	require.NoError(t, h.Details(ctx, q))
+	assert.Contains(t, fs.lastText, "Организация: Яндекс")
+	require.Len(t, fs.lastBtns, 2)
+	assert.Equal(t, "booking_reschedule:1", fs.lastBtns[0][0].CallbackData)
+	assert.Equal(t, "booking_cancel:1", fs.lastBtns[0][1].CallbackData)
+
+	ctx, q = callbackWithArgs(CallbackArgs{"id": 2})
+	require.NoError(t, h.Details(ctx, q))
+	assert.Contains(t, fs.lastText, models.BookingStatusCancelled.Title())
+	require.Len(t, fs.lastBtns, 1, "у отменённого бронирования нет действий")
+
+	ctx, q = callbackWithArgs(CallbackArgs{"id": 3})
+	require.NoError(t, h.Details(ctx, q))
+	assert.Equal(t, bookingNotFoundMessage, fs.lastText)
+}
+
+func TestMyBookings_ConfirmCancel(t *testing.T) {
+	repo := &fakeMyBookingsRepo{bookings: []models.Booking{upcomingBooking(1)}}
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(repo, fs, zap.NewNop())
+
+	ctx, q := callbackWithArgs(CallbackArgs{"id": 1})
+	require.NoError(t, h.ConfirmCancel(ctx, q))
+	assert.Equal(t, []int64{1}, repo.cancelled)
+	assert.Equal(t, "Бронирование отменено.", fs.lastText)
+}
+
+func TestMyBookings_PastBookingNotChangeable(t *testing.T) {
+	past := upcomingBooking(1)
+	past.BookingDate = time.Now().AddDate(0, 0, -1)
+	repo := &fakeMyBookingsRepo{bookings: []models.Booking{past}}
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(repo, fs, zap.NewNop())
+
+	for name, handle := range map[string]func(context.Context, *tgbotapi.CallbackQuery) error{
+		"cancel":          h.Cancel,
+		"confirm_cancel":  h.ConfirmCancel,
+		"reschedule":      h.Reschedule,
+		"reschedule_date": h.RescheduleDate,
+	} {
+		fs.lastText = ""
+		ctx, q := callbackWithArgs(CallbackArgs{"id": 1, "date": time.Now().AddDate(0, 0, 1)})
+		require.NoError(t, handle(ctx, q), name)
+		assert.Equal(t, bookingNotChangeableMessage, fs.lastText, name)
+	}
+	assert.Empty(t, repo.cancelled)
+	assert.Empty(t, repo.rescheduled)
+}
+
+func TestMyBookings_TodayInServiceTimezone(t *testing.T) {
+	// 15:00 UTC 9 марта — во Владивостоке уже 01:00 10 марта
+	now := time.Date(2026, 3, 9, 15, 0, 0, 0, time.UTC)
+	yesterday := upcomingBooking(1)
+	yesterday.BookingDate = time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	yesterday.ServiceTimezone = "Asia/Vladivostok"
+	today := upcomingBooking(2)
+	today.BookingDate = time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	today.ServiceTimezone = "Asia/Vladivostok"
+
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(&fakeMyBookingsRepo{bookings: []models.Booking{yesterday, today}}, fs, zap.NewNop())
+	h.now = func() time.Time { return now }
+
+	ctx, q := callbackWithArgs(CallbackArgs{"id": 1})
+	require.NoError(t, h.Details(ctx, q))
+	require.Len(t, fs.lastBtns, 1, "по времени услуги дата уже прошла")
+
+	ctx, q = callbackWithArgs(CallbackArgs{"id": 2})
+	require.NoError(t, h.Details(ctx, q))
+	require.Len(t, fs.lastBtns, 2)
+	assert.Equal(t, "booking_cancel:2", fs.lastBtns[0][1].CallbackData)
+}
+
+func TestMyBookings_RescheduleTimedAsksForSlot(t *testing.T) {
+	repo := &fakeMyBookingsRepo{
+		bookings: []models.Booking{upcomingBooking(1)},
+		timed:    true,
+		slots:    []string{"10:00", "11:00"},
+	}
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(repo, fs, zap.NewNop())
+
+	date := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	ctx, q := callbackWithArgs(CallbackArgs{"id": 1, "date": date})
+	require.NoError(t, h.RescheduleDate(ctx, q))
+	assert.Empty(t, repo.rescheduled)
+	assert.Equal(t, "reschedule:1:2026-03-20:10:00", fs.lastBtns[0][0].CallbackData)
+
+	ctx, q = callbackWithArgs(CallbackArgs{"id": 1, "date": date, "time": "11:00"})
+	require.NoError(t, h.RescheduleDate(ctx, q))
+	assert.Equal(t, "2026-03-20 11:00", repo.rescheduled)
+	assert.Contains(t, fs.lastText, "20.03.2026 11:00")
+}
+
+func TestMyBookings_RescheduleSlotTaken(t *testing.T) {
+	repo := &fakeMyBookingsRepo{
+		bookings:      []models.Booking{upcomingBooking(1)},
+		rescheduleErr: repository.ErrSlotTaken,
+	}
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(repo, fs, zap.NewNop())
+
+	ctx, q := callbackWithArgs(CallbackArgs{"id": 1, "date": time.Now().AddDate(0, 0, 1)})
+	require.NoError(t, h.RescheduleDate(ctx, q))
+	// после сообщения о занятом слоте снова предлагаются даты
+	assert.Equal(t, "Выберите новую дату:", fs.lastText)
+}
+
+func TestMyBookings_RescheduleOutsideSchedule(t *testing.T) {
+	repo := &fakeMyBookingsRepo{
+		bookings: []models.Booking{upcomingBooking(1)},
+		timed:    true,
+		slots:    []string{"10:00"},
+	}
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(repo, fs, zap.NewNop())
+
+	// время со старой клавиатуры, которого нет в расписании
+	ctx, q := callbackWithArgs(CallbackArgs{"id": 1, "date": time.Now().AddDate(0, 0, 1), "time": "23:00"})
+	require.NoError(t, h.RescheduleDate(ctx, q))
+	assert.Empty(t, repo.rescheduled)
+	assert.Equal(t, "Выберите новую дату:", fs.lastText)
+
+	// дата без сеансов у услуги на весь день
+	repo.timed, repo.slots = false, nil
+	ctx, q = callbackWithArgs(CallbackArgs{"id": 1, "date": time.Now().AddDate(0, 0, 5)})
+	require.NoError(t, h.RescheduleDate(ctx, q))
+	assert.Empty(t, repo.rescheduled)
+	assert.Equal(t, "Выберите новую дату:", fs.lastText)
+}
+
+func TestMyBookings_RescheduleMonthlyLimit(t *testing.T) {
+	repo := &fakeMyBookingsRepo{
+		bookings:      []models.Booking{upcomingBooking(1)},
+		rescheduleErr: repository.ErrMonthlyLimitReached,
+	}
+	fs := &fakeSender{}
+	h := NewMyBookingsHandler(repo, fs, zap.NewNop())
+
+	ctx, q := callbackWithArgs(CallbackArgs{"id": 1, "date": time.Now().AddDate(0, 0, 1)})
+	require.NoError(t, h.RescheduleDate(ctx, q))
+	assert.Equal(t, "Выберите новую дату:", fs.lastText)
+}
+
+func TestMyBookingsRoutes(t *testing.T) {
+	router := NewCallbackRouter(zap.NewNop())
+	for _, pattern := range []string{
+		CallbackMyBookingsPage, CallbackBookingDetails, CallbackBookingCancel, CallbackBookingCancelYes,
+		CallbackBookingReschedule, CallbackRescheduleDate, CallbackRescheduleDateTime,
+	} {
+		router.Handle(pattern, CallbackHandlerFunc(func(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+			return nil
+		}))
+	}
+
+	_, args, ok := router.Match("reschedule:7:2026-03-20:18:00")
+	require.True(t, ok)
+	v, _ := args.String("time")
+	assert.Equal(t, "18:00", v)
+
+	_, args, ok = router.Match("reschedule:7:2026-03-20")
+	require.True(t, ok)
+	_, hasTime := args.String("time")
+	assert.False(t, hasTime)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/scheduler"
+)
+
+// JobBookingReminder — вид отложенного задания с напоминанием о посещении.
+const JobBookingReminder = "booking_reminder"
+
+// wholeDayVisitTime — время, от которого отсчитываются напоминания для бронирований на весь день.
+const wholeDayVisitTime = 10 * time.Hour
+
+// JobScheduler планирует отложенные задания.
+type JobScheduler interface {
+	Schedule(ctx context.Context, kind, key string, runAt time.Time, payload interface{}) (bool, error)
+}
+
+// ReminderBookings загружает бронирование для напоминания.
+type ReminderBookings interface {
+	GetBooking(ctx context.Context, bookingID int64) (*models.Booking, error)
+}
+
+type reminderPayload struct {
+	BookingID int64     `json:"booking_id"`
+	VisitAt   time.Time `json:"visit_at"`
+	Before    string    `json:"before"` // time.Duration в строковом виде
+}
+
+// BookingReminders планирует и отправляет напоминания о посещении.
+type BookingReminders struct {
+	repo    ReminderBookings
+	jobs    JobScheduler
+	sender  MessageSender
+	offsets []time.Duration
+	loc     *time.Location
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewBookingReminders создаёт сервис напоминаний. offsets — за сколько до посещения
+// напоминать, loc — часовой пояс для услуг, у которых он не задан.
+func NewBookingReminders(repo ReminderBookings, jobs JobScheduler, sender MessageSender, offsets []time.Duration, loc *time.Location, logger *zap.Logger) *BookingReminders {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &BookingReminders{
+		repo:    repo,
+		jobs:    jobs,
+		sender:  sender,
+		offsets: offsets,
+		loc:     loc,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Schedule планирует напоминания для бронирования. Подходит как BookingHook:
+// после переноса планируются новые напоминания, а старые пропускаются при запуске.
+func (r *BookingReminders) Schedule(ctx context.Context, bookingID int64) error {
+	b, err := r.repo.GetBooking(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("get booking: %w", err)
+	}
+
+	visit := r.visitTime(b)
+	for _, before := range r.offsets {
+		runAt := visit.Add(-before)
+		if !runAt.After(r.now()) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d:%s:%s", JobBookingReminder, b.ID, visit.UTC().Format(time.RFC3339), before)
+		payload := reminderPayload{BookingID: b.ID, VisitAt: visit, Before: before.String()}
+		if _, err := r.jobs.Schedule(ctx, JobBookingReminder, key, runAt, payload); err != nil {
+			return fmt.Errorf("schedule reminder: %w", err)
+		}
+	}
+	return nil
+}
+
+// Handle отправляет напоминание. Если бронирование отменено или перенесено, напоминание пропускается.
+func (r *BookingReminders) Handle(ctx context.Context, job models.ScheduledJob) error {
+	var p reminderPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return scheduler.Permanent(fmt.Errorf("decode reminder payload: %w", err))
+	}
+
+	b, err := r.repo.GetBooking(ctx, p.BookingID)
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get booking: %w", err)
+	}
+
+	if !b.IsActive() || !r.visitTime(b).Equal(p.VisitAt) {
+		r.logger.Info("booking_reminder_skipped", zap.Int64("booking_id", b.ID), zap.String("status", string(b.Status)))
+		return nil
+	}
+
+	before, _ := time.ParseDuration(p.Before)
+	text := fmt.Sprintf("⏰ Напоминаем: %s — «%s», %s.\n\nЕсли планы изменились, отмените бронирование, чтобы освободить место.",
+		reminderLead(before), b.ServiceTitle, b.When())
+	buttons := [][]Button{
+		{{Text: "🚫 Я не приду", CallbackData: fmt.Sprintf("booking_cancel_yes:%d", b.ID)}},
+		{{Text: "📋 Мои бронирования", CallbackData: CallbackMyBookings}},
+	}
+	if err := r.sender.SendMessage(b.UserTelegramID, text, buttons); err != nil {
+		return fmt.Errorf("send reminder: %w", err)
+	}
+
+	r.logger.Info("booking_reminder_sent", zap.Int64("booking_id", b.ID), zap.String("before", p.Before))
+	return nil
+}
+
+// visitTime возвращает момент начала посещения в часовом поясе услуги.
+func (r *BookingReminders) visitTime(b *models.Booking) time.Time {
+	offset := wholeDayVisitTime
+	if b.BookingTime.Valid && b.BookingTime.String != "" {
+		if t, err := time.Parse(slotTimeLayout, b.BookingTime.String); err == nil {
+			offset = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		}
+	}
+	y, m, d := b.BookingDate.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, b.Location(r.loc)).Add(offset)
+}
+
+func reminderLead(before time.Duration) string {
+	switch {
+	case before >= 24*time.Hour && before%(24*time.Hour) == 0:
+		if before == 24*time.Hour {
+			return "завтра"
+		}
+		return fmt.Sprintf("через %d дн.", before/(24*time.Hour))
+	case before >= time.Hour && before%time.Hour == 0:
+		return fmt.Sprintf("через %d ч", before/time.Hour)
+	case before > 0:
+		return fmt.Sprintf("через %d мин", before/time.Minute)
+	default:
+		return "скоро"
+	}
+}
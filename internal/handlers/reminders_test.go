@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+type scheduledJob struct {
+	kind    string
+	key     string
+	runAt   time.Time
+	payload []byte
+}
+
+// fakeJobs запоминает запланированные задания и игнорирует повторы по ключу, как scheduled_jobs.
+type fakeJobs struct {
+	jobs []scheduledJob
+	keys map[string]bool
+}
+
+func (f *fakeJobs) Schedule(ctx context.Context, kind, key string, runAt time.Time, payload interface{}) (bool, error) {
+	if f.keys == nil {
+		f.keys = map[string]bool{}
+	}
+	if f.keys[key] {
+		return false, nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+	f.keys[key] = true
+	f.jobs = append(f.jobs, scheduledJob{kind: kind, key: key, runAt: runAt, payload: data})
+	return true, nil
+}
+
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+func newTestReminders(repo ReminderBookings, jobs *fakeJobs, rs *recordingSender, now time.Time) *BookingReminders {
+	r := NewBookingReminders(repo, jobs, rs, []time.Duration{24 * time.Hour, 2 * time.Hour}, moscow, zap.NewNop())
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func timedBooking() models.Booking {
+	b := newPendingBooking()
+	b.BookingTime = sql.NullString{String: "15:00", Valid: true}
+	return b
+}
+
+func TestReminders_Schedule(t *testing.T) {
+	jobs := &fakeJobs{}
+	repo := &fakeAdminRepo{booking: timedBooking()}
+	r := newTestReminders(repo, jobs, &recordingSender{}, time.Date(2026, 3, 1, 12, 0, 0, 0, moscow))
+
+	require.NoError(t, r.Schedule(context.Background(), 12))
+	require.Len(t, jobs.jobs, 2)
+	assert.Equal(t, JobBookingReminder, jobs.jobs[0].kind)
+	assert.True(t, jobs.jobs[0].runAt.Equal(time.Date(2026, 3, 13, 15, 0, 0, 0, moscow)))
+	assert.True(t, jobs.jobs[1].runAt.Equal(time.Date(2026, 3, 14, 13, 0, 0, 0, moscow)))
+
+	// повторный вызов хука не создаёт дублей
+	require.NoError(t, r.Schedule(context.Background(), 12))
+	assert.Len(t, jobs.jobs, 2)
+}
+
+func TestReminders_ScheduleSkipsPastReminders(t *testing.T) {
+	jobs := &fakeJobs{}
+	repo := &fakeAdminRepo{booking: timedBooking()}
+	r := newTestReminders(repo, jobs, &recordingSender{}, time.Date(2026, 3, 14, 9, 0, 0, 0, moscow))
+
+	require.NoError(t, r.Schedule(context.Background(), 12))
+	require.Len(t, jobs.jobs, 1)
+	assert.True(t, jobs.jobs[0].runAt.Equal(time.Date(2026, 3, 14, 13, 0, 0, 0, moscow)))
+}
+
+func TestReminders_Handle(t *testing.T) {
+	jobs := &fakeJobs{}
+	rs := &recordingSender{}
+	repo := &fakeAdminRepo{booking: timedBooking()}
+	r := newTestReminders(repo, jobs, rs, time.Date(2026, 3, 1, 12, 0, 0, 0, moscow))
+	require.NoError(t, r.Schedule(context.Background(), 12))
+
+	require.NoError(t, r.Handle(context.Background(), models.ScheduledJob{Kind: JobBookingReminder, Payload: jobs.jobs[0].payload}))
+	require.Len(t, rs.sent, 1)
+	assert.Equal(t, int64(777), rs.sent[0].chatID)
+	assert.Contains(t, rs.sent[0].text, "завтра")
+	assert.Contains(t, rs.sent[0].text, "14.03.2026 15:00")
+	assert.Equal(t, "booking_cancel_yes:12", rs.sent[0].buttons[0][0].CallbackData)
+}
+
+func TestReminders_HandleSkipsCancelledAndRescheduled(t *testing.T) {
+	jobs := &fakeJobs{}
+	rs := &recordingSender{}
+	repo := &fakeAdminRepo{booking: timedBooking()}
+	r := newTestReminders(repo, jobs, rs, time.Date(2026, 3, 1, 12, 0, 0, 0, moscow))
+	require.NoError(t, r.Schedule(context.Background(), 12))
+	job := models.ScheduledJob{Kind: JobBookingReminder, Payload: jobs.jobs[0].payload}
+
+	repo.booking.BookingTime = sql.NullString{String: "18:00", Valid: true}
+	require.NoError(t, r.Handle(context.Background(), job))
+
+	repo.booking = timedBooking()
+	repo.booking.Status = models.BookingStatusCancelled
+	require.NoError(t, r.Handle(context.Background(), job))
+
+	assert.Empty(t, rs.sent)
+}
+
+func TestReminderLead(t *testing.T) {
+	assert.Equal(t, "завтра", reminderLead(24*time.Hour))
+	assert.Equal(t, "через 2 дн.", reminderLead(48*time.Hour))
+	assert.Equal(t, "через 2 ч", reminderLead(2*time.Hour))
+	assert.Equal(t, "через 30 мин", reminderLead(30*time.Minute))
+}
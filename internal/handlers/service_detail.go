@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
+	"time"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/schedule"
 )
 
 // Button представляет интерактивную кнопку в интерфейсе сообщения.
@@ -29,93 +35,133 @@ func (d defaultSender) SendMessage(userID int64, text string, buttons [][]Button
 	return nil
 }
 
-// Service содержит данные об услуге.
-type Service struct {
-	ID          int
-	Title       string
-	Description string
-	Rules       string
-	Schedule    string // пустое, если не применяется
-	// Options непустой для услуг с несколькими типами посещения (например, галереи)
-	Options []string
-	// HasBooking указывает, что услуга поддерживает мгновенное бронирование (например, спорт)
-	HasBooking bool
+// ServiceCatalog — каталог услуг (см. repository.ServiceRepository).
+type ServiceCatalog interface {
+	GetServiceByID(ctx context.Context, serviceID int) (*models.Service, error)
+	GetServiceSchedule(ctx context.Context, serviceID int, from, to time.Time) (*schedule.Schedule, error)
 }
 
-// ErrServiceNotFound возвращается, когда услуга с указанным ID не найдена.
-var ErrServiceNotFound = fmt.Errorf("service not found")
-
-// inMemoryServices содержит примеры услуг и служит источником данных для хендлера.
-var inMemoryServices = map[int]Service{
-	1: {
-		ID:          1,
-		Title:       "🎨 Третьяковская галерея",
-		Description: "Государственная Третьяковская галерея — крупнейшее собрание русского искусства.",
-		Rules:       "Максимум 20 человек. Фото без вспышки.",
-		Schedule:    "ПН-СР: 10:00-18:00",
-		Options:     []string{"Приватный тур", "Групповой тур"},
-		HasBooking:  false,
-	},
-	2: {
-		ID:          2,
-		Title:       "🏋️ Спортзал Dynamo",
-		Description: "Современный спортивный комплекс с тренажёрным залом и бассейном.",
-		Rules:       "Вход по абонементам и предварительной записи.",
-		Schedule:    "Ежедневно: 06:00-23:00",
-		Options:     nil,
-		HasBooking:  true,
-	},
-}
+// ErrServiceNotFound возвращается, когда услуги с указанным ID нет или она отключена.
+var ErrServiceNotFound = repository.ErrServiceNotFound
+
+const serviceUnavailableMessage = "Эта услуга больше недоступна."
+
+// scheduleNoticeDays — за сколько дней вперёд карточка предупреждает об изменениях в расписании.
+const scheduleNoticeDays = 14
 
 // buildButtons формирует кнопки ответа в соответствии с настройками услуги.
-func buildButtons(s Service) [][]Button {
+// back — callback кнопки «Назад», пустой — возврат к каталогу.
+func buildButtons(s models.Service, back string) [][]Button {
 	var row []Button
 	// Для услуг с опциями (например, галереи) — отдельные варианты посещения
-	if len(s.Options) > 0 {
-		for idx, opt := range s.Options {
+	if len(s.VisitOptions) > 0 {
+		for idx, opt := range s.VisitOptions {
 			cb := fmt.Sprintf("option:%d:%d", s.ID, idx) // option:<serviceID>:<optionIdx> — формат callback для опции
-			row = append(row, Button{Text: opt, CallbackData: cb})
+			row = append(row, Button{Text: opt.Title, CallbackData: cb})
 		}
 	} else if s.HasBooking {
 		// Для услуг с расписанием и возможностью бронирования
 		row = append(row, Button{Text: "Забронировать", CallbackData: fmt.Sprintf("book_now:%d", s.ID)})
 	}
 	// Всегда добавляем кнопку 'Назад'
-	row = append(row, Button{Text: "Назад", CallbackData: "back_to_box_solutions"})
+	if back == "" {
+		back = CallbackBackToBoxSolutions
+	}
+	row = append(row, Button{Text: "Назад", CallbackData: back})
 	return [][]Button{row}
 }
 
 // composeMessage формирует текст сообщения для услуги.
-func composeMessage(s Service) string {
+func composeMessage(s models.Service) string {
 	parts := []string{}
 	parts = append(parts, s.Title)
 	parts = append(parts, "")
 	parts = append(parts, "Описание: "+s.Description)
 	parts = append(parts, "Правила: "+s.Rules)
-	if strings.TrimSpace(s.Schedule) != "" {
-		parts = append(parts, "Расписание: "+s.Schedule)
+	if !s.Schedule.IsZero() {
+		parts = append(parts, "Расписание:\n"+s.Schedule.String())
 	}
 	return strings.Join(parts, "\n")
 }
 
+// composeScheduleChanges перечисляет дни, когда услуга работает не по недельному расписанию:
+// праздники, закрытия и особые часы. Пустая строка — изменений нет.
+func composeScheduleChanges(days []schedule.Day) string {
+	var lines []string
+	for _, d := range days {
+		if !d.Special {
+			continue
+		}
+		line := d.Date.Format("02.01") + " — " + schedule.FormatHours(d.Hours)
+		if d.Note != "" {
+			line += " (" + d.Note + ")"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "Изменения в расписании:\n" + strings.Join(lines, "\n")
+}
+
 // HandleServiceDetail формирует и отправляет сообщение с деталями услуги указанному пользователю.
-// Логирует user_id и service_id и возвращает ошибку, если услуга не найдена или отправка неудачна.
-func HandleServiceDetail(serviceID int, userID int64) error {
+// Если у услуги есть фотографии и задан cards, карточка отправляется с ними, иначе — текстом.
+// back — callback кнопки «Назад», например страница списка, с которой открыли карточку.
+// Логирует user_id и service_id и возвращает ErrServiceNotFound, если услуги нет в каталоге
+// или она отключена, и ошибку отправки.
+func HandleServiceDetail(ctx context.Context, services ServiceCatalog, cards *ServiceCards, serviceID int, userID int64, back string) error {
 	log.Printf("HandleServiceDetail called: user_id=%d, service_id=%d", userID, serviceID)
-	service, ok := inMemoryServices[serviceID]
-	if !ok {
+	service, err := services.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+	if !service.IsActive {
 		return ErrServiceNotFound
 	}
 
-	msg := composeMessage(service)
-	buttons := buildButtons(service)
+	msg := composeMessage(*service)
+	if notice := upcomingScheduleChanges(ctx, services, serviceID); notice != "" {
+		msg += "\n\n" + notice
+	}
+	buttons := buildButtons(*service, back)
 	// Добавляем подсказку, если у услуги есть опции
-	if len(service.Options) > 0 {
-		msg += "\n\nВыберите тип посещения:"
+	var prompt string
+	if len(service.VisitOptions) > 0 {
+		prompt = "Выберите тип посещения:"
+	}
+
+	if cards != nil {
+		sent, err := cards.Send(ctx, userID, serviceID, msg, prompt, buttons)
+		if sent {
+			return err
+		}
 	}
 
-	if err := Sender.SendMessage(userID, msg, buttons); err != nil {
+	if err := Sender.SendMessage(userID, joinNonEmpty(msg, prompt), buttons); err != nil {
 		return fmt.Errorf("send message: %w", err)
 	}
 	return nil
 }
+
+// upcomingScheduleChanges возвращает изменения в расписании на ближайшие scheduleNoticeDays дней.
+// Если расписание не загрузилось, карточка показывается без них.
+func upcomingScheduleChanges(ctx context.Context, services ServiceCatalog, serviceID int) string {
+	from := time.Now()
+	sched, err := services.GetServiceSchedule(ctx, serviceID, from, from.AddDate(0, 0, scheduleNoticeDays-1))
+	if err != nil {
+		log.Printf("HandleServiceDetail: get schedule for service_id=%d: %v", serviceID, err)
+		return ""
+	}
+	if sched.Location != nil {
+		from = from.In(sched.Location)
+	}
+	return composeScheduleChanges(sched.Days(from, scheduleNoticeDays))
+}
+
+// sendServiceUnavailable отвечает на кнопку услуги, которую убрали из каталога.
+// back — callback кнопки «Назад».
+func sendServiceUnavailable(userID int64, back string) error {
+	return Sender.SendMessage(userID, serviceUnavailableMessage, [][]Button{
+		{{Text: "Назад", CallbackData: back}},
+	})
+}
@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/schedule"
 )
 
 type fakeSender struct {
@@ -17,12 +23,75 @@ func (f *fakeSender) SendMessage(userID int64, text string, buttons [][]Button)
 	return nil
 }
 
+// fakeCatalog — каталог услуг в памяти.
+type fakeCatalog map[int]models.Service
+
+func (f fakeCatalog) GetServiceByID(ctx context.Context, serviceID int) (*models.Service, error) {
+	s, ok := f[serviceID]
+	if !ok {
+		return nil, ErrServiceNotFound
+	}
+	return &s, nil
+}
+
+func (f fakeCatalog) GetServiceSchedule(ctx context.Context, serviceID int, from, to time.Time) (*schedule.Schedule, error) {
+	s, ok := f[serviceID]
+	if !ok {
+		return nil, ErrServiceNotFound
+	}
+	return &schedule.Schedule{Weekly: s.Schedule}, nil
+}
+
+// holidayCatalog добавляет к каталогу праздник на завтра.
+type holidayCatalog struct{ fakeCatalog }
+
+func (f holidayCatalog) GetServiceSchedule(ctx context.Context, serviceID int, from, to time.Time) (*schedule.Schedule, error) {
+	sched, err := f.fakeCatalog.GetServiceSchedule(ctx, serviceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sched.Holidays = []schedule.Holiday{{Date: from.AddDate(0, 0, 1), Title: "День России"}}
+	return sched, nil
+}
+
+func mustWeekly(s string) schedule.Weekly {
+	w, err := schedule.ParseWeekly(s)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+var testCatalog = fakeCatalog{
+	1: {
+		ID:          1,
+		Title:       "Третьяковская галерея",
+		Description: "Крупнейшее собрание русского искусства.",
+		Rules:       "Максимум 20 человек. Фото без вспышки.",
+		Schedule:    mustWeekly("ежедневно: 10:00-18:00"),
+		VisitOptions: models.VisitOptions{
+			{Title: "Приватный тур", VisitType: "private"},
+			{Title: "Групповой тур", VisitType: "public"},
+		},
+		IsActive: true,
+	},
+	2: {
+		ID:          2,
+		Title:       "Теннис в Лужниках",
+		Description: "Крытые теннисные корты.",
+		Rules:       "Бронирование по часу.",
+		HasBooking:  true,
+		IsActive:    true,
+	},
+	3: {ID: 3, Title: "Архивная услуга", HasBooking: true},
+}
+
 func TestHandleServiceDetail_Gallery(t *testing.T) {
 	fs := &fakeSender{}
 	Sender = fs
 	defer func() { Sender = defaultSender{} }()
 
-	if err := HandleServiceDetail(1, 42); err != nil {
+	if err := HandleServiceDetail(context.Background(), testCatalog, nil, 1, 42, ""); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -54,7 +123,7 @@ func TestHandleServiceDetail_Sport(t *testing.T) {
 	Sender = fs
 	defer func() { Sender = defaultSender{} }()
 
-	if err := HandleServiceDetail(2, 100); err != nil {
+	if err := HandleServiceDetail(context.Background(), testCatalog, nil, 2, 100, ""); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -68,11 +137,37 @@ func TestHandleServiceDetail_NotFound(t *testing.T) {
 	Sender = fs
 	defer func() { Sender = defaultSender{} }()
 
-	if err := HandleServiceDetail(999, 1); err == nil {
+	if err := HandleServiceDetail(context.Background(), testCatalog, nil, 999, 1, ""); err == nil {
 		t.Fatalf("expected error for unknown service, got nil")
 	}
 }
 
+func TestHandleServiceDetail_Inactive(t *testing.T) {
+	fs := &fakeSender{}
+	Sender = fs
+	defer func() { Sender = defaultSender{} }()
+
+	if err := HandleServiceDetail(context.Background(), testCatalog, nil, 3, 1, ""); err != ErrServiceNotFound {
+		t.Fatalf("expected ErrServiceNotFound for inactive service, got %v", err)
+	}
+	if fs.lastText != "" {
+		t.Fatalf("expected no message, got %q", fs.lastText)
+	}
+}
+
+func TestBuildButtons_VisitOptions(t *testing.T) {
+	row := buildButtons(testCatalog[1], "")[0]
+	if len(row) != 3 {
+		t.Fatalf("expected 2 options and back, got %d buttons", len(row))
+	}
+	if row[0].Text != "Приватный тур" || row[0].CallbackData != "option:1:0" {
+		t.Fatalf("unexpected first option: %+v", row[0])
+	}
+	if row[1].CallbackData != "option:1:1" {
+		t.Fatalf("unexpected second option: %+v", row[1])
+	}
+}
+
 // вспомогательные функции
 func contains(s, sub string) bool {
 	return len(s) >= len(sub) && (s == sub || (len(s) > len(sub) && (index(s, sub) >= 0)))
@@ -97,3 +192,27 @@ func hasBookingButton(btns [][]Button) bool {
 	}
 	return false
 }
+
+func TestHandleServiceDetail_ScheduleChanges(t *testing.T) {
+	fs := &fakeSender{}
+	Sender = fs
+	defer func() { Sender = defaultSender{} }()
+
+	if err := HandleServiceDetail(context.Background(), holidayCatalog{testCatalog}, nil, 1, 42, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(fs.lastText, "Ежедневно: 10:00-18:00") {
+		t.Fatalf("expected weekly hours in card: %s", fs.lastText)
+	}
+	if !strings.Contains(fs.lastText, "Изменения в расписании:") || !strings.Contains(fs.lastText, "выходной (День России)") {
+		t.Fatalf("expected holiday notice in card: %s", fs.lastText)
+	}
+
+	// без особых дней блок не показывается
+	if err := HandleServiceDetail(context.Background(), testCatalog, nil, 1, 42, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(fs.lastText, "Изменения в расписании") {
+		t.Fatalf("unexpected schedule notice: %s", fs.lastText)
+	}
+}
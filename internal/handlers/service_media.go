@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+const (
+	// maxCaptionLength — лимит Telegram на подпись к фото. Более длинная карточка уходит
+	// отдельным сообщением под фотографиями.
+	maxCaptionLength = 1024
+	// maxMediaGroupSize — сколько фотографий Telegram принимает в одном альбоме.
+	maxMediaGroupSize = 10
+
+	serviceCardKeyboardText = "Выберите действие:"
+)
+
+// SentPhoto — отправленная фотография: её file_id в Telegram и сообщение, в котором она пришла.
+type SentPhoto struct {
+	FileID    string
+	MessageID int
+}
+
+// PhotoSender отправляет фотографии пользователю и сообщает о каждой отправленной фотографии.
+// SendText и DeleteMessages нужны для сообщения с кнопками под альбомом: по нему «Назад»
+// убирает фотографии карточки.
+type PhotoSender interface {
+	SendPhoto(userID int64, photo models.ServicePhoto, caption string, buttons [][]Button) (SentPhoto, error)
+	SendMediaGroup(userID int64, photos []models.ServicePhoto, caption string) ([]SentPhoto, error)
+	SendText(userID int64, text string, buttons [][]Button) (int, error)
+	DeleteMessages(userID int64, messageIDs []int) error
+}
+
+// ServicePhotos — фотографии карточек услуг (см. repository.ServiceRepository).
+type ServicePhotos interface {
+	ListServicePhotos(ctx context.Context, serviceID int) ([]models.ServicePhoto, error)
+	SetServicePhotoFileID(ctx context.Context, photoID int64, fileID string) error
+}
+
+// ServiceCards отправляет карточки услуг с фотографиями: одна фотография — с подписью и кнопками,
+// несколько — альбомом и отдельным сообщением с кнопками, потому что у альбома не бывает клавиатуры.
+type ServiceCards struct {
+	photos   ServicePhotos
+	media    PhotoSender
+	mediaDir string
+	logger   *zap.Logger
+
+	mu sync.Mutex
+	// attached — фотографии последней карточки пользователя, отправленные отдельно от кнопок
+	attached map[int64]attachedPhotos
+}
+
+// attachedPhotos — сообщения с фотографиями над сообщением с кнопками keyboard.
+type attachedPhotos struct {
+	keyboard int
+	photos   []int
+}
+
+// NewServiceCards создаёт отправщик карточек. mediaDir — каталог, от которого считаются
+// относительные пути фотографий.
+func NewServiceCards(photos ServicePhotos, media PhotoSender, mediaDir string, logger *zap.Logger) *ServiceCards {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ServiceCards{photos: photos, media: media, mediaDir: mediaDir, logger: logger, attached: map[int64]attachedPhotos{}}
+}
+
+// Send отправляет карточку услуги с фотографиями. text — описание услуги, prompt — подсказка
+// над кнопками, может быть пустой. Возвращает false, если фотографий нет или отправить их
+// не удалось: тогда карточку нужно отправить текстом.
+func (c *ServiceCards) Send(ctx context.Context, userID int64, serviceID int, text, prompt string, buttons [][]Button) (bool, error) {
+	photos, err := c.photos.ListServicePhotos(ctx, serviceID)
+	if err != nil {
+		c.logger.Warn("service_photos_unavailable", zap.Error(err), zap.Int("service_id", serviceID))
+		return false, nil
+	}
+	if len(photos) == 0 {
+		return false, nil
+	}
+	if len(photos) > maxMediaGroupSize {
+		photos = photos[:maxMediaGroupSize]
+	}
+	for i := range photos {
+		photos[i].Path = c.resolve(photos[i].Path)
+	}
+
+	full := joinNonEmpty(text, prompt)
+	var sent []SentPhoto
+	if len(photos) == 1 {
+		caption, keyboard := full, buttons
+		if !fitsCaption(full) {
+			caption, keyboard = "", nil
+		}
+		photo, err := c.media.SendPhoto(userID, photos[0], caption, keyboard)
+		if err != nil {
+			c.logger.Warn("service_photo_send_failed", zap.Error(err), zap.Int64("user_id", userID), zap.Int("service_id", serviceID))
+			return false, nil
+		}
+		sent = []SentPhoto{photo}
+		if keyboard != nil {
+			c.remember(ctx, photos, sent)
+			return true, nil
+		}
+	} else {
+		caption := text
+		if !fitsCaption(text) {
+			caption = ""
+		}
+		sent, err = c.media.SendMediaGroup(userID, photos, caption)
+		if err != nil {
+			c.logger.Warn("service_photo_send_failed", zap.Error(err), zap.Int64("user_id", userID), zap.Int("service_id", serviceID))
+			return false, nil
+		}
+	}
+	c.remember(ctx, photos, sent)
+
+	// кнопки (и текст, если он не поместился в подпись) — отдельным сообщением под фотографиями
+	follow := prompt
+	if len(photos) == 1 || !fitsCaption(text) {
+		follow = full
+	}
+	if follow == "" {
+		follow = serviceCardKeyboardText
+	}
+	keyboard, err := c.media.SendText(userID, follow, buttons)
+	if err != nil {
+		return true, fmt.Errorf("send card keyboard: %w", err)
+	}
+	c.attach(userID, keyboard, sent)
+	return true, nil
+}
+
+// Dismiss удаляет фотографии, отправленные над сообщением с кнопками messageID, —
+// например, когда из карточки возвращаются «Назад». Сообщение с кнопками вызывающий
+// редактирует сам. Если над этим сообщением фотографий нет, ничего не делает.
+func (c *ServiceCards) Dismiss(userID int64, messageID int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	a, ok := c.attached[userID]
+	if ok && a.keyboard == messageID {
+		delete(c.attached, userID)
+	}
+	c.mu.Unlock()
+	if !ok || a.keyboard != messageID {
+		return
+	}
+	if err := c.media.DeleteMessages(userID, a.photos); err != nil {
+		c.logger.Warn("service_photos_not_deleted", zap.Error(err), zap.Int64("user_id", userID), zap.Int("message_id", messageID))
+	}
+}
+
+// attach запоминает фотографии карточки, отправленные отдельно от кнопок. Хранится только
+// последняя карточка пользователя: фотографии старых карточек остаются в чате.
+func (c *ServiceCards) attach(userID int64, keyboard int, sent []SentPhoto) {
+	ids := make([]int, 0, len(sent))
+	for _, p := range sent {
+		if p.MessageID != 0 {
+			ids = append(ids, p.MessageID)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attached[userID] = attachedPhotos{keyboard: keyboard, photos: ids}
+}
+
+// remember сохраняет file_id загруженных фотографий, чтобы в следующий раз не загружать файлы снова.
+func (c *ServiceCards) remember(ctx context.Context, photos []models.ServicePhoto, sent []SentPhoto) {
+	for i, p := range photos {
+		if p.FileID != "" || i >= len(sent) || sent[i].FileID == "" {
+			continue
+		}
+		if err := c.photos.SetServicePhotoFileID(ctx, p.ID, sent[i].FileID); err != nil {
+			c.logger.Warn("service_photo_file_id_not_saved", zap.Error(err), zap.Int64("photo_id", p.ID))
+		}
+	}
+}
+
+func (c *ServiceCards) resolve(path string) string {
+	if path == "" || c.mediaDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.mediaDir, path)
+}
+
+func fitsCaption(s string) bool {
+	return utf8.RuneCountInString(s) <= maxCaptionLength
+}
+
+func joinNonEmpty(parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// fakePhotoRepo — фотографии услуг в памяти.
+type fakePhotoRepo struct {
+	photos map[int][]models.ServicePhoto
+	saved  map[int64]string
+}
+
+func (f *fakePhotoRepo) ListServicePhotos(ctx context.Context, serviceID int) ([]models.ServicePhoto, error) {
+	return append([]models.ServicePhoto(nil), f.photos[serviceID]...), nil
+}
+
+func (f *fakePhotoRepo) SetServicePhotoFileID(ctx context.Context, photoID int64, fileID string) error {
+	if f.saved == nil {
+		f.saved = map[int64]string{}
+	}
+	f.saved[photoID] = fileID
+	return nil
+}
+
+// fakePhotoSender запоминает отправленные фотографии и выдаёт file_id по их ID,
+// а сообщениям — номера по порядку отправки.
+type fakePhotoSender struct {
+	sent     []models.ServicePhoto
+	caption  string
+	buttons  [][]Button
+	album    bool
+	text     string
+	textBtns [][]Button
+	lastID   int
+	deleted  []int
+	err      error
+}
+
+func (f *fakePhotoSender) SendPhoto(userID int64, photo models.ServicePhoto, caption string, buttons [][]Button) (SentPhoto, error) {
+	if f.err != nil {
+		return SentPhoto{}, f.err
+	}
+	f.sent, f.caption, f.buttons = []models.ServicePhoto{photo}, caption, buttons
+	f.lastID++
+	return SentPhoto{FileID: fileIDFor(photo), MessageID: f.lastID}, nil
+}
+
+func (f *fakePhotoSender) SendMediaGroup(userID int64, photos []models.ServicePhoto, caption string) ([]SentPhoto, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.sent, f.caption, f.album = photos, caption, true
+	out := make([]SentPhoto, len(photos))
+	for i, p := range photos {
+		f.lastID++
+		out[i] = SentPhoto{FileID: fileIDFor(p), MessageID: f.lastID}
+	}
+	return out, nil
+}
+
+func (f *fakePhotoSender) SendText(userID int64, text string, buttons [][]Button) (int, error) {
+	f.text, f.textBtns = text, buttons
+	f.lastID++
+	return f.lastID, nil
+}
+
+func (f *fakePhotoSender) DeleteMessages(userID int64, messageIDs []int) error {
+	f.deleted = append(f.deleted, messageIDs...)
+	return nil
+}
+
+func fileIDFor(p models.ServicePhoto) string {
+	if p.FileID != "" {
+		return p.FileID
+	}
+	return fmt.Sprintf("file-%d", p.ID)
+}
+
+func newCardsTest(photos ...models.ServicePhoto) (*ServiceCards, *fakePhotoRepo, *fakePhotoSender, *fakeSender) {
+	repo := &fakePhotoRepo{photos: map[int][]models.ServicePhoto{1: photos}}
+	media := &fakePhotoSender{}
+	fs := &fakeSender{}
+	Sender = fs
+	return NewServiceCards(repo, media, "/srv/media", nil), repo, media, fs
+}
+
+func TestServiceCards_SinglePhotoWithCaption(t *testing.T) {
+	cards, repo, media, _ := newCardsTest(models.ServicePhoto{ID: 5, ServiceID: 1, Path: "gallery/hall.jpg"})
+	defer func() { Sender = defaultSender{} }()
+
+	buttons := buildButtons(testCatalog[1], "")
+	sent, err := cards.Send(context.Background(), 42, 1, "Третьяковская галерея", "Выберите тип посещения:", buttons)
+	if err != nil || !sent {
+		t.Fatalf("expected photo card, sent=%v err=%v", sent, err)
+	}
+	if media.album || len(media.sent) != 1 {
+		t.Fatalf("expected a single photo, got %+v", media.sent)
+	}
+	if media.sent[0].Path != filepath.Join("/srv/media", "gallery/hall.jpg") {
+		t.Fatalf("path must be resolved against media dir, got %q", media.sent[0].Path)
+	}
+	if media.caption != "Третьяковская галерея\n\nВыберите тип посещения:" || len(media.buttons) == 0 {
+		t.Fatalf("caption and keyboard must be on the photo, got %q %+v", media.caption, media.buttons)
+	}
+	if media.text != "" {
+		t.Fatalf("no separate message expected, got %q", media.text)
+	}
+	if repo.saved[5] != "file-5" {
+		t.Fatalf("uploaded file_id must be saved, got %+v", repo.saved)
+	}
+}
+
+func TestServiceCards_AlbumSendsKeyboardSeparately(t *testing.T) {
+	cards, repo, media, _ := newCardsTest(
+		models.ServicePhoto{ID: 5, ServiceID: 1, FileID: "cached"},
+		models.ServicePhoto{ID: 6, ServiceID: 1, Path: "gallery/room.jpg"},
+	)
+	defer func() { Sender = defaultSender{} }()
+
+	buttons := buildButtons(testCatalog[2], "")
+	sent, err := cards.Send(context.Background(), 42, 1, "Теннис в Лужниках", "", buttons)
+	if err != nil || !sent {
+		t.Fatalf("expected album, sent=%v err=%v", sent, err)
+	}
+	if !media.album || len(media.sent) != 2 || media.caption != "Теннис в Лужниках" {
+		t.Fatalf("unexpected album: %+v caption=%q", media.sent, media.caption)
+	}
+	if media.text != serviceCardKeyboardText || len(media.textBtns) == 0 {
+		t.Fatalf("keyboard must follow the album, got %q %+v", media.text, media.textBtns)
+	}
+	if _, ok := repo.saved[5]; ok || repo.saved[6] != "file-6" {
+		t.Fatalf("only new uploads must be saved, got %+v", repo.saved)
+	}
+}
+
+func TestServiceCards_LongTextGoesBelowPhoto(t *testing.T) {
+	cards, _, media, _ := newCardsTest(models.ServicePhoto{ID: 5, ServiceID: 1, FileID: "cached"})
+	defer func() { Sender = defaultSender{} }()
+
+	text := strings.Repeat("я", maxCaptionLength+1)
+	sent, err := cards.Send(context.Background(), 42, 1, text, "", buildButtons(testCatalog[2], ""))
+	if err != nil || !sent {
+		t.Fatalf("expected photo card, sent=%v err=%v", sent, err)
+	}
+	if media.caption != "" || media.buttons != nil {
+		t.Fatalf("photo must go without caption, got %q %+v", media.caption, media.buttons)
+	}
+	if media.text != text || len(media.textBtns) == 0 {
+		t.Fatalf("text with keyboard must follow the photo")
+	}
+}
+
+func TestServiceCards_DismissDeletesAlbum(t *testing.T) {
+	cards, _, media, _ := newCardsTest(
+		models.ServicePhoto{ID: 5, ServiceID: 1, FileID: "cached"},
+		models.ServicePhoto{ID: 6, ServiceID: 1, FileID: "cached-6"},
+	)
+	defer func() { Sender = defaultSender{} }()
+
+	if _, err := cards.Send(context.Background(), 42, 1, "Теннис в Лужниках", "", buildButtons(testCatalog[2], "")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// альбом — сообщения 1 и 2, кнопки — 3
+
+	cards.Dismiss(42, 99)
+	if len(media.deleted) != 0 {
+		t.Fatalf("photos of another message must stay, deleted %v", media.deleted)
+	}
+
+	cards.Dismiss(42, 3)
+	if len(media.deleted) != 2 || media.deleted[0] != 1 || media.deleted[1] != 2 {
+		t.Fatalf("album must be deleted, got %v", media.deleted)
+	}
+
+	cards.Dismiss(42, 3)
+	if len(media.deleted) != 2 {
+		t.Fatalf("album must be deleted once, got %v", media.deleted)
+	}
+}
+
+func TestHandleServiceDetail_FallsBackToText(t *testing.T) {
+	cards, _, media, fs := newCardsTest(models.ServicePhoto{ID: 5, ServiceID: 1, Path: "missing.jpg"})
+	defer func() { Sender = defaultSender{} }()
+	media.err = errors.New("file not found")
+
+	if err := HandleServiceDetail(context.Background(), testCatalog, cards, 1, 42, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(fs.lastText, "Третьяковская галерея") || !strings.HasSuffix(fs.lastText, "Выберите тип посещения:") {
+		t.Fatalf("expected text card, got %q", fs.lastText)
+	}
+}
+
+func TestHandleServiceDetail_NoPhotos(t *testing.T) {
+	cards, _, media, fs := newCardsTest()
+	defer func() { Sender = defaultSender{} }()
+
+	if err := HandleServiceDetail(context.Background(), testCatalog, cards, 2, 42, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(media.sent) != 0 || !strings.HasPrefix(fs.lastText, "Теннис в Лужниках") {
+		t.Fatalf("expected text card, got photos=%+v text=%q", media.sent, fs.lastText)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"time"
 
 	"go.uber.org/zap"
 
@@ -15,30 +14,11 @@ import (
 
 const welcomeMessage = "👋 Добро пожаловать в Bot Яндекса!\n\nВыберите интересующую вас опцию:"
 
-func HandleStart(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, logger *zap.Logger, db *sql.DB) error {
+func HandleStart(ctx context.Context, bot *tgbotapi.BotAPI, msg *tgbotapi.Message, logger *zap.Logger, db *sql.DB) error {
 	if msg == nil || msg.From == nil {
 		return fmt.Errorf("invalid message from user")
 	}
 
-	start := time.Now()
-	// ActiveUsers: считаем “активных” как количество одновременно обрабатываемых запросов
-	metrics.Default.ActiveUsers.Inc()
-	defer metrics.Default.ActiveUsers.Dec()
-
-	metrics.Default.MessagesReceived.Inc()
-
-	var handlerErr error
-	defer func() {
-		dur := time.Since(start).Seconds()
-		metrics.Default.MessageProcessingDuration.Observe(dur)
-
-		logger.Info("handle_start_metrics",
-			zap.Int64("user_id", msg.From.ID),
-			zap.Float64("duration_seconds", dur),
-			zap.Bool("success", handlerErr == nil),
-		)
-	}()
-
 	user := msg.From
 
 	logger.Info("received start command",
@@ -49,10 +29,9 @@ func HandleStart(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, logger *zap.Logger
 
 	adapter := repository.NewDBAdapter(db)
 	userRepo := repository.NewUserRepository(adapter, logger)
-	newUser, err, isNew := userRepo.CreateUser(context.Background(), msg.From.ID, msg.From.UserName, msg.From.FirstName, msg.From.LastName)
+	newUser, err, isNew := userRepo.CreateUser(ctx, msg.From.ID, msg.From.UserName, msg.From.FirstName, msg.From.LastName)
 	if err != nil {
 		logger.Error(err.Error())
-		tgbotapi.NewMessage(msg.Chat.ID, "Произошла ошибка, попробуйте позже")
 		return err
 	}
 	if isNew {
@@ -60,13 +39,9 @@ func HandleStart(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, logger *zap.Logger
 			zap.Int64("user_id", user.ID),
 			zap.String("username", user.UserName))
 	} else if newUser.Username != user.UserName {
-		err = userRepo.UpdateUserUsername(context.Background(), newUser.TelegramID, user.UserName)
+		err = userRepo.UpdateUserUsername(ctx, newUser.TelegramID, user.UserName)
 		if err != nil {
 			logger.Error("failed to update username", zap.Error(err))
-			errMsg := tgbotapi.NewMessage(msg.Chat.ID, "Произошла ошибка, попробуйте позже")
-			if _, sendErr := bot.Send(errMsg); sendErr != nil {
-				logger.Error("failed to send error message to user", zap.Error(sendErr))
-			}
 			return err
 		}
 	}
@@ -81,6 +56,9 @@ func HandleStart(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, logger *zap.Logger
 			tgbotapi.NewInlineKeyboardButtonData("Запрос спецпроекта", "special_project"),
 			tgbotapi.NewInlineKeyboardButtonData("Примеры спецпроектов", "project_examples"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Мои бронирования", CallbackMyBookings),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("О нас", "about_us"),
 			tgbotapi.NewInlineKeyboardButtonData("Связь с поддержкой", "support"),
@@ -94,9 +72,6 @@ func HandleStart(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, logger *zap.Logger
 
 	//обрабатываем ошибку отправки
 	if _, err := bot.Send(message); err != nil {
-		handlerErr = err
-		metrics.Default.MessagesErrorsTotal.Inc()
-
 		logger.Error("failed to send message",
 			zap.Int64("user_id", user.ID),
 			zap.Int64("chat_id", msg.Chat.ID),
@@ -112,3 +87,21 @@ func HandleStart(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, logger *zap.Logger
 
 	return nil
 }
+
+const unknownMessage = "Не понимаю это сообщение 🤔\n\nНажмите /start, чтобы открыть главное меню."
+
+// HandleUnknownMessage отвечает на текст и команды, для которых нет обработчика.
+func HandleUnknownMessage(bot *tgbotapi.BotAPI, msg *tgbotapi.Message, logger *zap.Logger) error {
+	if msg == nil || msg.Chat == nil {
+		return fmt.Errorf("invalid message from user")
+	}
+
+	if _, err := bot.Send(tgbotapi.NewMessage(msg.Chat.ID, unknownMessage)); err != nil {
+		logger.Error("failed to send unknown message reply",
+			zap.Int64("chat_id", msg.Chat.ID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to send unknown message reply: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+	"github.com/yandex-development-2-team/Go/internal/scheduler"
+)
+
+const (
+	CallbackWaitlistClaim   = "waitlist_claim:{id:int}"
+	CallbackWaitlistDecline = "waitlist_decline:{id:int}"
+)
+
+const waitlistOfferGoneMessage = "Это предложение уже не действует: время на ответ истекло или место передано следующему в очереди."
+
+// WaitlistRepository — операции с предложениями из листа ожидания.
+type WaitlistRepository interface {
+	GetEntry(ctx context.Context, entryID int64) (*models.WaitlistEntry, error)
+	ClaimOffer(ctx context.Context, telegramID, entryID int64) (int64, error)
+	DeclineOffer(ctx context.Context, telegramID, entryID int64) error
+	ExpireOffer(ctx context.Context, entryID int64) (bool, error)
+}
+
+// WaitlistHandler рассылает предложения освободившихся мест и обрабатывает ответы на них.
+type WaitlistHandler struct {
+	repo      WaitlistRepository
+	sender    MessageSender
+	loc       *time.Location
+	logger    *zap.Logger
+	onCreated []BookingHook
+}
+
+// NewWaitlistHandler создаёт обработчик листа ожидания. loc — часовой пояс,
+// в котором пользователю показывается срок действия предложения.
+func NewWaitlistHandler(repo WaitlistRepository, sender MessageSender, loc *time.Location, logger *zap.Logger) *WaitlistHandler {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &WaitlistHandler{repo: repo, sender: sender, loc: loc, logger: logger}
+}
+
+// OnBookingCreated регистрирует хук, вызываемый после бронирования по предложению.
+func (h *WaitlistHandler) OnBookingCreated(hook BookingHook) {
+	h.onCreated = append(h.onCreated, hook)
+}
+
+// HandleOfferJob отправляет пользователю предложение занять освободившееся место.
+func (h *WaitlistHandler) HandleOfferJob(ctx context.Context, job models.ScheduledJob) error {
+	e, ok, err := h.loadJobEntry(ctx, job)
+	if err != nil || !ok {
+		return err
+	}
+	if e.Status != models.WaitlistStatusOffered {
+		h.logger.Info("waitlist_offer_skipped", zap.Int64("entry_id", e.ID), zap.String("status", string(e.Status)))
+		return nil
+	}
+
+	text := fmt.Sprintf("🎉 Освободилось место: «%s», %s.\n\nМесто закреплено за вами до %s — подтвердите бронирование, иначе оно перейдёт следующему в очереди.",
+		e.ServiceTitle, e.When(), e.OfferExpiresAt.Time.In(h.loc).Format("15:04"))
+	buttons := [][]Button{
+		{
+			{Text: "✅ Забронировать", CallbackData: fmt.Sprintf("waitlist_claim:%d", e.ID)},
+			{Text: "Отказаться", CallbackData: fmt.Sprintf("waitlist_decline:%d", e.ID)},
+		},
+	}
+	if err := h.sender.SendMessage(e.UserTelegramID, text, buttons); err != nil {
+		return fmt.Errorf("send waitlist offer: %w", err)
+	}
+
+	h.logger.Info("waitlist_offer_sent", zap.Int64("entry_id", e.ID), zap.Int64("telegram_id", e.UserTelegramID))
+	return nil
+}
+
+// HandleExpireJob закрывает неотвеченное предложение, чтобы место получил следующий в очереди.
+func (h *WaitlistHandler) HandleExpireJob(ctx context.Context, job models.ScheduledJob) error {
+	e, ok, err := h.loadJobEntry(ctx, job)
+	if err != nil || !ok {
+		return err
+	}
+
+	expired, err := h.repo.ExpireOffer(ctx, e.ID)
+	if err != nil {
+		return fmt.Errorf("expire waitlist offer: %w", err)
+	}
+	if !expired {
+		return nil
+	}
+
+	h.logger.Info("waitlist_offer_expired", zap.Int64("entry_id", e.ID))
+	text := fmt.Sprintf("Время на ответ истекло, место на «%s», %s передано следующему в очереди.", e.ServiceTitle, e.When())
+	if err := h.sender.SendMessage(e.UserTelegramID, text, nil); err != nil {
+		// предложение уже закрыто, повтор задания ничего не изменит
+		h.logger.Warn("waitlist_expired_notify_failed", zap.Int64("entry_id", e.ID), zap.Error(err))
+	}
+	return nil
+}
+
+// Claim бронирует предложенное место. Ожидает маршрут CallbackWaitlistClaim.
+func (h *WaitlistHandler) Claim(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	id, ok := CallbackArgsFromContext(ctx).Int("id")
+	if !ok {
+		return fmt.Errorf("waitlist entry id is missing in callback %q", q.Data)
+	}
+
+	bookingID, err := h.repo.ClaimOffer(ctx, q.From.ID, int64(id))
+	switch {
+	case errors.Is(err, repository.ErrWaitlistEntryNotFound), errors.Is(err, repository.ErrOfferExpired):
+		return h.sender.SendMessage(q.From.ID, waitlistOfferGoneMessage, nil)
+	case errors.Is(err, repository.ErrGradeNotAllowed):
+		return h.sender.SendMessage(q.From.ID, gradeNotAllowedMessage, nil)
+	case errors.Is(err, repository.ErrMonthlyLimitReached):
+		return h.sender.SendMessage(q.From.ID, monthlyLimitMessage, nil)
+	case err != nil:
+		h.logger.Error("failed_to_claim_waitlist_offer", zap.Error(err), zap.Int("entry_id", id))
+		return err
+	}
+
+	h.logger.Info("waitlist_offer_claimed_by_user", zap.Int64("user_id", q.From.ID), zap.Int64("booking_id", bookingID))
+	if err := h.sender.SendMessage(q.From.ID, bookingCreatedMessage, [][]Button{
+		{{Text: "📋 Мои бронирования", CallbackData: CallbackMyBookings}},
+	}); err != nil {
+		return err
+	}
+
+	for _, hook := range h.onCreated {
+		if err := hook(ctx, bookingID); err != nil {
+			h.logger.Error("booking_created_hook_failed", zap.Int64("booking_id", bookingID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Decline отказывается от предложенного места. Ожидает маршрут CallbackWaitlistDecline.
+func (h *WaitlistHandler) Decline(ctx context.Context, q *tgbotapi.CallbackQuery) error {
+	id, ok := CallbackArgsFromContext(ctx).Int("id")
+	if !ok {
+		return fmt.Errorf("waitlist entry id is missing in callback %q", q.Data)
+	}
+
+	err := h.repo.DeclineOffer(ctx, q.From.ID, int64(id))
+	switch {
+	case errors.Is(err, repository.ErrWaitlistEntryNotFound), errors.Is(err, repository.ErrOfferExpired):
+		return h.sender.SendMessage(q.From.ID, waitlistOfferGoneMessage, nil)
+	case err != nil:
+		h.logger.Error("failed_to_decline_waitlist_offer", zap.Error(err), zap.Int("entry_id", id))
+		return err
+	}
+
+	h.logger.Info("waitlist_offer_declined", zap.Int64("user_id", q.From.ID), zap.Int("entry_id", id))
+	return h.sender.SendMessage(q.From.ID, "Хорошо, место передано следующему в очереди.", nil)
+}
+
+func (h *WaitlistHandler) loadJobEntry(ctx context.Context, job models.ScheduledJob) (*models.WaitlistEntry, bool, error) {
+	entryID, err := repository.WaitlistEntryID(job)
+	if err != nil {
+		return nil, false, scheduler.Permanent(err)
+	}
+
+	e, err := h.repo.GetEntry(ctx, entryID)
+	if errors.Is(err, repository.ErrWaitlistEntryNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get waitlist entry: %w", err)
+	}
+	return e, true, nil
+}
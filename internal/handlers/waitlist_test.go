@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/database/repository"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// fakeWaitlist хранит одну запись и повторяет переходы статусов WaitlistRepository.
+type fakeWaitlist struct {
+	entry     models.WaitlistEntry
+	bookingID int64
+}
+
+func (f *fakeWaitlist) GetEntry(ctx context.Context, entryID int64) (*models.WaitlistEntry, error) {
+	if entryID != f.entry.ID {
+		return nil, repository.ErrWaitlistEntryNotFound
+	}
+	e := f.entry
+	return &e, nil
+}
+
+func (f *fakeWaitlist) ClaimOffer(ctx context.Context, telegramID, entryID int64) (int64, error) {
+	if entryID != f.entry.ID || telegramID != f.entry.UserTelegramID {
+		return 0, repository.ErrWaitlistEntryNotFound
+	}
+	if f.entry.Status != models.WaitlistStatusOffered {
+		return 0, repository.ErrOfferExpired
+	}
+	f.entry.Status = models.WaitlistStatusClaimed
+	return f.bookingID, nil
+}
+
+func (f *fakeWaitlist) DeclineOffer(ctx context.Context, telegramID, entryID int64) error {
+	if entryID != f.entry.ID || telegramID != f.entry.UserTelegramID {
+		return repository.ErrWaitlistEntryNotFound
+	}
+	if f.entry.Status != models.WaitlistStatusOffered {
+		return repository.ErrOfferExpired
+	}
+	f.entry.Status = models.WaitlistStatusDeclined
+	return nil
+}
+
+func (f *fakeWaitlist) ExpireOffer(ctx context.Context, entryID int64) (bool, error) {
+	if f.entry.Status != models.WaitlistStatusOffered {
+		return false, nil
+	}
+	f.entry.Status = models.WaitlistStatusExpired
+	return true, nil
+}
+
+func newOfferedEntry() models.WaitlistEntry {
+	return models.WaitlistEntry{
+		ID:             21,
+		UserTelegramID: 777,
+		ServiceTitle:   "Третьяковская галерея",
+		SlotDate:       time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		SlotTime:       sql.NullString{String: "15:00", Valid: true},
+		Status:         models.WaitlistStatusOffered,
+		OfferExpiresAt: sql.NullTime{Time: time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC), Valid: true},
+	}
+}
+
+func waitlistJob(entryID int64) models.ScheduledJob {
+	return models.ScheduledJob{Kind: repository.JobWaitlistOffer, Payload: []byte(fmt.Sprintf(`{"entry_id":%d}`, entryID))}
+}
+
+func waitlistCallback(userID int64, id int) (context.Context, *tgbotapi.CallbackQuery) {
+	ctx := WithCallbackArgs(context.Background(), CallbackArgs{"id": id})
+	return ctx, &tgbotapi.CallbackQuery{From: &tgbotapi.User{ID: userID}}
+}
+
+func TestWaitlist_OfferJobSendsButtons(t *testing.T) {
+	rs := &recordingSender{}
+	h := NewWaitlistHandler(&fakeWaitlist{entry: newOfferedEntry()}, rs, moscow, zap.NewNop())
+
+	require.NoError(t, h.HandleOfferJob(context.Background(), waitlistJob(21)))
+	require.Len(t, rs.sent, 1)
+	assert.Equal(t, int64(777), rs.sent[0].chatID)
+	assert.Contains(t, rs.sent[0].text, "14.03.2026 15:00")
+	assert.Contains(t, rs.sent[0].text, "до 12:30")
+	assert.Equal(t, "waitlist_claim:21", rs.sent[0].buttons[0][0].CallbackData)
+	assert.Equal(t, "waitlist_decline:21", rs.sent[0].buttons[0][1].CallbackData)
+}
+
+func TestWaitlist_OfferJobSkipsClosedOffer(t *testing.T) {
+	entry := newOfferedEntry()
+	entry.Status = models.WaitlistStatusDeclined
+	rs := &recordingSender{}
+	h := NewWaitlistHandler(&fakeWaitlist{entry: entry}, rs, moscow, zap.NewNop())
+
+	require.NoError(t, h.HandleOfferJob(context.Background(), waitlistJob(21)))
+	assert.Empty(t, rs.sent)
+}
+
+func TestWaitlist_ExpireJobNotifiesOnce(t *testing.T) {
+	repo := &fakeWaitlist{entry: newOfferedEntry()}
+	rs := &recordingSender{}
+	h := NewWaitlistHandler(repo, rs, moscow, zap.NewNop())
+
+	require.NoError(t, h.HandleExpireJob(context.Background(), waitlistJob(21)))
+	assert.Equal(t, models.WaitlistStatusExpired, repo.entry.Status)
+	require.Len(t, rs.sent, 1)
+	assert.Contains(t, rs.sent[0].text, "следующему в очереди")
+
+	// повторный запуск задания ничего не отправляет
+	require.NoError(t, h.HandleExpireJob(context.Background(), waitlistJob(21)))
+	assert.Len(t, rs.sent, 1)
+}
+
+func TestWaitlist_ClaimRunsBookingHooks(t *testing.T) {
+	repo := &fakeWaitlist{entry: newOfferedEntry(), bookingID: 42}
+	rs := &recordingSender{}
+	h := NewWaitlistHandler(repo, rs, moscow, zap.NewNop())
+
+	var hooked []int64
+	h.OnBookingCreated(func(ctx context.Context, bookingID int64) error {
+		hooked = append(hooked, bookingID)
+		return nil
+	})
+
+	ctx, q := waitlistCallback(777, 21)
+	require.NoError(t, h.Claim(ctx, q))
+	assert.Equal(t, models.WaitlistStatusClaimed, repo.entry.Status)
+	assert.Equal(t, []int64{42}, hooked)
+	require.Len(t, rs.sent, 1)
+	assert.Equal(t, bookingCreatedMessage, rs.sent[0].text)
+
+	// после истечения или повторного нажатия место уже не наше
+	rs.sent = nil
+	require.NoError(t, h.Claim(ctx, q))
+	require.Len(t, rs.sent, 1)
+	assert.Equal(t, waitlistOfferGoneMessage, rs.sent[0].text)
+	assert.Len(t, hooked, 1)
+}
+
+func TestWaitlist_DeclineForeignEntry(t *testing.T) {
+	repo := &fakeWaitlist{entry: newOfferedEntry()}
+	rs := &recordingSender{}
+	h := NewWaitlistHandler(repo, rs, moscow, zap.NewNop())
+
+	ctx, q := waitlistCallback(555, 21)
+	require.NoError(t, h.Decline(ctx, q))
+	assert.Equal(t, models.WaitlistStatusOffered, repo.entry.Status)
+	assert.Equal(t, waitlistOfferGoneMessage, rs.sent[0].text)
+}
@@ -0,0 +1,67 @@
+package ics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// defaultVisitDuration — длительность события, если у почасовой услуги не задан slot_minutes.
+const defaultVisitDuration = time.Hour
+
+// BookingEvent описывает бронирование событием календаря. Время бронирования
+// интерпретируется в часовом поясе услуги, а если он не задан — в fallback.
+func BookingEvent(b *models.Booking, fallback *time.Location) Event {
+	loc := b.Location(fallback)
+	y, m, d := b.BookingDate.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, loc)
+
+	ev := Event{
+		UID:     fmt.Sprintf("booking-%d@yandex-development-2-team", b.ID),
+		Summary: b.ServiceTitle,
+		Start:   day,
+		End:     day.AddDate(0, 0, 1),
+		AllDay:  true,
+		Status:  bookingStatus(b.Status),
+		Created: b.CreatedAt,
+	}
+	if b.ServiceLocation.Valid {
+		ev.Location = b.ServiceLocation.String
+	}
+
+	if b.BookingTime.Valid && b.BookingTime.String != "" {
+		if t, err := time.Parse("15:04", b.BookingTime.String); err == nil {
+			duration := defaultVisitDuration
+			if b.ServiceSlotMinutes.Valid && b.ServiceSlotMinutes.Int64 > 0 {
+				duration = time.Duration(b.ServiceSlotMinutes.Int64) * time.Minute
+			}
+			ev.AllDay = false
+			// настенное время дня, а не смещение от полуночи: в день перевода часов они расходятся
+			ev.Start = time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc)
+			ev.End = ev.Start.Add(duration)
+		}
+	}
+
+	var desc strings.Builder
+	fmt.Fprintf(&desc, "Бронирование #%d\nСтатус: %s\n", b.ID, b.Status.Title())
+	fmt.Fprintf(&desc, "Гость: %s", b.GuestName)
+	if b.GuestOrganization.Valid && b.GuestOrganization.String != "" {
+		fmt.Fprintf(&desc, ", %s", b.GuestOrganization.String)
+	}
+	ev.Description = desc.String()
+
+	return ev
+}
+
+func bookingStatus(s models.BookingStatus) Status {
+	switch s {
+	case models.BookingStatusConfirmed:
+		return StatusConfirmed
+	case models.BookingStatusCancelled:
+		return StatusCancelled
+	default:
+		return StatusTentative
+	}
+}
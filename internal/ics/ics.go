@@ -0,0 +1,202 @@
+// Package ics формирует календари в формате iCalendar (RFC 5545).
+package ics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	localLayout    = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+	maxLineOctets  = 75
+	defaultProduct = "-//yandex-development-2-team//Booking Bot//RU"
+)
+
+// Status — статус события (STATUS).
+type Status string
+
+const (
+	StatusTentative Status = "TENTATIVE"
+	StatusConfirmed Status = "CONFIRMED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// Event — событие календаря. Для событий на весь день учитываются только даты Start и End,
+// End не включается. Для остальных время записывается в часовом поясе Start.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Status      Status
+	Created     time.Time
+}
+
+type Calendar struct {
+	Name   string // X-WR-CALNAME, пусто — не указывается
+	Events []Event
+}
+
+// Encode возвращает календарь в формате iCalendar.
+func Encode(cal Calendar, now time.Time) []byte {
+	var sb strings.Builder
+	_ = Write(&sb, cal, now)
+	return []byte(sb.String())
+}
+
+// Write записывает календарь в w. now используется как DTSTAMP.
+func Write(w io.Writer, cal Calendar, now time.Time) error {
+	e := &encoder{w: w}
+
+	e.line("BEGIN:VCALENDAR")
+	e.line("VERSION:2.0")
+	e.line("PRODID:" + defaultProduct)
+	e.line("CALSCALE:GREGORIAN")
+	e.line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		e.line("X-WR-CALNAME:" + escape(cal.Name))
+	}
+
+	for _, loc := range timezones(cal.Events) {
+		e.timezone(loc, cal.Events)
+	}
+	for _, ev := range cal.Events {
+		e.event(ev, now)
+	}
+
+	e.line("END:VCALENDAR")
+	return e.err
+}
+
+type encoder struct {
+	w   io.Writer
+	err error
+}
+
+func (e *encoder) event(ev Event, now time.Time) {
+	e.line("BEGIN:VEVENT")
+	e.line("UID:" + escape(ev.UID))
+	e.line("DTSTAMP:" + now.UTC().Format(utcLayout))
+	if !ev.Created.IsZero() {
+		e.line("CREATED:" + ev.Created.UTC().Format(utcLayout))
+	}
+
+	if ev.AllDay {
+		e.line("DTSTART;VALUE=DATE:" + ev.Start.Format(dateLayout))
+		e.line("DTEND;VALUE=DATE:" + ev.End.Format(dateLayout))
+	} else {
+		e.line("DTSTART" + timeValue(ev.Start))
+		e.line("DTEND" + timeValue(ev.End.In(ev.Start.Location())))
+	}
+
+	e.line("SUMMARY:" + escape(ev.Summary))
+	if ev.Location != "" {
+		e.line("LOCATION:" + escape(ev.Location))
+	}
+	if ev.Description != "" {
+		e.line("DESCRIPTION:" + escape(ev.Description))
+	}
+	if ev.Status != "" {
+		e.line("STATUS:" + string(ev.Status))
+	}
+	e.line("END:VEVENT")
+}
+
+// timezone описывает часовой пояс компонентом VTIMEZONE. Описание ограничено одним
+// смещением, поэтому поясам с переходом на летнее время события пишутся в UTC (см. timeValue).
+func (e *encoder) timezone(loc *time.Location, events []Event) {
+	var ref time.Time
+	for _, ev := range events {
+		if !ev.AllDay && ev.Start.Location() == loc {
+			ref = ev.Start
+			break
+		}
+	}
+	_, offset := ref.Zone()
+	abbr, _ := ref.Zone()
+
+	e.line("BEGIN:VTIMEZONE")
+	e.line("TZID:" + loc.String())
+	e.line("BEGIN:STANDARD")
+	e.line("DTSTART:19700101T000000")
+	e.line("TZOFFSETFROM:" + formatOffset(offset))
+	e.line("TZOFFSETTO:" + formatOffset(offset))
+	e.line("TZNAME:" + escape(abbr))
+	e.line("END:STANDARD")
+	e.line("END:VTIMEZONE")
+}
+
+// line записывает строку содержимого, перенося её по 75 октетов без разрыва UTF-8 символов.
+func (e *encoder) line(s string) {
+	if e.err != nil {
+		return
+	}
+
+	var sb strings.Builder
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > maxLineOctets {
+			sb.WriteString("\r\n ")
+			width = 1
+		}
+		sb.WriteRune(r)
+		width += n
+	}
+	sb.WriteString("\r\n")
+
+	_, e.err = io.WriteString(e.w, sb.String())
+}
+
+// timeValue возвращает параметры и значение DTSTART/DTEND.
+func timeValue(t time.Time) string {
+	loc := t.Location()
+	if loc == time.UTC || hasDST(loc, t.Year()) {
+		return ":" + t.UTC().Format(utcLayout)
+	}
+	return ";TZID=" + loc.String() + ":" + t.Format(localLayout)
+}
+
+// timezones возвращает пояса событий, которые нужно описать через VTIMEZONE.
+func timezones(events []Event) []*time.Location {
+	var locs []*time.Location
+	seen := map[string]bool{}
+	for _, ev := range events {
+		loc := ev.Start.Location()
+		if ev.AllDay || loc == time.UTC || hasDST(loc, ev.Start.Year()) || seen[loc.String()] {
+			continue
+		}
+		seen[loc.String()] = true
+		locs = append(locs, loc)
+	}
+	return locs
+}
+
+func hasDST(loc *time.Location, year int) bool {
+	_, winter := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, summer := time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	return winter != summer
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escape экранирует текстовое значение свойства.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
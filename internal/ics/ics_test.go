@@ -0,0 +1,106 @@
+package ics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+var stamp = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func TestEncode_TimedEventInServiceTimezone(t *testing.T) {
+	b := &models.Booking{
+		ID:                 12,
+		ServiceTitle:       "Теннис в Лужниках",
+		ServiceLocation:    sql.NullString{String: "Москва, Лужнецкая набережная, 24", Valid: true},
+		ServiceTimezone:    "Europe/Moscow",
+		ServiceSlotMinutes: sql.NullInt64{Int64: 90, Valid: true},
+		BookingDate:        time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		BookingTime:        sql.NullString{String: "18:00", Valid: true},
+		GuestName:          "Иван Иванов",
+		Status:             models.BookingStatusConfirmed,
+	}
+
+	out := string(Encode(Calendar{Events: []Event{BookingEvent(b, time.UTC)}}, stamp))
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "TZID:Europe/Moscow\r\n")
+	assert.Contains(t, out, "TZOFFSETTO:+0300\r\n")
+	assert.Contains(t, out, "DTSTART;TZID=Europe/Moscow:20260314T180000\r\n")
+	assert.Contains(t, out, "DTEND;TZID=Europe/Moscow:20260314T193000\r\n")
+	assert.Contains(t, out, "UID:booking-12@yandex-development-2-team\r\n")
+	assert.Contains(t, out, "SUMMARY:Теннис в Лужниках\r\n")
+	assert.Contains(t, out, `LOCATION:Москва\, Лужнецкая набережная\, 24`)
+	assert.Contains(t, out, "STATUS:CONFIRMED\r\n")
+	assert.Contains(t, out, "DTSTAMP:20260301T090000Z\r\n")
+}
+
+func TestEncode_AllDayEvent(t *testing.T) {
+	b := &models.Booking{
+		ID:           7,
+		ServiceTitle: "Третьяковская галерея",
+		BookingDate:  time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		Status:       models.BookingStatusPending,
+	}
+
+	out := string(Encode(Calendar{Events: []Event{BookingEvent(b, time.UTC)}}, stamp))
+
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20260314\r\n")
+	assert.Contains(t, out, "DTEND;VALUE=DATE:20260315\r\n")
+	assert.Contains(t, out, "STATUS:TENTATIVE\r\n")
+	assert.NotContains(t, out, "BEGIN:VTIMEZONE")
+}
+
+func TestEncode_DSTZoneFallsBackToUTC(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	start := time.Date(2026, 7, 1, 10, 0, 0, 0, berlin)
+	out := string(Encode(Calendar{Events: []Event{{UID: "x", Summary: "s", Start: start, End: start.Add(time.Hour)}}}, stamp))
+
+	assert.Contains(t, out, "DTSTART:20260701T080000Z\r\n")
+	assert.NotContains(t, out, "BEGIN:VTIMEZONE")
+}
+
+func TestBookingEvent_DSTTransitionDay(t *testing.T) {
+	// 29 марта 2026 в Берлине часы переводят с 02:00 на 03:00, сутки короче на час
+	b := &models.Booking{
+		ID:              3,
+		ServiceTitle:    "Экскурсия",
+		ServiceTimezone: "Europe/Berlin",
+		BookingDate:     time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC),
+		BookingTime:     sql.NullString{String: "10:00", Valid: true},
+		Status:          models.BookingStatusConfirmed,
+	}
+
+	ev := BookingEvent(b, time.UTC)
+	assert.Equal(t, 10, ev.Start.Hour())
+	assert.Equal(t, 11, ev.End.Hour())
+
+	out := string(Encode(Calendar{Events: []Event{ev}}, stamp))
+	assert.Contains(t, out, "DTSTART:20260329T080000Z\r\n")
+}
+
+func TestEncode_FoldsLongLinesAndEscapes(t *testing.T) {
+	desc := strings.Repeat("Описание; ", 20) + "\nвторая строка"
+	out := string(Encode(Calendar{Events: []Event{{
+		UID: "x", Summary: "s", Description: desc, AllDay: true,
+		Start: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+	}}}, stamp))
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "строка длиннее 75 октетов: %q", line)
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, `Описание\; Описание\;`)
+	assert.Contains(t, unfolded, `\nвторая строка`)
+}
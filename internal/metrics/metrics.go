@@ -31,6 +31,18 @@ type Metrics struct {
 	CallbacksReceived           prometheus.Counter
 	CallbacksProcessingDuration prometheus.Histogram
 
+	// Очередь обработки апдейтов
+	UpdatesQueueDepth        prometheus.Gauge
+	UpdatesInFlight          prometheus.Gauge
+	UpdatesQueueWaitDuration prometheus.Histogram
+	UpdatesBackpressureTotal prometheus.Counter
+
+	// Интеграция с Трекером
+	TrackerTicketsTotal *prometheus.CounterVec
+
+	// Брошенные диалоги
+	ConversationsAbandonedTotal *prometheus.CounterVec
+
 	registry *prometheus.Registry
 	logger   *zap.Logger
 }
@@ -133,6 +145,49 @@ func NewMetrics(logger *zap.Logger) (*Metrics, error) {
 		Buckets:   prometheus.DefBuckets,
 	})
 
+	// Gauge: апдейты, ожидающие обработки в пуле
+	m.UpdatesQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "bot",
+		Name:      "updates_queue_depth",
+		Help:      "Number of updates waiting in the worker pool queue",
+	})
+
+	// Gauge: апдейты, обрабатываемые прямо сейчас
+	m.UpdatesInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "bot",
+		Name:      "updates_in_flight",
+		Help:      "Number of updates currently being processed by workers",
+	})
+
+	// Histogram: время ожидания апдейта в очереди
+	m.UpdatesQueueWaitDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "bot",
+		Name:      "updates_queue_wait_duration_seconds",
+		Help:      "Time updates spend in the queue before processing in seconds",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// Counter: сколько раз приём апдейта ждал освобождения места в очереди
+	m.UpdatesBackpressureTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "bot",
+		Name:      "updates_backpressure_total",
+		Help:      "Total number of times update intake blocked because the queue was full",
+	})
+
+	// CounterVec: попытки создать тикет в Трекере по результату (created/retry/failed)
+	m.TrackerTicketsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bot",
+		Name:      "tracker_tickets_total",
+		Help:      "Total number of Tracker ticket creation attempts by result",
+	}, []string{"result"})
+
+	// CounterVec: диалоги, удалённые по истечении TTL, по диалогу и шагу, на котором их бросили
+	m.ConversationsAbandonedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bot",
+		Name:      "conversations_abandoned_total",
+		Help:      "Total number of conversations expired without activity by conversation and step",
+	}, []string{"conversation", "step"})
+
 	// Регистрируем все метрики
 	collectors := []prometheus.Collector{
 		m.MessagesReceived,
@@ -147,6 +202,12 @@ func NewMetrics(logger *zap.Logger) (*Metrics, error) {
 		m.BookingsTotal,
 		m.CallbacksReceived,
 		m.CallbacksProcessingDuration,
+		m.UpdatesQueueDepth,
+		m.UpdatesInFlight,
+		m.UpdatesQueueWaitDuration,
+		m.UpdatesBackpressureTotal,
+		m.TrackerTicketsTotal,
+		m.ConversationsAbandonedTotal,
 	}
 
 	for _, collector := range collectors {
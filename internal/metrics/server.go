@@ -15,6 +15,7 @@ import (
 
 type Server struct {
 	srv    *http.Server
+	mux    *http.ServeMux
 	logger *zap.Logger
 }
 
@@ -35,7 +36,13 @@ func NewServer(port int, db *sqlx.DB, telegram api.TelegramChecker, m *Metrics,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	return &Server{srv: s, logger: logger}
+	return &Server{srv: s, mux: mux, logger: logger}
+}
+
+// Handle монтирует дополнительный обработчик (например, webhook Telegram).
+// Вызывать до Start.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
 }
 
 func (s *Server) Start() error {
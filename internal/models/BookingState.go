@@ -8,16 +8,49 @@ const (
 	BookingStepOrg        = 3
 	BookingStepPosition   = 4
 	BookingStepConfirm    = 5
+	BookingStepSelectTime = 6
+	BookingStepGuests     = 7 // список гостей: добавить, изменить или удалить перед подтверждением
 )
 
+// BookingState — состояние формы бронирования, сохраняется между шагами диалога.
 type BookingState struct {
-	UserID            int64
-	ServiceID         int
-	VisitType         string // private/public
-	SelectedDate      time.Time
-	GuestName         string
-	GuestOrganization string
-	GuestPosition     string
-	Step              int
-	CreatedAt         time.Time
-}
\ No newline at end of file
+	UserID            int64          `json:"user_id"`
+	ServiceID         int            `json:"service_id"`
+	VisitType         string         `json:"visit_type"` // private/public
+	SelectedDate      time.Time      `json:"selected_date"`
+	SelectedTime      string         `json:"selected_time,omitempty"` // 15:04, пусто для бронирования на весь день
+	Waitlist          bool           `json:"waitlist,omitempty"`      // выбран занятый слот — форма ставит в лист ожидания
+	GuestName         string         `json:"guest_name"`
+	GuestOrganization string         `json:"guest_organization"`
+	GuestPosition     string         `json:"guest_position"`
+	Guests            []BookingGuest `json:"guests,omitempty"` // введённые гости; Guest* хранят гостя, которого заполняют сейчас
+	MaxGuests         int            `json:"max_guests,omitempty"`
+	EditGuest         int            `json:"edit_guest,omitempty"` // номер изменяемого гостя с 1, 0 — новый гость
+	Editing           bool           `json:"editing,omitempty"`    // поле меняют из подтверждения — после шага вернуться к нему
+	Step              int            `json:"step"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at,omitempty"`
+}
+
+// Expired сообщает, что с последнего шага формы прошло больше ttl.
+// Для состояний, сохранённых до появления UpdatedAt, отсчёт идёт от CreatedAt.
+// ttl <= 0 отключает проверку.
+func (s BookingState) Expired(now time.Time, ttl time.Duration) bool {
+	if ttl <= 0 {
+		return false
+	}
+	last := s.UpdatedAt
+	if last.IsZero() {
+		last = s.CreatedAt
+	}
+	return !last.IsZero() && now.Sub(last) > ttl
+}
+
+// AllGuests возвращает гостей бронирования. Если список пуст, единственный гость
+// берётся из полей Guest*, как в форме с одним посетителем.
+func (s BookingState) AllGuests() []BookingGuest {
+	if len(s.Guests) > 0 {
+		return s.Guests
+	}
+	return []BookingGuest{{Name: s.GuestName, Organization: s.GuestOrganization, Position: s.GuestPosition}}
+}
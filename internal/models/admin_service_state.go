@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Шаги редактора услуг в /admin services.
+const (
+	AdminServiceStepEdit  = 1 // черновик правится кнопками
+	AdminServiceStepInput = 2 // ждём текст для поля Field
+)
+
+// AdminServiceState — черновик карточки услуги, которую администратор создаёт
+// (Draft.ID == 0) или редактирует. Изменения попадают в каталог только по «Сохранить».
+type AdminServiceState struct {
+	Step      int       `json:"step"`
+	Field     string    `json:"field,omitempty"`  // поле, значение которого ждём текстом
+	Option    int       `json:"option,omitempty"` // номер изменяемого варианта посещения с 1
+	Draft     Service   `json:"draft"`
+	Dirty     bool      `json:"dirty,omitempty"` // в черновике есть несохранённые изменения
+	UpdatedAt time.Time `json:"updated_at"`
+}
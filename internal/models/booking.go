@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type BookingStatus string
+
+const (
+	BookingStatusPending   BookingStatus = "pending"
+	BookingStatusConfirmed BookingStatus = "confirmed"
+	BookingStatusCancelled BookingStatus = "cancelled"
+)
+
+// ErrInvalidStatusTransition возвращается при недопустимой смене статуса бронирования.
+var ErrInvalidStatusTransition = errors.New("invalid booking status transition")
+
+// bookingTransitions — допустимые переходы: pending → confirmed → cancelled,
+// отменить можно и неподтверждённое бронирование.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingStatusPending:   {BookingStatusConfirmed, BookingStatusCancelled},
+	BookingStatusConfirmed: {BookingStatusCancelled},
+}
+
+// CanTransitionTo сообщает, можно ли перевести бронирование из статуса s в next.
+func (s BookingStatus) CanTransitionTo(next BookingStatus) bool {
+	for _, allowed := range bookingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition возвращает ErrInvalidStatusTransition, если переход недопустим.
+func (s BookingStatus) ValidateTransition(next BookingStatus) error {
+	if !s.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, s, next)
+	}
+	return nil
+}
+
+// Title возвращает название статуса для пользователя.
+func (s BookingStatus) Title() string {
+	switch s {
+	case BookingStatusPending:
+		return "⏳ Ожидает подтверждения"
+	case BookingStatusConfirmed:
+		return "✅ Подтверждено"
+	case BookingStatusCancelled:
+		return "❌ Отменено"
+	default:
+		return string(s)
+	}
+}
+
+// Booking — запись из таблицы bookings вместе с названием услуги.
+type Booking struct {
+	ID                 int64          `db:"id"`
+	UserID             int64          `db:"user_id"`
+	UserTelegramID     int64          `db:"user_telegram_id"`
+	ServiceID          int            `db:"service_id"`
+	ServiceTitle       string         `db:"service_title"`
+	ServiceLocation    sql.NullString `db:"service_location"`
+	ServiceTimezone    string         `db:"service_timezone"`     // IANA, например Europe/Moscow
+	ServiceSlotMinutes sql.NullInt64  `db:"service_slot_minutes"` // длительность посещения для почасовых услуг
+	SlotID             sql.NullInt64  `db:"slot_id"`
+	BookingDate        time.Time      `db:"booking_date"`
+	BookingTime        sql.NullString `db:"booking_time"` // 15:04, пусто для бронирования на весь день
+	GuestName          string         `db:"guest_name"`
+	GuestOrganization  sql.NullString `db:"guest_organization"`
+	GuestPosition      sql.NullString `db:"guest_position"`
+	VisitType          sql.NullString `db:"visit_type"`
+	GuestCount         int            `db:"guest_count"` // все гости из booking_guests, включая контактное лицо
+	GuestNames         sql.NullString `db:"guest_names"` // имена гостей через запятую
+	Status             BookingStatus  `db:"status"`
+	TrackerTicketID    sql.NullString `db:"tracker_ticket_id"`
+	CreatedAt          time.Time      `db:"created_at"`
+	UpdatedAt          time.Time      `db:"updated_at"`
+}
+
+// IsActive сообщает, что бронирование не отменено и ещё может быть изменено.
+func (b Booking) IsActive() bool {
+	return b.Status == BookingStatusPending || b.Status == BookingStatusConfirmed
+}
+
+// Location возвращает часовой пояс услуги, а если он не задан или неизвестен — fallback.
+func (b Booking) Location(fallback *time.Location) *time.Location {
+	if b.ServiceTimezone != "" {
+		if loc, err := time.LoadLocation(b.ServiceTimezone); err == nil {
+			return loc
+		}
+	}
+	return fallback
+}
+
+// When возвращает дату и время посещения для показа пользователю.
+func (b Booking) When() string {
+	when := b.BookingDate.Format("02.01.2006")
+	if b.BookingTime.Valid && b.BookingTime.String != "" {
+		when += " " + b.BookingTime.String
+	}
+	return when
+}
@@ -0,0 +1,9 @@
+package models
+
+// BookingGuest — посетитель из бронирования. Первый гость — контактное лицо,
+// его данные дублируются в bookings.guest_name и соседних колонках.
+type BookingGuest struct {
+	Name         string `json:"name" db:"name"`
+	Organization string `json:"organization,omitempty" db:"organization"`
+	Position     string `json:"position,omitempty" db:"position"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBookingStatus_Transitions(t *testing.T) {
+	cases := []struct {
+		from, to BookingStatus
+		ok       bool
+	}{
+		{BookingStatusPending, BookingStatusConfirmed, true},
+		{BookingStatusPending, BookingStatusCancelled, true},
+		{BookingStatusConfirmed, BookingStatusCancelled, true},
+		{BookingStatusConfirmed, BookingStatusPending, false},
+		{BookingStatusCancelled, BookingStatusConfirmed, false},
+		{BookingStatusCancelled, BookingStatusCancelled, false},
+	}
+
+	for _, c := range cases {
+		if got := c.from.CanTransitionTo(c.to); got != c.ok {
+			t.Errorf("%s -> %s: expected %v, got %v", c.from, c.to, c.ok, got)
+		}
+		err := c.from.ValidateTransition(c.to)
+		if c.ok && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", c.from, c.to, err)
+		}
+		if !c.ok && !errors.Is(err, ErrInvalidStatusTransition) {
+			t.Errorf("%s -> %s: expected ErrInvalidStatusTransition, got %v", c.from, c.to, err)
+		}
+	}
+}
+
+func TestBookingState_AllGuests(t *testing.T) {
+	single := BookingState{GuestName: "Иван Иванов", GuestPosition: "Разработчик"}
+	if got := single.AllGuests(); len(got) != 1 || got[0].Name != "Иван Иванов" || got[0].Position != "Разработчик" {
+		t.Fatalf("unexpected single guest: %+v", got)
+	}
+
+	group := BookingState{GuestName: "Пётр Петров", Guests: []BookingGuest{{Name: "Иван Иванов"}, {Name: "Пётр Петров"}}}
+	if got := group.AllGuests(); len(got) != 2 || got[0].Name != "Иван Иванов" {
+		t.Fatalf("unexpected guests: %+v", got)
+	}
+}
+
+func TestBookingState_Expired(t *testing.T) {
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)
+	ttl := 2 * time.Hour
+
+	cases := []struct {
+		name  string
+		state BookingState
+		ttl   time.Duration
+		want  bool
+	}{
+		{"idle since creation", BookingState{CreatedAt: now.Add(-3 * time.Hour)}, ttl, true},
+		{"recent step", BookingState{CreatedAt: now.Add(-3 * time.Hour), UpdatedAt: now.Add(-time.Hour)}, ttl, false},
+		{"ttl disabled", BookingState{CreatedAt: now.Add(-3 * time.Hour)}, 0, false},
+		{"no timestamps", BookingState{}, ttl, false},
+	}
+	for _, c := range cases {
+		if got := c.state.Expired(now, c.ttl); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
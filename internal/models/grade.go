@@ -0,0 +1,30 @@
+package models
+
+// Grade — уровень пользователя из users.grade. От него зависят видимость услуг
+// и ограничения на бронирование (таблица service_grade_rules).
+type Grade int
+
+const (
+	GradeExternal Grade = iota
+	GradeJunior
+	GradeMid
+	GradeSenior
+	GradeAdmin
+)
+
+func (g Grade) String() string {
+	switch g {
+	case GradeExternal:
+		return "external"
+	case GradeJunior:
+		return "junior"
+	case GradeMid:
+		return "mid"
+	case GradeSenior:
+		return "senior"
+	case GradeAdmin:
+		return "admin"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,9 @@
+package models
+
+// ScheduledJob — отложенное задание из таблицы scheduled_jobs.
+type ScheduledJob struct {
+	ID       int64  `db:"id"`
+	Kind     string `db:"kind"`
+	Payload  []byte `db:"payload"`  // JSON
+	Attempts int    `db:"attempts"` // с учётом текущего запуска
+}
@@ -1,6 +1,79 @@
 package models
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yandex-development-2-team/Go/internal/schedule"
+)
+
+// Service — услуга из каталога коробочных решений.
 type Service struct {
-	ID    int
-	Title string
+	ID           int             `json:"id" db:"id"`
+	Title        string          `json:"title" db:"title"`
+	Description  string          `json:"description" db:"description"`
+	Rules        string          `json:"rules" db:"rules"`
+	Schedule     schedule.Weekly `json:"schedule" db:"weekly_hours"`       // часы работы; пустое — расписание не показывается
+	VisitOptions VisitOptions    `json:"visit_options" db:"visit_options"` // варианты посещения, например приватный и групповой тур
+	HasBooking   bool            `json:"has_booking" db:"has_booking"`     // бронирование без выбора варианта посещения
+	SortOrder    int             `json:"sort_order" db:"sort_order"`
+	IsActive     bool            `json:"is_active" db:"is_active"`
+	CategoryID   int             `json:"category_id" db:"category_id"` // раздел каталога; 0 — без категории («Другое»)
+}
+
+// VisitOption — вариант посещения услуги и тип посещения, который он задаёт бронированию.
+type VisitOption struct {
+	Title     string `json:"title"`
+	VisitType string `json:"visit_type"` // private/public
+}
+
+// VisitOptions хранится в services.visit_options как JSON-массив.
+type VisitOptions []VisitOption
+
+// VisitType возвращает тип посещения варианта с индексом idx или пустую строку.
+func (o VisitOptions) VisitType(idx int) string {
+	if idx < 0 || idx >= len(o) {
+		return ""
+	}
+	return o[idx].VisitType
+}
+
+func (o *VisitOptions) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("scan visit options: unsupported type %T", src)
+	}
+	return json.Unmarshal(raw, o)
+}
+
+func (o VisitOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(o)
+	return string(raw), err
+}
+
+// ServicePhoto — фотография карточки услуги. Пока FileID пуст, фото загружается из Path.
+type ServicePhoto struct {
+	ID        int64  `json:"id" db:"id"`
+	ServiceID int    `json:"service_id" db:"service_id"`
+	Path      string `json:"path" db:"local_path"`
+	FileID    string `json:"file_id" db:"file_id"`
+}
+
+// ServiceCategory — раздел каталога коробочных решений. ID 0 — услуги без категории.
+type ServiceCategory struct {
+	ID       int    `json:"id" db:"id"`
+	Title    string `json:"title" db:"title"`
+	Services int    `json:"services" db:"services"` // сколько услуг категории видно пользователю
 }
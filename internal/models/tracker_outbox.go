@@ -0,0 +1,8 @@
+package models
+
+// TrackerOutboxEntry — запись очереди на создание тикета в Яндекс Трекере.
+type TrackerOutboxEntry struct {
+	ID        int64 `db:"id"`
+	BookingID int64 `db:"booking_id"`
+	Attempts  int   `db:"attempts"` // с учётом текущей попытки
+}
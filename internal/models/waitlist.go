@@ -0,0 +1,45 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// WaitlistStatus — состояние записи в листе ожидания.
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting  WaitlistStatus = "waiting"  // ждёт освобождения места
+	WaitlistStatusOffered  WaitlistStatus = "offered"  // место удерживается до offer_expires_at
+	WaitlistStatusClaimed  WaitlistStatus = "claimed"  // по предложению создано бронирование
+	WaitlistStatusExpired  WaitlistStatus = "expired"  // предложение не принято вовремя
+	WaitlistStatusDeclined WaitlistStatus = "declined" // пользователь отказался от предложения
+)
+
+// WaitlistEntry — запись из таблицы waitlist_entries вместе с названием услуги.
+type WaitlistEntry struct {
+	ID                int64          `db:"id"`
+	UserTelegramID    int64          `db:"user_telegram_id"`
+	ServiceID         int            `db:"service_id"`
+	ServiceTitle      string         `db:"service_title"`
+	SlotDate          time.Time      `db:"slot_date"`
+	SlotTime          sql.NullString `db:"slot_time"` // 15:04, пусто для ожидания на весь день
+	GuestName         string         `db:"guest_name"`
+	GuestOrganization sql.NullString `db:"guest_organization"`
+	GuestPosition     sql.NullString `db:"guest_position"`
+	VisitType         sql.NullString `db:"visit_type"`
+	Status            WaitlistStatus `db:"status"`
+	SlotID            sql.NullInt64  `db:"slot_id"`
+	OfferExpiresAt    sql.NullTime   `db:"offer_expires_at"`
+	BookingID         sql.NullInt64  `db:"booking_id"`
+	CreatedAt         time.Time      `db:"created_at"`
+}
+
+// When возвращает дату и время, на которые ожидается место.
+func (e WaitlistEntry) When() string {
+	when := e.SlotDate.Format("02.01.2006")
+	if e.SlotTime.Valid && e.SlotTime.String != "" {
+		when += " " + e.SlotTime.String
+	}
+	return when
+}
@@ -0,0 +1,137 @@
+// Package pager строит inline-клавиатуру Telegram со списком по страницам: кнопки элементов
+// в столбик и ряд навигации «◀️ · N из M · ▶️».
+//
+// Callback data имеет вид "<prefix>:<страница>" для перехода на страницу и
+// "<prefix>:<страница>:<значение>" для кнопки элемента, например "boxc:2" или "boxc:2:17".
+// Страница в данных элемента нужна, чтобы кнопка «Назад» вернула на ту же страницу.
+// Страницы считаются с 1.
+package pager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DefaultPerPage — сколько элементов на странице, если PerPage не задан.
+const DefaultPerPage = 8
+
+const counterValue = "-"
+
+// Item — элемент списка: текст кнопки и значение, которое вернёт Parse.
+type Item struct {
+	Text  string
+	Value string
+}
+
+// Pager описывает список по страницам. Prefix отличает его callback data от других кнопок:
+// он должен быть коротким и может включать контекст списка, например "boxs:3" для услуг
+// категории 3.
+type Pager struct {
+	Prefix  string
+	PerPage int
+}
+
+// Pages возвращает число страниц для n элементов; пустой список — одна страница.
+func (p Pager) Pages(n int) int {
+	per := p.perPage()
+	if n <= 0 {
+		return 1
+	}
+	return (n + per - 1) / per
+}
+
+// Clamp приводит номер страницы к диапазону [1, Pages(n)].
+func (p Pager) Clamp(page, n int) int {
+	if page < 1 {
+		return 1
+	}
+	if pages := p.Pages(n); page > pages {
+		return pages
+	}
+	return page
+}
+
+// PageOf возвращает страницу, на которой находится элемент с индексом idx.
+func (p Pager) PageOf(idx int) int {
+	if idx < 0 {
+		return 1
+	}
+	return idx/p.perPage() + 1
+}
+
+// Keyboard строит клавиатуру страницы page; номер приводится к допустимому. Ряд навигации
+// добавляется, только если страниц больше одной. extra — ряды под списком, например «Назад».
+func (p Pager) Keyboard(items []Item, page int, extra ...[]tgbotapi.InlineKeyboardButton) tgbotapi.InlineKeyboardMarkup {
+	page = p.Clamp(page, len(items))
+	per := p.perPage()
+	from := (page - 1) * per
+	to := min(from+per, len(items))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, to-from+1+len(extra))
+	for _, item := range items[from:to] {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(item.Text, p.ItemData(page, item.Value)),
+		))
+	}
+
+	if pages := p.Pages(len(items)); pages > 1 {
+		nav := tgbotapi.NewInlineKeyboardRow(
+			p.noop(" "),
+			p.noop(fmt.Sprintf("%d из %d", page, pages)),
+			p.noop(" "),
+		)
+		if page > 1 {
+			nav[0] = tgbotapi.NewInlineKeyboardButtonData("◀️", p.PageData(page-1))
+		}
+		if page < pages {
+			nav[2] = tgbotapi.NewInlineKeyboardButtonData("▶️", p.PageData(page+1))
+		}
+		rows = append(rows, nav)
+	}
+
+	rows = append(rows, extra...)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// PageData возвращает callback data перехода на страницу page.
+func (p Pager) PageData(page int) string {
+	return p.Prefix + ":" + strconv.Itoa(page)
+}
+
+// ItemData возвращает callback data кнопки элемента со значением value на странице page.
+func (p Pager) ItemData(page int, value string) string {
+	return p.PageData(page) + ":" + value
+}
+
+// Parse разбирает callback data списка. ok=false, если данные не относятся к нему.
+// Для перехода на страницу value пустое, для счётчика страниц page = 0.
+func (p Pager) Parse(data string) (page int, value string, ok bool) {
+	rest, found := strings.CutPrefix(data, p.Prefix+":")
+	if !found {
+		return 0, "", false
+	}
+	if rest == counterValue {
+		return 0, "", true
+	}
+
+	num, value, _ := strings.Cut(rest, ":")
+	page, err := strconv.Atoi(num)
+	if err != nil || page < 1 {
+		return 0, "", false
+	}
+	return page, value, true
+}
+
+func (p Pager) noop(text string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, p.Prefix+":"+counterValue)
+}
+
+func (p Pager) perPage() int {
+	if p.PerPage <= 0 {
+		return DefaultPerPage
+	}
+	return p.PerPage
+}
@@ -0,0 +1,93 @@
+package pager
+
+import (
+	"fmt"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func items(n int) []Item {
+	out := make([]Item, n)
+	for i := range out {
+		out[i] = Item{Text: fmt.Sprintf("Услуга %d", i+1), Value: fmt.Sprint(i + 1)}
+	}
+	return out
+}
+
+func texts(row []tgbotapi.InlineKeyboardButton) []string {
+	var out []string
+	for _, btn := range row {
+		out = append(out, btn.Text)
+	}
+	return out
+}
+
+func TestKeyboard_MiddlePage(t *testing.T) {
+	p := Pager{Prefix: "boxs:3", PerPage: 4}
+	back := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Назад", "back"))
+	rows := p.Keyboard(items(10), 2, back).InlineKeyboard
+
+	require.Len(t, rows, 4+1+1)
+	assert.Equal(t, []string{"Услуга 5"}, texts(rows[0]))
+	assert.Equal(t, "boxs:3:2:5", *rows[0][0].CallbackData)
+	assert.Equal(t, []string{"◀️", "2 из 3", "▶️"}, texts(rows[4]))
+	assert.Equal(t, "boxs:3:1", *rows[4][0].CallbackData)
+	assert.Equal(t, "boxs:3:-", *rows[4][1].CallbackData)
+	assert.Equal(t, "boxs:3:3", *rows[4][2].CallbackData)
+	assert.Equal(t, []string{"Назад"}, texts(rows[5]))
+}
+
+func TestKeyboard_EdgesAndClamp(t *testing.T) {
+	p := Pager{Prefix: "boxc", PerPage: 4}
+
+	last := p.Keyboard(items(10), 99).InlineKeyboard
+	require.Len(t, last, 2+1)
+	assert.Equal(t, []string{"Услуга 9"}, texts(last[0]))
+	assert.Equal(t, []string{"◀️", "3 из 3", " "}, texts(last[2]))
+
+	first := p.Keyboard(items(10), 0).InlineKeyboard
+	assert.Equal(t, []string{" ", "1 из 3", "▶️"}, texts(first[4]))
+}
+
+func TestKeyboard_SinglePageHasNoNavigation(t *testing.T) {
+	rows := Pager{Prefix: "boxc"}.Keyboard(items(3), 1).InlineKeyboard
+	require.Len(t, rows, 3)
+	assert.Equal(t, "boxc:1:3", *rows[2][0].CallbackData)
+}
+
+func TestPages(t *testing.T) {
+	p := Pager{PerPage: 4}
+	assert.Equal(t, 1, p.Pages(0))
+	assert.Equal(t, 1, p.Pages(4))
+	assert.Equal(t, 2, p.Pages(5))
+	assert.Equal(t, 3, p.PageOf(8))
+	assert.Equal(t, 2, Pager{}.PageOf(DefaultPerPage))
+}
+
+func TestParse(t *testing.T) {
+	p := Pager{Prefix: "boxs:3"}
+
+	page, value, ok := p.Parse("boxs:3:2:17")
+	require.True(t, ok)
+	assert.Equal(t, 2, page)
+	assert.Equal(t, "17", value)
+
+	page, value, ok = p.Parse("boxs:3:4")
+	require.True(t, ok)
+	assert.Equal(t, 4, page)
+	assert.Empty(t, value)
+
+	page, _, ok = p.Parse("boxs:3:-")
+	require.True(t, ok)
+	assert.Zero(t, page)
+
+	_, _, ok = p.Parse("boxs:4:1")
+	assert.False(t, ok)
+	_, _, ok = p.Parse("boxs:3:x")
+	assert.False(t, ok)
+	_, _, ok = p.Parse("boxs:3:0")
+	assert.False(t, ok)
+}
@@ -0,0 +1,189 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dayNames — сокращения дней недели в порядке ISO, индекс 0 — понедельник.
+var dayNames = [...]string{"ПН", "ВТ", "СР", "ЧТ", "ПТ", "СБ", "ВС"}
+
+const (
+	everyDay = "ЕЖЕДНЕВНО"
+	dayOff   = "ВЫХОДНОЙ"
+)
+
+// ParseError — строка расписания, которую не удалось разобрать.
+type ParseError struct {
+	Line string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse schedule line %q: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseWeekly разбирает недельное расписание в том виде, в каком его пишут в карточке:
+//
+//	ПН-ПТ: 10:00-18:00
+//	СБ, ВС: 11:00-14:00, 15:00-19:00
+//	ЧТ: выходной
+//
+// Строки разделяются переводом строки или «;». Дни — сокращения ПН…ВС через запятую
+// или диапазоном (ПТ-ВТ проходит через выходные), либо «ежедневно». Следующая строка
+// переопределяет предыдущие для тех же дней.
+func ParseWeekly(s string) (Weekly, error) {
+	var w Weekly
+	lines := strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ';' })
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		days, hours, err := parseLine(line)
+		if err != nil {
+			return Weekly{}, &ParseError{Line: line, Err: err}
+		}
+		for _, iso := range days {
+			w[iso%7] = hours
+		}
+	}
+	return w, nil
+}
+
+func parseLine(line string) (days []int, hours []Interval, err error) {
+	dayPart, hourPart, ok := strings.Cut(line, ":")
+	if !ok {
+		return nil, nil, fmt.Errorf("expected \"<days>: <hours>\"")
+	}
+	if days, err = parseDays(dayPart); err != nil {
+		return nil, nil, err
+	}
+	if hours, err = parseHours(hourPart); err != nil {
+		return nil, nil, err
+	}
+	return days, hours, nil
+}
+
+// parseDays возвращает номера дней ISO: 1=пн ... 7=вс.
+func parseDays(s string) ([]int, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == everyDay {
+		return []int{1, 2, 3, 4, 5, 6, 7}, nil
+	}
+
+	var days []int
+	for _, token := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(normalizeDash(token), "-")
+		from, err := parseDay(first)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = parseDay(last); err != nil {
+				return nil, err
+			}
+		}
+		for d := from; ; d = d%7 + 1 {
+			days = append(days, d)
+			if d == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseDay(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	for i, name := range dayNames {
+		if s == name {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+func parseHours(s string) ([]Interval, error) {
+	s = strings.TrimSpace(s)
+	if strings.ToUpper(s) == dayOff {
+		return nil, nil
+	}
+
+	var hours []Interval
+	for _, token := range strings.Split(s, ",") {
+		open, closing, ok := strings.Cut(normalizeDash(token), "-")
+		if !ok {
+			return nil, fmt.Errorf("expected interval \"10:00-18:00\", got %q", strings.TrimSpace(token))
+		}
+		var (
+			iv  Interval
+			err error
+		)
+		if iv.Open, err = ParseClock(strings.TrimSpace(open)); err != nil {
+			return nil, err
+		}
+		if iv.Close, err = ParseClock(strings.TrimSpace(closing)); err != nil {
+			return nil, err
+		}
+		hours = append(hours, iv)
+	}
+	return normalize(hours)
+}
+
+func normalizeDash(s string) string {
+	return strings.NewReplacer("–", "-", "—", "-").Replace(s)
+}
+
+// String возвращает расписание в формате ParseWeekly: подряд идущие дни с одинаковыми
+// часами объединяются в диапазон, выходные не выводятся.
+func (w Weekly) String() string {
+	hoursOf := func(i int) []Interval { return w[(i+1)%7] } // i — индекс в dayNames
+
+	daily := len(hoursOf(0)) > 0
+	for i := 1; i < 7 && daily; i++ {
+		daily = sameHours(hoursOf(i), hoursOf(0))
+	}
+	if daily {
+		return "Ежедневно: " + formatHours(hoursOf(0))
+	}
+
+	var lines []string
+	for i := 0; i < 7; {
+		j := i
+		for j+1 < 7 && sameHours(hoursOf(j+1), hoursOf(i)) {
+			j++
+		}
+		if len(hoursOf(i)) > 0 {
+			days := dayNames[i]
+			if j > i {
+				days += "-" + dayNames[j]
+			}
+			lines = append(lines, days+": "+formatHours(hoursOf(i)))
+		}
+		i = j + 1
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatHours возвращает часы работы дня, например "10:00-13:00, 14:00-18:00" или "выходной".
+func FormatHours(hours []Interval) string {
+	if len(hours) == 0 {
+		return "выходной"
+	}
+	return formatHours(hours)
+}
+
+func formatHours(hours []Interval) string {
+	parts := make([]string, len(hours))
+	for i, iv := range hours {
+		parts[i] = iv.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,303 @@
+// Package schedule описывает часы работы услуги: недельное расписание, исключения
+// на отдельные даты (особые часы или закрытие) и государственные праздники.
+//
+// Даты — календарные дни без времени: учитываются только год, месяц и день.
+// Часы работы задаются в местном времени услуги (Schedule.Location); интервал
+// не переходит через полночь, 24:00 означает конец дня.
+package schedule
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clock — время суток в минутах от полуночи, от 0 до 24:00.
+type Clock int
+
+// EndOfDay — 24:00, конец суток.
+const EndOfDay Clock = 24 * 60
+
+// ParseClock разбирает время "15:04"; допускается "24:00".
+func ParseClock(s string) (Clock, error) {
+	h, m, ok := splitClock(s)
+	if !ok || h > 24 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return Clock(h*60 + m), nil
+}
+
+func splitClock(s string) (h, m int, ok bool) {
+	hh, mm, found := strings.Cut(s, ":")
+	if !found || len(mm) != 2 || len(hh) == 0 || len(hh) > 2 {
+		return 0, 0, false
+	}
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	return h, m, err1 == nil && err2 == nil && h >= 0 && m >= 0
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", int(c)/60, int(c)%60)
+}
+
+func (c Clock) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Clock) UnmarshalText(text []byte) error {
+	v, err := ParseClock(string(text))
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+// Interval — часы работы [Open, Close).
+type Interval struct {
+	Open  Clock `json:"open"`
+	Close Clock `json:"close"`
+}
+
+// Contains сообщает, попадает ли время в интервал.
+func (i Interval) Contains(c Clock) bool {
+	return c >= i.Open && c < i.Close
+}
+
+func (i Interval) String() string {
+	return i.Open.String() + "-" + i.Close.String()
+}
+
+// normalize сортирует интервалы и проверяет, что они непусты и не пересекаются.
+func normalize(hours []Interval) ([]Interval, error) {
+	out := append([]Interval(nil), hours...)
+	sort.Slice(out, func(a, b int) bool { return out[a].Open < out[b].Open })
+	for i, iv := range out {
+		if iv.Open < 0 || iv.Close > EndOfDay || iv.Open >= iv.Close {
+			return nil, fmt.Errorf("invalid interval %s", iv)
+		}
+		if i > 0 && out[i-1].Close > iv.Open {
+			return nil, fmt.Errorf("overlapping intervals %s and %s", out[i-1], iv)
+		}
+	}
+	return out, nil
+}
+
+func sameHours(a, b []Interval) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Weekly — часы работы по дням недели, индекс — time.Weekday. Пустой день — выходной.
+// В services.weekly_hours хранится как JSON-объект по дням ISO (1=пн ... 7=вс):
+// {"1": [{"open": "10:00", "close": "18:00"}], ...}.
+type Weekly [7][]Interval
+
+// IsZero сообщает, что услуга не работает ни в один день недели.
+func (w Weekly) IsZero() bool {
+	for _, hours := range w {
+		if len(hours) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (w Weekly) MarshalJSON() ([]byte, error) {
+	days := make(map[string][]Interval, 7)
+	for wd, hours := range w {
+		if len(hours) > 0 {
+			days[strconv.Itoa(isoWeekday(time.Weekday(wd)))] = hours
+		}
+	}
+	return json.Marshal(days)
+}
+
+func (w *Weekly) UnmarshalJSON(raw []byte) error {
+	var days map[string][]Interval
+	if err := json.Unmarshal(raw, &days); err != nil {
+		return err
+	}
+
+	var out Weekly
+	for key, hours := range days {
+		iso, err := strconv.Atoi(key)
+		if err != nil || iso < 1 || iso > 7 {
+			return fmt.Errorf("invalid weekday %q", key)
+		}
+		if out[iso%7], err = normalize(hours); err != nil {
+			return fmt.Errorf("weekday %d: %w", iso, err)
+		}
+	}
+	*w = out
+	return nil
+}
+
+func (w *Weekly) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*w = Weekly{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, w)
+	case string:
+		return json.Unmarshal([]byte(v), w)
+	default:
+		return fmt.Errorf("scan weekly hours: unsupported type %T", src)
+	}
+}
+
+func (w Weekly) Value() (driver.Value, error) {
+	raw, err := json.Marshal(w)
+	return string(raw), err
+}
+
+// isoWeekday переводит time.Weekday в номер дня ISO: 1=пн ... 7=вс.
+func isoWeekday(wd time.Weekday) int {
+	if wd == time.Sunday {
+		return 7
+	}
+	return int(wd)
+}
+
+// Exception — особые часы работы на дату. Пустой Hours — услуга в этот день закрыта.
+// Исключение важнее праздника: так услугу можно открыть в праздничный день.
+type Exception struct {
+	Date  time.Time
+	Hours []Interval
+	Note  string
+}
+
+// Holiday — государственный праздник; если на дату нет исключения, услуга закрыта.
+type Holiday struct {
+	Date  time.Time
+	Title string
+}
+
+// Day — часы работы услуги на конкретную дату с учётом исключений и праздников.
+type Day struct {
+	Date    time.Time
+	Hours   []Interval
+	Note    string // причина особого расписания: название праздника или комментарий к исключению
+	Special bool   // день отличается от недельного расписания
+}
+
+// Open сообщает, работает ли услуга в этот день.
+func (d Day) Open() bool {
+	return len(d.Hours) > 0
+}
+
+// SlotStarts возвращает начала слотов длительностью step, которые целиком помещаются
+// в часы работы. Отсчёт идёт от начала каждого интервала.
+func (d Day) SlotStarts(step time.Duration) []Clock {
+	minutes := Clock(step / time.Minute)
+	if minutes <= 0 {
+		return nil
+	}
+
+	var starts []Clock
+	for _, iv := range d.Hours {
+		for c := iv.Open; c+minutes <= iv.Close; c += minutes {
+			starts = append(starts, c)
+		}
+	}
+	return starts
+}
+
+// Slot — слот времени [Start, End).
+type Slot struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Schedule — расписание услуги. Exceptions и Holidays достаточно загрузить на тот период,
+// для которого выполняются запросы.
+type Schedule struct {
+	Weekly     Weekly
+	Exceptions []Exception
+	Holidays   []Holiday
+	Location   *time.Location // nil — UTC
+}
+
+// Day возвращает часы работы на дату: исключение, затем праздник, затем недельное расписание.
+func (s Schedule) Day(date time.Time) Day {
+	date = civilDate(date)
+	for _, e := range s.Exceptions {
+		if civilDate(e.Date).Equal(date) {
+			return Day{Date: date, Hours: e.Hours, Note: e.Note, Special: !sameHours(e.Hours, s.Weekly[date.Weekday()])}
+		}
+	}
+	for _, h := range s.Holidays {
+		if civilDate(h.Date).Equal(date) {
+			return Day{Date: date, Note: h.Title, Special: len(s.Weekly[date.Weekday()]) > 0}
+		}
+	}
+	return Day{Date: date, Hours: s.Weekly[date.Weekday()]}
+}
+
+// Days возвращает расписание на days дней начиная с даты from.
+func (s Schedule) Days(from time.Time, days int) []Day {
+	from = civilDate(from)
+	out := make([]Day, 0, days)
+	for i := 0; i < days; i++ {
+		out = append(out, s.Day(from.AddDate(0, 0, i)))
+	}
+	return out
+}
+
+// IsOpen сообщает, работает ли услуга в момент t.
+func (s Schedule) IsOpen(t time.Time) bool {
+	local := t.In(s.location())
+	at := Clock(local.Hour()*60 + local.Minute())
+	for _, iv := range s.Day(local).Hours {
+		if iv.Contains(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenSlots возвращает слоты длительностью step, которые начинаются не раньше from
+// и заканчиваются не позже to.
+func (s Schedule) OpenSlots(from, to time.Time, step time.Duration) []Slot {
+	loc := s.location()
+	first, last := civilDate(from.In(loc)), civilDate(to.In(loc))
+
+	var slots []Slot
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		for _, c := range s.Day(d).SlotStarts(step) {
+			start := time.Date(d.Year(), d.Month(), d.Day(), int(c)/60, int(c)%60, 0, 0, loc)
+			end := start.Add(step)
+			if start.Before(from) || end.After(to) {
+				continue
+			}
+			slots = append(slots, Slot{Start: start, End: end})
+		}
+	}
+	return slots
+}
+
+func (s Schedule) location() *time.Location {
+	if s.Location == nil {
+		return time.UTC
+	}
+	return s.Location
+}
+
+// civilDate отбрасывает время и часовой пояс, оставляя календарный день в UTC.
+func civilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,157 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func iv(open, closing string) Interval {
+	o, _ := ParseClock(open)
+	c, _ := ParseClock(closing)
+	return Interval{Open: o, Close: c}
+}
+
+func TestParseWeekly(t *testing.T) {
+	w, err := ParseWeekly("ПН-ПТ: 10:00-18:00; сб, вс: 11:00-14:00, 15:00–19:00\nСР: выходной")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Interval{iv("10:00", "18:00")}, w[time.Monday])
+	assert.Empty(t, w[time.Wednesday], "следующая строка переопределяет дни")
+	assert.Equal(t, []Interval{iv("11:00", "14:00"), iv("15:00", "19:00")}, w[time.Sunday])
+	assert.Equal(t, "ПН-ВТ: 10:00-18:00\nЧТ-ПТ: 10:00-18:00\nСБ-ВС: 11:00-14:00, 15:00-19:00", w.String())
+}
+
+func TestParseWeekly_WrappingRangeAndEveryDay(t *testing.T) {
+	w, err := ParseWeekly("ПТ-ВТ: 12:00-24:00")
+	require.NoError(t, err)
+	assert.Empty(t, w[time.Wednesday])
+	assert.Empty(t, w[time.Thursday])
+	assert.Equal(t, []Interval{{Open: 12 * 60, Close: EndOfDay}}, w[time.Saturday])
+
+	w, err = ParseWeekly("Ежедневно: 06:00-23:00")
+	require.NoError(t, err)
+	assert.Equal(t, "Ежедневно: 06:00-23:00", w.String())
+}
+
+func TestParseWeekly_Errors(t *testing.T) {
+	for _, s := range []string{
+		"ПН 10:00-18:00",
+		"ПХ: 10:00-18:00",
+		"ПН: 10-18",
+		"ПН: 18:00-10:00",
+		"ПН: 10:00-14:00, 13:00-18:00",
+		"ПН: 10:00-25:00",
+	} {
+		_, err := ParseWeekly(s)
+		var perr *ParseError
+		assert.True(t, errors.As(err, &perr), s)
+	}
+}
+
+func TestWeekly_JSONRoundTrip(t *testing.T) {
+	w, err := ParseWeekly("ВТ-ВС: 10:00-18:00")
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(w)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"7":[{"open":"10:00","close":"18:00"}]`)
+	assert.NotContains(t, string(raw), `"1"`)
+
+	var back Weekly
+	require.NoError(t, back.Scan(raw))
+	assert.Equal(t, w, back)
+
+	assert.Error(t, back.Scan([]byte(`{"8":[]}`)))
+}
+
+func TestSchedule_Day(t *testing.T) {
+	w, _ := ParseWeekly("ВТ-ВС: 10:00-18:00")
+	s := Schedule{
+		Weekly: w,
+		Exceptions: []Exception{
+			{Date: day(2026, time.June, 13), Hours: []Interval{iv("12:00", "16:00")}, Note: "Санитарный день до 12:00"},
+			{Date: day(2026, time.June, 12), Hours: []Interval{iv("10:00", "18:00")}},
+		},
+		Holidays: []Holiday{
+			{Date: day(2026, time.June, 12), Title: "День России"},
+			{Date: day(2026, time.May, 9), Title: "День Победы"},
+		},
+	}
+
+	// праздник закрывает услугу
+	d := s.Day(day(2026, time.May, 9))
+	assert.False(t, d.Open())
+	assert.True(t, d.Special)
+	assert.Equal(t, "День Победы", d.Note)
+
+	// исключение важнее праздника и совпадает с обычными часами
+	d = s.Day(day(2026, time.June, 12))
+	assert.True(t, d.Open())
+	assert.False(t, d.Special)
+
+	d = s.Day(time.Date(2026, time.June, 13, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, []Interval{iv("12:00", "16:00")}, d.Hours)
+	assert.True(t, d.Special)
+
+	// понедельник — выходной по недельному расписанию
+	d = s.Day(day(2026, time.June, 15))
+	assert.False(t, d.Open())
+	assert.False(t, d.Special)
+}
+
+func TestSchedule_IsOpen(t *testing.T) {
+	msk, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	w, _ := ParseWeekly("ПН-ПТ: 10:00-13:00, 14:00-18:00")
+	s := Schedule{Weekly: w, Location: msk}
+
+	// 07:30 UTC — 10:30 по Москве
+	assert.True(t, s.IsOpen(time.Date(2026, time.June, 1, 7, 30, 0, 0, time.UTC)))
+	assert.False(t, s.IsOpen(time.Date(2026, time.June, 1, 13, 30, 0, 0, msk)), "перерыв")
+	assert.False(t, s.IsOpen(time.Date(2026, time.June, 1, 18, 0, 0, 0, msk)), "закрытие не входит в интервал")
+	assert.False(t, s.IsOpen(time.Date(2026, time.June, 6, 12, 0, 0, 0, msk)), "суббота")
+}
+
+func TestSchedule_OpenSlots(t *testing.T) {
+	w, _ := ParseWeekly("ПН-ПТ: 10:00-13:00, 14:00-16:00")
+	s := Schedule{
+		Weekly:     w,
+		Exceptions: []Exception{{Date: day(2026, time.June, 2)}},
+	}
+
+	slots := s.OpenSlots(
+		time.Date(2026, time.June, 1, 11, 0, 0, 0, time.UTC),
+		time.Date(2026, time.June, 3, 12, 0, 0, 0, time.UTC),
+		time.Hour,
+	)
+
+	var got []string
+	for _, slot := range slots {
+		got = append(got, slot.Start.Format("02.01 15:04")+"-"+slot.End.Format("15:04"))
+	}
+	assert.Equal(t, []string{
+		"01.06 11:00-12:00", "01.06 12:00-13:00", "01.06 14:00-15:00", "01.06 15:00-16:00",
+		// 2 июня закрыто исключением
+		"03.06 10:00-11:00", "03.06 11:00-12:00",
+	}, got)
+}
+
+func TestDay_SlotStarts(t *testing.T) {
+	d := Day{Hours: []Interval{iv("06:00", "12:00")}}
+	var got []string
+	for _, c := range d.SlotStarts(90 * time.Minute) {
+		got = append(got, c.String())
+	}
+	assert.Equal(t, []string{"06:00", "07:30", "09:00", "10:30"}, got)
+	assert.Nil(t, d.SlotStarts(0))
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// Store — хранилище отложенных заданий.
+type Store interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledJob, error)
+	Complete(ctx context.Context, jobID int64) error
+	Retry(ctx context.Context, jobID int64, runAt time.Time, lastErr string) error
+	Fail(ctx context.Context, jobID int64, lastErr string) error
+}
+
+// Handler выполняет задание одного вида. Ошибка приводит к повтору,
+// ошибка, обёрнутая в Permanent, — к немедленной остановке попыток.
+type Handler func(ctx context.Context, job models.ScheduledJob) error
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку как неисправимую: задание не будет повторяться.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	RetryDelay   time.Duration // задержка повтора растёт линейно с номером попытки
+	// Lease — время, на которое задание закрепляется за репликой. Если реплика упала,
+	// не завершив задание, по истечении lease его возьмёт другая.
+	Lease time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 15 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 20
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = time.Minute
+	}
+	if c.Lease <= 0 {
+		c.Lease = 5 * time.Minute
+	}
+}
+
+// Scheduler периодически забирает наступившие задания из Store и выполняет
+// их зарегистрированными обработчиками.
+type Scheduler struct {
+	store    Store
+	cfg      Config
+	logger   *zap.Logger
+	now      func() time.Time
+	handlers map[string]Handler
+
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func New(store Store, cfg Config, logger *zap.Logger) *Scheduler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	cfg.setDefaults()
+	return &Scheduler{
+		store:    store,
+		cfg:      cfg,
+		logger:   logger,
+		now:      time.Now,
+		handlers: make(map[string]Handler),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register задаёт обработчик для заданий вида kind. Вызывается до Run.
+func (s *Scheduler) Register(kind string, h Handler) {
+	s.handlers[kind] = h
+}
+
+// Run выполняет задания, пока не отменён ctx.
+func (s *Scheduler) Run(ctx context.Context) {
+	defer s.doneOnce.Do(func() { close(s.done) })
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunDue(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("scheduler_poll_failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown ждёт, пока Run завершит текущие задания после отмены своего контекста.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunDue выполняет одну порцию наступивших заданий и возвращает их количество.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	jobs, err := s.store.Claim(ctx, s.cfg.BatchSize, s.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			// оставшиеся задания вернутся в работу по истечении lease
+			return len(jobs), ctx.Err()
+		}
+		s.run(ctx, job)
+	}
+	return len(jobs), nil
+}
+
+func (s *Scheduler) run(ctx context.Context, job models.ScheduledJob) {
+	log := s.logger.With(zap.Int64("job_id", job.ID), zap.String("kind", job.Kind), zap.Int("attempt", job.Attempts))
+
+	h, ok := s.handlers[job.Kind]
+	if !ok {
+		err := fmt.Errorf("no handler for job kind %q", job.Kind)
+		if ferr := s.store.Fail(ctx, job.ID, err.Error()); ferr != nil {
+			log.Error("job_update_failed", zap.Error(ferr))
+		}
+		log.Error("job_failed", zap.Error(err))
+		return
+	}
+
+	err := h(ctx, job)
+	if err == nil {
+		if err := s.store.Complete(ctx, job.ID); err != nil {
+			log.Error("job_update_failed", zap.Error(err))
+			return
+		}
+		log.Info("job_completed")
+		return
+	}
+
+	var perm permanentError
+	if errors.As(err, &perm) || job.Attempts >= s.cfg.MaxAttempts {
+		if ferr := s.store.Fail(ctx, job.ID, err.Error()); ferr != nil {
+			log.Error("job_update_failed", zap.Error(ferr))
+		}
+		log.Error("job_failed", zap.Error(err))
+		return
+	}
+
+	delay := s.cfg.RetryDelay * time.Duration(job.Attempts)
+	if rerr := s.store.Retry(ctx, job.ID, s.now().Add(delay), err.Error()); rerr != nil {
+		log.Error("job_update_failed", zap.Error(rerr))
+	}
+	log.Warn("job_retry", zap.Error(err), zap.Duration("delay", delay))
+}
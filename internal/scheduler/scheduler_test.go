@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+type fakeStore struct {
+	due       []models.ScheduledJob
+	completed []int64
+	retries   map[int64]time.Time
+	failed    map[int64]string
+}
+
+func newFakeStore(jobs ...models.ScheduledJob) *fakeStore {
+	return &fakeStore{due: jobs, retries: map[int64]time.Time{}, failed: map[int64]string{}}
+}
+
+func (f *fakeStore) Claim(_ context.Context, limit int, _ time.Duration) ([]models.ScheduledJob, error) {
+	if limit > len(f.due) {
+		limit = len(f.due)
+	}
+	claimed := f.due[:limit]
+	f.due = f.due[limit:]
+	return claimed, nil
+}
+
+func (f *fakeStore) Complete(_ context.Context, id int64) error {
+	f.completed = append(f.completed, id)
+	return nil
+}
+
+func (f *fakeStore) Retry(_ context.Context, id int64, at time.Time, _ string) error {
+	f.retries[id] = at
+	return nil
+}
+
+func (f *fakeStore) Fail(_ context.Context, id int64, lastErr string) error {
+	f.failed[id] = lastErr
+	return nil
+}
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestScheduler(store Store) *Scheduler {
+	s := New(store, Config{MaxAttempts: 3, RetryDelay: time.Minute}, zap.NewNop())
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func TestScheduler_RunsRegisteredHandler(t *testing.T) {
+	store := newFakeStore(models.ScheduledJob{ID: 1, Kind: "ping", Payload: []byte(`{}`), Attempts: 1})
+	s := newTestScheduler(store)
+
+	var got []int64
+	s.Register("ping", func(_ context.Context, job models.ScheduledJob) error {
+		got = append(got, job.ID)
+		return nil
+	})
+
+	n, err := s.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []int64{1}, got)
+	assert.Equal(t, []int64{1}, store.completed)
+}
+
+func TestScheduler_RetriesWithGrowingDelay(t *testing.T) {
+	store := newFakeStore(models.ScheduledJob{ID: 1, Kind: "ping", Attempts: 2})
+	s := newTestScheduler(store)
+	s.Register("ping", func(context.Context, models.ScheduledJob) error { return errors.New("telegram is down") })
+
+	_, err := s.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, testNow.Add(2*time.Minute), store.retries[1])
+	assert.Empty(t, store.completed)
+}
+
+func TestScheduler_FailsJob(t *testing.T) {
+	store := newFakeStore(
+		models.ScheduledJob{ID: 1, Kind: "ping", Attempts: 3},
+		models.ScheduledJob{ID: 2, Kind: "ping", Attempts: 1},
+		models.ScheduledJob{ID: 3, Kind: "unknown", Attempts: 1},
+	)
+	s := newTestScheduler(store)
+	s.Register("ping", func(_ context.Context, job models.ScheduledJob) error {
+		if job.ID == 2 {
+			return Permanent(errors.New("bad payload"))
+		}
+		return errors.New("telegram is down")
+	})
+
+	_, err := s.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, store.failed, int64(1), "попытки исчерпаны")
+	assert.Contains(t, store.failed, int64(2), "неисправимая ошибка")
+	assert.Contains(t, store.failed, int64(3), "нет обработчика")
+	assert.Empty(t, store.retries)
+}
+
+func TestScheduler_ShutdownWaitsForRun(t *testing.T) {
+	s := newTestScheduler(newFakeStore())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.Run(ctx)
+	cancel()
+
+	shutdownCtx, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	require.NoError(t, s.Shutdown(shutdownCtx))
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// SessionRepository — операции с таблицей user_sessions (см. repository.SessionRepository).
+type SessionRepository interface {
+	SaveSession(ctx context.Context, userID int64, state string, data map[string]interface{}) error
+	GetStateSession(ctx context.Context, userID int64, state string) (*models.UserSession, error)
+	ClearStateSession(ctx context.Context, userID int64, state string) error
+}
+
+// UserLookup ищет пользователя по Telegram ID (см. repository.UserRepository).
+type UserLookup interface {
+	GetUserByTelegramID(ctx context.Context, telegramID int64) (*models.User, error)
+}
+
+// SessionStore хранит состояние диалога в user_sessions: переживает рестарты
+// и доступно всем репликам бота. Сессия определяется пользователем и name
+// (значение current_state), поэтому диалоги одного пользователя, как и в MemoryStore,
+// хранятся независимо и не затирают друг друга.
+type SessionStore[T any] struct {
+	name     string
+	sessions SessionRepository
+	users    UserLookup
+
+	// ids кэширует соответствие Telegram ID -> users.id, оно не меняется
+	ids sync.Map
+}
+
+func NewSessionStore[T any](name string, sessions SessionRepository, users UserLookup) *SessionStore[T] {
+	return &SessionStore[T]{
+		name:     name,
+		sessions: sessions,
+		users:    users,
+	}
+}
+
+func (s *SessionStore[T]) Load(ctx context.Context, telegramID int64) (T, bool, error) {
+	var zero T
+
+	userID, err := s.userID(ctx, telegramID)
+	if err != nil {
+		return zero, false, err
+	}
+
+	session, err := s.sessions.GetStateSession(ctx, userID, s.name)
+	if err != nil {
+		return zero, false, fmt.Errorf("load %s state: %w", s.name, err)
+	}
+	if session == nil {
+		return zero, false, nil
+	}
+
+	state, err := Decode[T](*session)
+	if err != nil {
+		return zero, false, err
+	}
+	return state, true, nil
+}
+
+func (s *SessionStore[T]) Save(ctx context.Context, telegramID int64, state T) error {
+	userID, err := s.userID(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	data, err := toStateData(s.name, state)
+	if err != nil {
+		return err
+	}
+
+	return s.sessions.SaveSession(ctx, userID, s.name, data)
+}
+
+func (s *SessionStore[T]) Delete(ctx context.Context, telegramID int64) error {
+	userID, err := s.userID(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessions.ClearStateSession(ctx, userID, s.name); err != nil {
+		return fmt.Errorf("delete %s state: %w", s.name, err)
+	}
+	return nil
+}
+
+func (s *SessionStore[T]) userID(ctx context.Context, telegramID int64) (int64, error) {
+	if id, ok := s.ids.Load(telegramID); ok {
+		return id.(int64), nil
+	}
+
+	user, err := s.users.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		return 0, fmt.Errorf("get user: %w", err)
+	}
+	if user == nil || user.ID == 0 {
+		return 0, fmt.Errorf("user %d is not registered", telegramID)
+	}
+
+	s.ids.Store(telegramID, user.ID)
+	return user.ID, nil
+}
+
+// toStateData переводит состояние в state_data сессии.
+func toStateData[T any](name string, state T) (map[string]interface{}, error) {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s state: %w", name, err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("%s state must be a JSON object: %w", name, err)
+	}
+	return data, nil
+}
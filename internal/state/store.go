@@ -0,0 +1,95 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// Store хранит состояние многошагового диалога пользователя между апдейтами.
+// Ключ — Telegram ID пользователя.
+type Store[T any] interface {
+	// Load возвращает состояние и false, если диалог не начат.
+	Load(ctx context.Context, userID int64) (T, bool, error)
+	Save(ctx context.Context, userID int64, state T) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+// MemoryStore хранит состояния в памяти процесса. Подходит для тестов и локального
+// запуска: состояние теряется при рестарте и не разделяется между репликами.
+// name — имя диалога, как у SessionStore; оно попадает в удалённые по TTL сессии.
+type MemoryStore[T any] struct {
+	name    string
+	mu      sync.RWMutex
+	items   map[int64]T
+	updated map[int64]time.Time
+	now     func() time.Time
+}
+
+func NewMemoryStore[T any](name string) *MemoryStore[T] {
+	return &MemoryStore[T]{
+		name:    name,
+		items:   make(map[int64]T),
+		updated: make(map[int64]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore[T]) Load(ctx context.Context, userID int64) (T, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.items[userID]
+	return v, ok, nil
+}
+
+func (s *MemoryStore[T]) Save(ctx context.Context, userID int64, state T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[userID] = state
+	s.updated[userID] = s.now()
+	return nil
+}
+
+func (s *MemoryStore[T]) Delete(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, userID)
+	delete(s.updated, userID)
+	return nil
+}
+
+// DeleteStaleSessions удаляет состояния, которые не сохранялись с момента before, если диалог
+// стора входит в conversations. UserID в возвращаемых сессиях — Telegram ID.
+func (s *MemoryStore[T]) DeleteStaleSessions(ctx context.Context, before time.Time, conversations []string) ([]models.UserSession, error) {
+	if !slices.Contains(conversations, s.name) {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stale []models.UserSession
+	for userID, at := range s.updated {
+		if !at.Before(before) {
+			continue
+		}
+
+		data, err := toStateData(s.name, s.items[userID])
+		if err != nil {
+			return stale, fmt.Errorf("user %d: %w", userID, err)
+		}
+		stale = append(stale, models.UserSession{
+			UserID:       userID,
+			CurrentState: s.name,
+			StateData:    data,
+			UpdatedAt:    at,
+		})
+		delete(s.items, userID)
+		delete(s.updated, userID)
+	}
+	return stale, nil
+}
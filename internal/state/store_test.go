@@ -0,0 +1,123 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// sessionKey — уникальный ключ user_sessions.
+type sessionKey struct {
+	userID int64
+	state  string
+}
+
+type fakeSessions struct {
+	sessions map[sessionKey]*models.UserSession
+}
+
+func (f *fakeSessions) SaveSession(ctx context.Context, userID int64, state string, data map[string]interface{}) error {
+	f.sessions[sessionKey{userID, state}] = &models.UserSession{UserID: userID, CurrentState: state, StateData: data}
+	return nil
+}
+
+func (f *fakeSessions) GetStateSession(ctx context.Context, userID int64, state string) (*models.UserSession, error) {
+	return f.sessions[sessionKey{userID, state}], nil
+}
+
+func (f *fakeSessions) ClearStateSession(ctx context.Context, userID int64, state string) error {
+	delete(f.sessions, sessionKey{userID, state})
+	return nil
+}
+
+type fakeUsers struct {
+	ids     map[int64]int64
+	lookups int
+}
+
+func (f *fakeUsers) GetUserByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	f.lookups++
+	return &models.User{ID: f.ids[telegramID], TelegramID: telegramID}, nil
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore[models.BookingState]("booking_form")
+
+	_, ok, err := s.Load(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.Save(ctx, 1, models.BookingState{ServiceID: 3}))
+	got, ok, err := s.Load(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, got.ServiceID)
+
+	require.NoError(t, s.Delete(ctx, 1))
+	_, ok, _ = s.Load(ctx, 1)
+	assert.False(t, ok)
+}
+
+func TestSessionStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	sessions := &fakeSessions{sessions: map[sessionKey]*models.UserSession{}}
+	users := &fakeUsers{ids: map[int64]int64{777: 5}}
+	s := NewSessionStore[models.BookingState]("booking_form", sessions, users)
+
+	date := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	in := models.BookingState{
+		UserID:       777,
+		ServiceID:    1,
+		VisitType:    "private",
+		SelectedDate: date,
+		GuestName:    "Иван Иванов",
+		Step:         models.BookingStepOrg,
+	}
+	require.NoError(t, s.Save(ctx, 777, in))
+
+	// сессия хранится под внутренним users.id
+	require.Contains(t, sessions.sessions, sessionKey{5, "booking_form"})
+
+	out, ok, err := s.Load(ctx, 777)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, in.GuestName, out.GuestName)
+	assert.Equal(t, in.Step, out.Step)
+	assert.True(t, date.Equal(out.SelectedDate))
+
+	assert.Equal(t, 1, users.lookups, "users.id должен кэшироваться")
+
+	require.NoError(t, s.Delete(ctx, 777))
+	_, ok, err = s.Load(ctx, 777)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSessionStore_IgnoresOtherConversation(t *testing.T) {
+	ctx := context.Background()
+	sessions := &fakeSessions{sessions: map[sessionKey]*models.UserSession{
+		{5, "admin_services"}: {UserID: 5, CurrentState: "admin_services", StateData: map[string]interface{}{"step": 1}},
+	}}
+	s := NewSessionStore[models.BookingState]("booking_form", sessions, &fakeUsers{ids: map[int64]int64{777: 5}})
+
+	_, ok, err := s.Load(ctx, 777)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.Delete(ctx, 777))
+	assert.Contains(t, sessions.sessions, sessionKey{5, "admin_services"}, "чужую сессию удалять нельзя")
+}
+
+func TestSessionStore_UnknownUser(t *testing.T) {
+	s := NewSessionStore[models.BookingState]("booking_form",
+		&fakeSessions{sessions: map[sessionKey]*models.UserSession{}}, &fakeUsers{ids: map[int64]int64{}})
+
+	_, _, err := s.Load(context.Background(), 1)
+	assert.Error(t, err)
+}
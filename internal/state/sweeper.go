@@ -0,0 +1,158 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/metrics"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// StaleSessions удаляет состояния диалогов conversations, которые не обновлялись с момента
+// before (см. repository.SessionRepository и MemoryStore). Состояния других диалогов
+// не трогаются: их владельцы сами решают, когда их удалять.
+type StaleSessions interface {
+	DeleteStaleSessions(ctx context.Context, before time.Time, conversations []string) ([]models.UserSession, error)
+}
+
+// StaleSources объединяет несколько источников, например MemoryStore разных диалогов.
+type StaleSources []StaleSessions
+
+func (s StaleSources) DeleteStaleSessions(ctx context.Context, before time.Time, conversations []string) ([]models.UserSession, error) {
+	var stale []models.UserSession
+	for _, source := range s {
+		sessions, err := source.DeleteStaleSessions(ctx, before, conversations)
+		stale = append(stale, sessions...)
+		if err != nil {
+			return stale, err
+		}
+	}
+	return stale, nil
+}
+
+// ExpiredHook вызывается для каждого удалённого диалога, например чтобы сообщить
+// пользователю, что его форма истекла.
+type ExpiredHook func(ctx context.Context, session models.UserSession)
+
+// Sweeper периодически удаляет брошенные диалоги — состояния без активности дольше ttl —
+// и считает их в метрике по диалогу и шагу. Удаляются только диалоги conversations.
+type Sweeper struct {
+	source        StaleSessions
+	conversations []string
+	ttl           time.Duration
+	interval      time.Duration
+	metrics       *metrics.Metrics
+	logger        *zap.Logger
+	now           func() time.Time
+	onExpired     []ExpiredHook
+
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// NewSweeper создаёт уборщик диалогов conversations — значений current_state, за которыми
+// он следит.
+func NewSweeper(source StaleSessions, conversations []string, ttl, interval time.Duration, m *metrics.Metrics, logger *zap.Logger) *Sweeper {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Sweeper{
+		source:        source,
+		conversations: conversations,
+		ttl:           ttl,
+		interval:      interval,
+		metrics:       m,
+		logger:        logger,
+		now:           time.Now,
+		done:          make(chan struct{}),
+	}
+}
+
+// OnExpired регистрирует хук, вызываемый для каждого удалённого диалога. После удаления
+// состояния пользователь уже не узнает об истечении формы из самого диалога.
+func (s *Sweeper) OnExpired(hook ExpiredHook) {
+	s.onExpired = append(s.onExpired, hook)
+}
+
+// Run удаляет брошенные диалоги раз в interval, пока не отменён ctx.
+func (s *Sweeper) Run(ctx context.Context) {
+	defer s.doneOnce.Do(func() { close(s.done) })
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("state_sweep_failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown ждёт завершения Run после отмены его контекста.
+func (s *Sweeper) Shutdown(ctx context.Context) error {
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sweep удаляет брошенные диалоги один раз и возвращает их количество.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	stale, err := s.source.DeleteStaleSessions(ctx, s.now().Add(-s.ttl), s.conversations)
+	for _, session := range stale {
+		CountAbandoned(s.metrics, session.CurrentState, SessionStep(session))
+		for _, hook := range s.onExpired {
+			hook(ctx, session)
+		}
+	}
+	if err != nil {
+		return len(stale), err
+	}
+
+	if len(stale) > 0 {
+		s.logger.Info("stale_states_deleted", zap.Int("count", len(stale)))
+	}
+	return len(stale), nil
+}
+
+// Decode восстанавливает состояние диалога из state_data сессии.
+func Decode[T any](session models.UserSession) (T, error) {
+	var state T
+	raw, err := json.Marshal(session.StateData)
+	if err != nil {
+		return state, fmt.Errorf("marshal %s state: %w", session.CurrentState, err)
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return state, fmt.Errorf("unmarshal %s state: %w", session.CurrentState, err)
+	}
+	return state, nil
+}
+
+// SessionStep возвращает шаг диалога из state_data или пустую строку, если шага нет.
+func SessionStep(session models.UserSession) string {
+	step, ok := session.StateData["step"]
+	if !ok || step == nil {
+		return ""
+	}
+	return fmt.Sprint(step)
+}
+
+// CountAbandoned учитывает брошенный диалог в метрике. m может быть nil.
+func CountAbandoned(m *metrics.Metrics, conversation, step string) {
+	if m != nil {
+		m.ConversationsAbandonedTotal.WithLabelValues(conversation, step).Inc()
+	}
+}
@@ -0,0 +1,136 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/metrics"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+func TestMemoryStore_DeleteStaleSessions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore[models.BookingState]("booking_form")
+
+	s.now = func() time.Time { return now.Add(-3 * time.Hour) }
+	require.NoError(t, s.Save(ctx, 1, models.BookingState{ServiceID: 3, Step: models.BookingStepOrg}))
+	s.now = func() time.Time { return now }
+	require.NoError(t, s.Save(ctx, 2, models.BookingState{ServiceID: 4}))
+
+	stale, err := s.DeleteStaleSessions(ctx, now.Add(-2*time.Hour), []string{"booking_form"})
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	assert.Equal(t, int64(1), stale[0].UserID)
+	assert.Equal(t, "booking_form", stale[0].CurrentState)
+	assert.Equal(t, "3", SessionStep(stale[0]))
+
+	_, ok, _ := s.Load(ctx, 1)
+	assert.False(t, ok)
+	_, ok, _ = s.Load(ctx, 2)
+	assert.True(t, ok)
+}
+
+func TestSweeper_CountsAbandonedPerStep(t *testing.T) {
+	ctx := context.Background()
+	m, err := metrics.NewMetrics(zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore[models.BookingState]("booking_form")
+	store.now = func() time.Time { return now.Add(-time.Hour) }
+	require.NoError(t, store.Save(ctx, 1, models.BookingState{Step: models.BookingStepOrg}))
+	require.NoError(t, store.Save(ctx, 2, models.BookingState{Step: models.BookingStepOrg}))
+	require.NoError(t, store.Save(ctx, 3, models.BookingState{Step: models.BookingStepConfirm}))
+
+	s := NewSweeper(store, []string{"booking_form"}, 30*time.Minute, time.Minute, m, zap.NewNop())
+	s.now = func() time.Time { return now }
+
+	n, err := s.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	abandoned := m.ConversationsAbandonedTotal
+	assert.Equal(t, 2.0, testutil.ToFloat64(abandoned.WithLabelValues("booking_form", "3")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(abandoned.WithLabelValues("booking_form", "5")))
+}
+
+func TestSweeper_NotifiesAboutDeletedForms(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore[models.BookingState]("booking_form")
+	store.now = func() time.Time { return now.Add(-time.Hour) }
+	require.NoError(t, store.Save(ctx, 1, models.BookingState{UserID: 1, ServiceID: 4, Step: models.BookingStepOrg}))
+	store.now = func() time.Time { return now }
+	require.NoError(t, store.Save(ctx, 2, models.BookingState{UserID: 2, ServiceID: 5}))
+
+	s := NewSweeper(store, []string{"booking_form"}, 30*time.Minute, time.Minute, nil, zap.NewNop())
+	s.now = func() time.Time { return now }
+	var expired []models.BookingState
+	s.OnExpired(func(ctx context.Context, session models.UserSession) {
+		form, err := Decode[models.BookingState](session)
+		require.NoError(t, err)
+		expired = append(expired, form)
+	})
+
+	n, err := s.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	// пользователь узнает об истечении, хотя состояния формы уже нет
+	require.Len(t, expired, 1)
+	assert.Equal(t, int64(1), expired[0].UserID)
+	assert.Equal(t, 4, expired[0].ServiceID)
+	_, ok, _ := store.Load(ctx, 1)
+	assert.False(t, ok)
+}
+
+func TestSweeper_KeepsUnregisteredConversations(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)
+	booking := NewMemoryStore[models.BookingState]("booking_form")
+	admin := NewMemoryStore[models.AdminServiceState]("admin_services")
+	booking.now = func() time.Time { return now.Add(-time.Hour) }
+	admin.now = booking.now
+	require.NoError(t, booking.Save(ctx, 1, models.BookingState{Step: models.BookingStepOrg}))
+	require.NoError(t, admin.Save(ctx, 1, models.AdminServiceState{Step: models.AdminServiceStepInput}))
+
+	s := NewSweeper(StaleSources{booking, admin}, []string{"booking_form"}, 30*time.Minute, time.Minute, nil, zap.NewNop())
+	s.now = func() time.Time { return now }
+
+	n, err := s.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, ok, _ := admin.Load(ctx, 1)
+	assert.True(t, ok, "диалог, за которым уборщик не следит, остаётся")
+}
+
+func TestSessionStep(t *testing.T) {
+	assert.Equal(t, "2", SessionStep(models.UserSession{StateData: map[string]interface{}{"step": float64(2)}}))
+	assert.Equal(t, "", SessionStep(models.UserSession{StateData: map[string]interface{}{}}))
+}
+
+func TestStaleSources_CollectsAllStores(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	booking := NewMemoryStore[models.BookingState]("booking_form")
+	booking.now = func() time.Time { return now.Add(-3 * time.Hour) }
+	require.NoError(t, booking.Save(ctx, 1, models.BookingState{Step: models.BookingStepOrg}))
+
+	admin := NewMemoryStore[models.AdminServiceState]("admin_services")
+	admin.now = func() time.Time { return now.Add(-3 * time.Hour) }
+	require.NoError(t, admin.Save(ctx, 2, models.AdminServiceState{Step: models.AdminServiceStepInput}))
+
+	stale, err := StaleSources{booking, admin}.DeleteStaleSessions(ctx, now.Add(-2*time.Hour), []string{"booking_form", "admin_services"})
+	require.NoError(t, err)
+	require.Len(t, stale, 2)
+	assert.Equal(t, "booking_form", stale[0].CurrentState)
+	assert.Equal(t, "admin_services", stale[1].CurrentState)
+	assert.Equal(t, "2", SessionStep(stale[1]))
+}
@@ -0,0 +1,157 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "https://api.tracker.yandex.net"
+
+// Issue — задача, создаваемая в Трекере.
+type Issue struct {
+	Queue       string
+	Summary     string
+	Description string
+	// Unique — ключ идемпотентности: повторное создание задачи с тем же ключом
+	// Трекер отклоняет с 409, и клиент возвращает уже существующую задачу.
+	Unique string
+}
+
+// Client создаёт задачи в Трекере.
+type Client interface {
+	CreateIssue(ctx context.Context, issue Issue) (key string, err error)
+}
+
+// StatusError — неуспешный ответ API Трекера.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("tracker returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary сообщает, что запрос имеет смысл повторить позже.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// IsPermanent сообщает, что повтор запроса не поможет: ошибка в данных или доступах.
+// Сетевые ошибки и таймауты считаются временными.
+func IsPermanent(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return !se.Temporary()
+	}
+	return false
+}
+
+type HTTPClient struct {
+	token      string
+	orgID      string
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewHTTPClient создаёт клиент API Трекера v2. Пустой baseURL — DefaultBaseURL.
+func NewHTTPClient(baseURL, token, orgID string) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &HTTPClient{
+		token: token,
+		orgID: orgID,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+type createIssueRequest struct {
+	Queue       string `json:"queue"`
+	Summary     string `json:"summary"`
+	Description string `json:"description,omitempty"`
+	Unique      string `json:"unique,omitempty"`
+}
+
+type issueResponse struct {
+	Key string `json:"key"`
+}
+
+// CreateIssue создаёт задачу и возвращает её ключ (например, BOOK-42).
+func (c *HTTPClient) CreateIssue(ctx context.Context, issue Issue) (string, error) {
+	var created issueResponse
+	err := c.do(ctx, "/v2/issues", createIssueRequest{
+		Queue:       issue.Queue,
+		Summary:     issue.Summary,
+		Description: issue.Description,
+		Unique:      issue.Unique,
+	}, &created)
+
+	var se *StatusError
+	if errors.As(err, &se) && se.StatusCode == http.StatusConflict && issue.Unique != "" {
+		// задача уже создана предыдущей попыткой, ответ на которую потерялся
+		return c.findByUnique(ctx, issue.Unique)
+	}
+	if err != nil {
+		return "", err
+	}
+	if created.Key == "" {
+		return "", errors.New("tracker response has no issue key")
+	}
+	return created.Key, nil
+}
+
+func (c *HTTPClient) findByUnique(ctx context.Context, unique string) (string, error) {
+	var found []issueResponse
+	body := map[string]interface{}{"filter": map[string]string{"unique": unique}}
+	if err := c.do(ctx, "/v2/issues/_search", body, &found); err != nil {
+		return "", fmt.Errorf("search issue by unique: %w", err)
+	}
+	if len(found) == 0 || found[0].Key == "" {
+		return "", fmt.Errorf("issue with unique %q not found", unique)
+	}
+	return found[0].Key, nil
+}
+
+func (c *HTTPClient) do(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "OAuth "+c.token)
+	if c.orgID != "" {
+		req.Header.Set("X-Org-ID", c.orgID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("tracker request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
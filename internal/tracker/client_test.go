@@ -0,0 +1,120 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTracker — локальная замена API Трекера: создаёт задачи и отвечает 409 на повтор unique.
+type fakeTracker struct {
+	mu       sync.Mutex
+	issues   map[string]string // unique -> key
+	requests int
+	failWith int // если не 0, все запросы получают этот статус
+}
+
+func newFakeTracker(t *testing.T) (*fakeTracker, *httptest.Server) {
+	ft := &fakeTracker{issues: map[string]string{}}
+	srv := httptest.NewServer(ft)
+	t.Cleanup(srv.Close)
+	return ft, srv
+}
+
+func (f *fakeTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	if r.Header.Get("Authorization") != "OAuth test-token" || r.Header.Get("X-Org-ID") != "42" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if f.failWith != 0 {
+		w.WriteHeader(f.failWith)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v2/issues":
+		var req createIssueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Queue == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, ok := f.issues[req.Unique]; ok {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		key := req.Queue + "-" + strconv.Itoa(len(f.issues)+1)
+		f.issues[req.Unique] = key
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"key": key})
+	case "/v2/issues/_search":
+		var req struct {
+			Filter struct {
+				Unique string `json:"unique"`
+			} `json:"filter"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		found := []map[string]string{}
+		if key, ok := f.issues[req.Filter.Unique]; ok {
+			found = append(found, map[string]string{"key": key})
+		}
+		_ = json.NewEncoder(w).Encode(found)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestHTTPClient_CreateIssue(t *testing.T) {
+	_, srv := newFakeTracker(t)
+	c := NewHTTPClient(srv.URL, "test-token", "42")
+
+	key, err := c.CreateIssue(context.Background(), Issue{Queue: "BOOK", Summary: "s", Unique: "booking-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "BOOK-1", key)
+}
+
+func TestHTTPClient_CreateIssue_DuplicateReturnsExisting(t *testing.T) {
+	_, srv := newFakeTracker(t)
+	c := NewHTTPClient(srv.URL, "test-token", "42")
+	issue := Issue{Queue: "BOOK", Summary: "s", Unique: "booking-1"}
+
+	first, err := c.CreateIssue(context.Background(), issue)
+	require.NoError(t, err)
+
+	second, err := c.CreateIssue(context.Background(), issue)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestHTTPClient_CreateIssue_Errors(t *testing.T) {
+	ft, srv := newFakeTracker(t)
+
+	ft.failWith = http.StatusServiceUnavailable
+	_, err := NewHTTPClient(srv.URL, "test-token", "42").CreateIssue(context.Background(), Issue{Queue: "BOOK", Unique: "booking-1"})
+	require.Error(t, err)
+	assert.False(t, IsPermanent(err))
+
+	ft.failWith = 0
+	_, err = NewHTTPClient(srv.URL, "wrong-token", "42").CreateIssue(context.Background(), Issue{Queue: "BOOK", Unique: "booking-1"})
+	require.Error(t, err)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestHTTPClient_NetworkErrorIsTemporary(t *testing.T) {
+	_, srv := newFakeTracker(t)
+	srv.Close()
+
+	_, err := NewHTTPClient(srv.URL, "test-token", "42").CreateIssue(context.Background(), Issue{Queue: "BOOK"})
+	require.Error(t, err)
+	assert.False(t, IsPermanent(err))
+}
@@ -0,0 +1,230 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/metrics"
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+// OutboxStore — очередь бронирований, для которых нужно создать тикет.
+type OutboxStore interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.TrackerOutboxEntry, error)
+	Complete(ctx context.Context, entry models.TrackerOutboxEntry, ticketKey string) error
+	Retry(ctx context.Context, entry models.TrackerOutboxEntry, retryAt time.Time, lastErr string) error
+	Fail(ctx context.Context, entry models.TrackerOutboxEntry, lastErr string) error
+}
+
+// BookingSource загружает бронирование для описания тикета.
+type BookingSource interface {
+	GetBooking(ctx context.Context, bookingID int64) (*models.Booking, error)
+}
+
+type WorkerConfig struct {
+	Queue        string
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Lease — на сколько запись скрывается от других воркеров, пока идёт запрос в Трекер.
+	Lease time.Duration
+}
+
+func (c *WorkerConfig) setDefaults() {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 10 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 10
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 10
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = 30 * time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Hour
+	}
+	if c.Lease <= 0 {
+		c.Lease = time.Minute
+	}
+}
+
+// Worker разбирает tracker_outbox: создаёт тикеты и сохраняет их ключи в бронированиях.
+// Тикет создаётся с ключом идемпотентности, поэтому повтор после сбоя не приводит к дублю.
+type Worker struct {
+	store    OutboxStore
+	bookings BookingSource
+	client   Client
+	cfg      WorkerConfig
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+	now      func() time.Time
+
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func NewWorker(store OutboxStore, bookings BookingSource, client Client, cfg WorkerConfig, m *metrics.Metrics, logger *zap.Logger) *Worker {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	cfg.setDefaults()
+	return &Worker{
+		store:    store,
+		bookings: bookings,
+		client:   client,
+		cfg:      cfg,
+		metrics:  m,
+		logger:   logger,
+		now:      time.Now,
+		done:     make(chan struct{}),
+	}
+}
+
+// Run обрабатывает очередь, пока не отменён ctx.
+func (w *Worker) Run(ctx context.Context) {
+	defer w.doneOnce.Do(func() { close(w.done) })
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.ProcessBatch(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("tracker_outbox_batch_failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown ждёт завершения Run после отмены его контекста.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ProcessBatch обрабатывает одну порцию записей и возвращает их количество.
+func (w *Worker) ProcessBatch(ctx context.Context) (int, error) {
+	entries, err := w.store.Claim(ctx, w.cfg.BatchSize, w.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			// необработанные записи вернутся в очередь по истечении lease
+			return len(entries), ctx.Err()
+		}
+		w.process(ctx, entry)
+	}
+	return len(entries), nil
+}
+
+func (w *Worker) process(ctx context.Context, entry models.TrackerOutboxEntry) {
+	log := w.logger.With(zap.Int64("booking_id", entry.BookingID), zap.Int("attempt", entry.Attempts))
+
+	key, err := w.createTicket(ctx, entry)
+	if err == nil {
+		if err := w.store.Complete(ctx, entry, key); err != nil {
+			// тикет уже есть; при повторе Трекер вернёт его же по ключу идемпотентности
+			log.Error("tracker_ticket_save_failed", zap.Error(err), zap.String("ticket", key))
+			return
+		}
+		w.count("created")
+		log.Info("tracker_ticket_created", zap.String("ticket", key))
+		return
+	}
+
+	if IsPermanent(err) || entry.Attempts >= w.cfg.MaxAttempts {
+		if ferr := w.store.Fail(ctx, entry, err.Error()); ferr != nil {
+			log.Error("tracker_outbox_update_failed", zap.Error(ferr))
+		}
+		w.count("failed")
+		log.Error("tracker_ticket_failed", zap.Error(err))
+		return
+	}
+
+	delay := Backoff(entry.Attempts, w.cfg.BaseBackoff, w.cfg.MaxBackoff)
+	if rerr := w.store.Retry(ctx, entry, w.now().Add(delay), err.Error()); rerr != nil {
+		log.Error("tracker_outbox_update_failed", zap.Error(rerr))
+	}
+	w.count("retry")
+	log.Warn("tracker_ticket_retry", zap.Error(err), zap.Duration("delay", delay))
+}
+
+func (w *Worker) createTicket(ctx context.Context, entry models.TrackerOutboxEntry) (string, error) {
+	b, err := w.bookings.GetBooking(ctx, entry.BookingID)
+	if err != nil {
+		return "", fmt.Errorf("get booking: %w", err)
+	}
+	return w.client.CreateIssue(ctx, bookingIssue(w.cfg.Queue, b))
+}
+
+func (w *Worker) count(result string) {
+	if w.metrics != nil {
+		w.metrics.TrackerTicketsTotal.WithLabelValues(result).Inc()
+	}
+}
+
+// Backoff возвращает задержку перед следующей попыткой: base·2^(attempt-1), но не больше max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+func bookingIssue(queue string, b *models.Booking) Issue {
+	var desc strings.Builder
+	fmt.Fprintf(&desc, "Бронирование #%d\n", b.ID)
+	fmt.Fprintf(&desc, "Услуга: %s\n", b.ServiceTitle)
+	fmt.Fprintf(&desc, "Дата: %s\n", b.When())
+	fmt.Fprintf(&desc, "Гость: %s\n", b.GuestName)
+	if b.GuestOrganization.Valid && b.GuestOrganization.String != "" {
+		fmt.Fprintf(&desc, "Организация: %s\n", b.GuestOrganization.String)
+	}
+	if b.GuestPosition.Valid && b.GuestPosition.String != "" {
+		fmt.Fprintf(&desc, "Должность: %s\n", b.GuestPosition.String)
+	}
+	if b.GuestCount > 1 {
+		fmt.Fprintf(&desc, "Гости (%d): %s\n", b.GuestCount, b.GuestNames.String)
+	}
+	if b.VisitType.Valid && b.VisitType.String != "" {
+		fmt.Fprintf(&desc, "Тип посещения: %s\n", b.VisitType.String)
+	}
+	fmt.Fprintf(&desc, "Telegram ID: %d\n", b.UserTelegramID)
+
+	return Issue{
+		Queue:       queue,
+		Summary:     fmt.Sprintf("%s — %s, %s", b.ServiceTitle, b.GuestName, b.When()),
+		Description: desc.String(),
+		Unique:      fmt.Sprintf("booking-%d", b.ID),
+	}
+}
@@ -0,0 +1,179 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yandex-development-2-team/Go/internal/models"
+)
+
+type fakeOutbox struct {
+	pending   []models.TrackerOutboxEntry
+	completed map[int64]string
+	retries   map[int64]time.Time
+	failed    map[int64]string
+}
+
+func newFakeOutbox(entries ...models.TrackerOutboxEntry) *fakeOutbox {
+	return &fakeOutbox{
+		pending:   entries,
+		completed: map[int64]string{},
+		retries:   map[int64]time.Time{},
+		failed:    map[int64]string{},
+	}
+}
+
+func (f *fakeOutbox) Claim(_ context.Context, limit int, _ time.Duration) ([]models.TrackerOutboxEntry, error) {
+	if limit > len(f.pending) {
+		limit = len(f.pending)
+	}
+	claimed := f.pending[:limit]
+	f.pending = f.pending[limit:]
+	return claimed, nil
+}
+
+func (f *fakeOutbox) Complete(_ context.Context, e models.TrackerOutboxEntry, key string) error {
+	f.completed[e.BookingID] = key
+	return nil
+}
+
+func (f *fakeOutbox) Retry(_ context.Context, e models.TrackerOutboxEntry, at time.Time, _ string) error {
+	f.retries[e.BookingID] = at
+	return nil
+}
+
+func (f *fakeOutbox) Fail(_ context.Context, e models.TrackerOutboxEntry, lastErr string) error {
+	f.failed[e.BookingID] = lastErr
+	return nil
+}
+
+type fakeBookings struct{}
+
+func (fakeBookings) GetBooking(_ context.Context, id int64) (*models.Booking, error) {
+	return &models.Booking{
+		ID:           id,
+		ServiceTitle: "Экскурсия",
+		GuestName:    "Иван",
+		BookingDate:  time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+type fakeClient struct {
+	err    error
+	issues []Issue
+}
+
+func (c *fakeClient) CreateIssue(_ context.Context, issue Issue) (string, error) {
+	c.issues = append(c.issues, issue)
+	if c.err != nil {
+		return "", c.err
+	}
+	return "BOOK-1", nil
+}
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestWorker(store OutboxStore, client Client) *Worker {
+	w := NewWorker(store, fakeBookings{}, client, WorkerConfig{
+		Queue:       "BOOK",
+		MaxAttempts: 3,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  10 * time.Minute,
+	}, nil, zap.NewNop())
+	w.now = func() time.Time { return testNow }
+	return w
+}
+
+func TestWorker_CreatesTicket(t *testing.T) {
+	store := newFakeOutbox(models.TrackerOutboxEntry{ID: 1, BookingID: 10, Attempts: 1})
+	client := &fakeClient{}
+
+	n, err := newTestWorker(store, client).ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "BOOK-1", store.completed[10])
+
+	require.Len(t, client.issues, 1)
+	assert.Equal(t, "BOOK", client.issues[0].Queue)
+	assert.Equal(t, "booking-10", client.issues[0].Unique)
+	assert.Contains(t, client.issues[0].Summary, "Экскурсия")
+	assert.Contains(t, client.issues[0].Description, "14.03.2026")
+}
+
+func TestWorker_TemporaryErrorIsRetriedWithBackoff(t *testing.T) {
+	store := newFakeOutbox(models.TrackerOutboxEntry{ID: 1, BookingID: 10, Attempts: 2})
+	client := &fakeClient{err: &StatusError{StatusCode: http.StatusServiceUnavailable}}
+
+	_, err := newTestWorker(store, client).ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, store.completed)
+	assert.Empty(t, store.failed)
+	assert.Equal(t, testNow.Add(2*time.Minute), store.retries[10])
+}
+
+func TestWorker_FailsAfterMaxAttempts(t *testing.T) {
+	store := newFakeOutbox(models.TrackerOutboxEntry{ID: 1, BookingID: 10, Attempts: 3})
+	client := &fakeClient{err: errors.New("connection refused")}
+
+	_, err := newTestWorker(store, client).ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, store.retries)
+	assert.Contains(t, store.failed[10], "connection refused")
+}
+
+func TestWorker_PermanentErrorFailsImmediately(t *testing.T) {
+	store := newFakeOutbox(models.TrackerOutboxEntry{ID: 1, BookingID: 10, Attempts: 1})
+	client := &fakeClient{err: &StatusError{StatusCode: http.StatusForbidden}}
+
+	_, err := newTestWorker(store, client).ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, store.retries)
+	assert.Contains(t, store.failed, int64(10))
+}
+
+func TestWorker_AgainstStandIn(t *testing.T) {
+	ft, srv := newFakeTracker(t)
+	store := newFakeOutbox(models.TrackerOutboxEntry{ID: 1, BookingID: 10, Attempts: 1})
+	w := newTestWorker(store, NewHTTPClient(srv.URL, "test-token", "42"))
+
+	ft.failWith = http.StatusInternalServerError
+	_, err := w.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, store.retries, int64(10))
+
+	// Трекер восстановился: запись снова в очереди, тикет создаётся один раз
+	ft.failWith = 0
+	store.pending = append(store.pending, models.TrackerOutboxEntry{ID: 1, BookingID: 10, Attempts: 2})
+	_, err = w.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "BOOK-1", store.completed[10])
+	assert.Len(t, ft.issues, 1)
+}
+
+func TestWorker_RunStopsOnCancel(t *testing.T) {
+	w := newTestWorker(newFakeOutbox(), &fakeClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go w.Run(ctx)
+	cancel()
+
+	shutdownCtx, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	require.NoError(t, w.Shutdown(shutdownCtx))
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 5*time.Minute
+	assert.Equal(t, 30*time.Second, Backoff(1, base, max))
+	assert.Equal(t, time.Minute, Backoff(2, base, max))
+	assert.Equal(t, 2*time.Minute, Backoff(3, base, max))
+	assert.Equal(t, max, Backoff(10, base, max))
+	assert.Equal(t, max, Backoff(100, base, max))
+}
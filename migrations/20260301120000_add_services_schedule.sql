@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE services
+    ADD COLUMN work_days      SMALLINT[] NOT NULL DEFAULT '{1,2,3,4,5}',  -- дни недели ISO: 1=пн ... 7=вс
+    ADD COLUMN daily_capacity INTEGER    NOT NULL DEFAULT 10;             -- бронирований в день
+
+UPDATE services SET work_days = '{2,3,4,5,6,7}', daily_capacity = 20 WHERE id IN (1, 2);
+UPDATE services SET work_days = '{1,2,3,4,5,6,7}', daily_capacity = 10 WHERE id = 3;
+UPDATE services SET work_days = '{1,2,3,4,5,6,7}', daily_capacity = 8 WHERE id IN (4, 5);
+UPDATE services SET work_days = '{}', daily_capacity = 0 WHERE id = 6;
+
+CREATE INDEX idx_bookings_service_date ON bookings(service_id, booking_date);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_bookings_service_date;
+ALTER TABLE services
+    DROP COLUMN IF EXISTS daily_capacity,
+    DROP COLUMN IF EXISTS work_days;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE service_slots (
+                               id BIGSERIAL PRIMARY KEY,
+                               service_id INTEGER NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+                               slot_date DATE NOT NULL,
+                               slot_time TIME,  -- NULL для услуг, которые бронируются на весь день
+                               capacity INTEGER NOT NULL CHECK (capacity >= 0),
+                               booked INTEGER NOT NULL DEFAULT 0 CHECK (booked >= 0),
+                               created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                               updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                               CONSTRAINT service_slots_booked_le_capacity CHECK (booked <= capacity),
+                               CONSTRAINT service_slots_service_date_time_key UNIQUE NULLS NOT DISTINCT (service_id, slot_date, slot_time)
+);
+
+ALTER TABLE bookings ADD COLUMN slot_id BIGINT REFERENCES service_slots(id);
+CREATE INDEX idx_bookings_slot_id ON bookings(slot_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_bookings_slot_id;
+ALTER TABLE bookings DROP COLUMN IF EXISTS slot_id;
+DROP TABLE IF EXISTS service_slots;
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE services
+    ADD COLUMN open_time     TIME,                        -- начало работы для почасовых услуг
+    ADD COLUMN close_time    TIME,                        -- окончание работы (последний слот заканчивается не позже)
+    ADD COLUMN slot_minutes  SMALLINT,                    -- длительность слота; NULL — бронирование на весь день
+    ADD COLUMN slot_capacity INTEGER NOT NULL DEFAULT 1;  -- бронирований в одном слоте
+
+UPDATE services
+SET open_time = '06:00', close_time = '23:00', slot_minutes = 60, slot_capacity = 1
+WHERE id IN (4, 5);
+
+-- +goose Down
+ALTER TABLE services
+    DROP COLUMN IF EXISTS slot_capacity,
+    DROP COLUMN IF EXISTS slot_minutes,
+    DROP COLUMN IF EXISTS close_time,
+    DROP COLUMN IF EXISTS open_time;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE booking_status_history (
+                                        id BIGSERIAL PRIMARY KEY,
+                                        booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+                                        old_status VARCHAR(50),  -- NULL при создании бронирования
+                                        new_status VARCHAR(50) NOT NULL,
+                                        changed_by BIGINT,  -- telegram_id пользователя или администратора; NULL — система
+                                        changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_booking_status_history_booking_id ON booking_status_history(booking_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_booking_status_history_booking_id;
+DROP TABLE IF EXISTS booking_status_history;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE tracker_outbox (
+                                id BIGSERIAL PRIMARY KEY,
+                                booking_id BIGINT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+                                attempts INTEGER NOT NULL DEFAULT 0,
+                                next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                last_error TEXT,
+                                processed_at TIMESTAMP,  -- тикет создан и сохранён в bookings.tracker_ticket_id
+                                failed_at TIMESTAMP,     -- попытки исчерпаны или ошибка неисправима
+                                created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_tracker_outbox_pending ON tracker_outbox(next_attempt_at)
+    WHERE processed_at IS NULL AND failed_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tracker_outbox_pending;
+DROP TABLE IF EXISTS tracker_outbox;
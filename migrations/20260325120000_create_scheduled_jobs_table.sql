@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE scheduled_jobs (
+                                id BIGSERIAL PRIMARY KEY,
+                                kind VARCHAR(64) NOT NULL,
+                                dedup_key VARCHAR(255) NOT NULL UNIQUE, -- повторное планирование того же задания игнорируется
+                                payload JSONB NOT NULL DEFAULT '{}',
+                                run_at TIMESTAMPTZ NOT NULL,
+                                status VARCHAR(16) NOT NULL DEFAULT 'pending'
+                                    CHECK (status IN ('pending', 'running', 'done', 'failed')),
+                                attempts INTEGER NOT NULL DEFAULT 0,
+                                locked_until TIMESTAMPTZ, -- running-задание с истёкшей блокировкой снова берётся в работу
+                                last_error TEXT,
+                                created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_scheduled_jobs_due ON scheduled_jobs(run_at) WHERE status IN ('pending', 'running');
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_scheduled_jobs_due;
+DROP TABLE IF EXISTS scheduled_jobs;
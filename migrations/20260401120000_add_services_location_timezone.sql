@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE services
+    ADD COLUMN location TEXT,                                          -- адрес для приглашений в календарь
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Europe/Moscow';  -- часовой пояс дат и времени бронирований
+
+UPDATE services SET location = 'Москва, Лаврушинский переулок, 10' WHERE id = 1;
+UPDATE services SET location = 'Москва, улица Волхонка, 12' WHERE id = 2;
+UPDATE services SET location = 'Москва, Малая Бронная улица, 4' WHERE id = 3;
+UPDATE services SET location = 'Москва, Лужнецкая набережная, 24' WHERE id = 4;
+UPDATE services SET location = 'Москва, Пресненская набережная, 10' WHERE id = 5;
+
+-- +goose Down
+ALTER TABLE services
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS location;
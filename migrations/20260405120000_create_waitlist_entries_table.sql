@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE waitlist_entries (
+                                  id BIGSERIAL PRIMARY KEY,
+                                  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                  service_id SMALLINT NOT NULL,
+                                  slot_date DATE NOT NULL,
+                                  slot_time TIME,  -- NULL — ожидание места на весь день
+                                  guest_name VARCHAR(255) NOT NULL,
+                                  guest_organization VARCHAR(255),
+                                  guest_position VARCHAR(255),
+                                  visit_type VARCHAR(50),
+                                  status VARCHAR(16) NOT NULL DEFAULT 'waiting'
+                                      CHECK (status IN ('waiting', 'offered', 'claimed', 'expired', 'declined')),
+                                  slot_id BIGINT REFERENCES service_slots(id) ON DELETE SET NULL, -- место, удерживаемое на время предложения
+                                  offered_at TIMESTAMPTZ,
+                                  offer_expires_at TIMESTAMPTZ,
+                                  booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+                                  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- пользователь стоит в очереди на один слот не больше одного раза
+CREATE UNIQUE INDEX waitlist_entries_active_key
+    ON waitlist_entries(user_id, service_id, slot_date, slot_time) NULLS NOT DISTINCT
+    WHERE status IN ('waiting', 'offered');
+CREATE INDEX idx_waitlist_entries_queue
+    ON waitlist_entries(service_id, slot_date, created_at)
+    WHERE status = 'waiting';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_waitlist_entries_queue;
+DROP INDEX IF EXISTS waitlist_entries_active_key;
+DROP TABLE IF EXISTS waitlist_entries;
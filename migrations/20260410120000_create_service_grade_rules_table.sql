@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE service_grade_rules (
+                                     service_id INTEGER NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+                                     visit_type VARCHAR(50) NOT NULL DEFAULT '',  -- '' — правило для любого типа посещения
+                                     grade SMALLINT NOT NULL CHECK (grade BETWEEN 0 AND 4),
+                                     can_view BOOLEAN NOT NULL DEFAULT TRUE,      -- учитывается только в правилах с visit_type = ''
+                                     can_book BOOLEAN NOT NULL DEFAULT TRUE,
+                                     monthly_limit SMALLINT CHECK (monthly_limit >= 0),  -- NULL — без ограничения
+                                     PRIMARY KEY (service_id, visit_type, grade)
+);
+
+-- приватный тур в Третьяковскую галерею — только для senior и выше
+INSERT INTO service_grade_rules (service_id, visit_type, grade, can_book) VALUES
+                                                                              (1, 'private', 0, FALSE),
+                                                                              (1, 'private', 1, FALSE),
+                                                                              (1, 'private', 2, FALSE);
+
+-- внешним пользователям — не больше двух игр в месяц
+INSERT INTO service_grade_rules (service_id, grade, monthly_limit) VALUES
+                                                                       (4, 0, 2),
+                                                                       (5, 0, 2);
+
+-- +goose Down
+DROP TABLE IF EXISTS service_grade_rules;
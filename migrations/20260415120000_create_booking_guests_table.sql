@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE booking_guests (
+                                id BIGSERIAL PRIMARY KEY,
+                                booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+                                sort_order SMALLINT NOT NULL,  -- 1 — контактное лицо, оно же в bookings.guest_name
+                                name VARCHAR(255) NOT NULL,
+                                organization VARCHAR(255),
+                                position VARCHAR(255),
+                                created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                UNIQUE (booking_id, sort_order)
+);
+
+-- гости уже созданных бронирований
+INSERT INTO booking_guests (booking_id, sort_order, name, organization, position)
+SELECT id, 1, guest_name, guest_organization, guest_position FROM bookings;
+
+ALTER TABLE services
+    ADD COLUMN max_guests SMALLINT NOT NULL DEFAULT 1 CHECK (max_guests >= 1);  -- сколько гостей можно указать в одном бронировании
+
+UPDATE services SET max_guests = 10 WHERE id IN (1, 2);
+UPDATE services SET max_guests = 4 WHERE id IN (3, 4, 5);
+
+-- гости заявки из листа ожидания, переносятся в booking_guests при бронировании
+ALTER TABLE waitlist_entries
+    ADD COLUMN guests JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE waitlist_entries
+    DROP COLUMN IF EXISTS guests;
+ALTER TABLE services
+    DROP COLUMN IF EXISTS max_guests;
+DROP TABLE IF EXISTS booking_guests;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- по updated_at фоновая задача ищет брошенные диалоги
+CREATE INDEX idx_user_sessions_updated_at ON user_sessions(updated_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_sessions_updated_at;
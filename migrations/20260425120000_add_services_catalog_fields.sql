@@ -0,0 +1,68 @@
+-- +goose Up
+ALTER TABLE services
+    ADD COLUMN description   TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN rules         TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN schedule      TEXT    NOT NULL DEFAULT '',     -- расписание для карточки услуги; пусто — не показывать
+    ADD COLUMN visit_options JSONB   NOT NULL DEFAULT '[]',   -- [{"title": "Приватный тур", "visit_type": "private"}, ...]
+    ADD COLUMN has_booking   BOOLEAN NOT NULL DEFAULT FALSE,  -- кнопка «Забронировать» для услуг без вариантов посещения
+    ADD COLUMN sort_order    INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN is_active     BOOLEAN NOT NULL DEFAULT TRUE;
+
+UPDATE services SET
+    description = 'Государственная Третьяковская галерея — крупнейшее собрание русского искусства.',
+    rules = 'Максимум 20 человек. Фото без вспышки.',
+    schedule = 'ВТ-ВС: 10:00-18:00',
+    visit_options = '[{"title": "Приватный тур", "visit_type": "private"}, {"title": "Групповой тур", "visit_type": "public"}]',
+    sort_order = 10
+WHERE id = 1;
+
+UPDATE services SET
+    description = 'ГМИИ им. А. С. Пушкина — коллекция европейского искусства от античности до XX века.',
+    rules = 'Максимум 20 человек. Крупные сумки сдаются в гардероб.',
+    schedule = 'ВТ-ВС: 11:00-20:00',
+    visit_options = '[{"title": "Приватный тур", "visit_type": "private"}, {"title": "Групповой тур", "visit_type": "public"}]',
+    sort_order = 20
+WHERE id = 2;
+
+UPDATE services SET
+    description = 'Спектакли Театра на Малой Бронной для гостей компании.',
+    rules = 'Вход по именным билетам. Опоздавшие проходят в антракте.',
+    schedule = 'Ежедневно, начало спектаклей в 19:00',
+    has_booking = TRUE,
+    sort_order = 30
+WHERE id = 3;
+
+UPDATE services SET
+    description = 'Крытые теннисные корты в Лужниках, ракетки и мячи выдаются на месте.',
+    rules = 'Бронирование по часу. Спортивная обувь обязательна.',
+    schedule = 'Ежедневно: 06:00-23:00',
+    has_booking = TRUE,
+    sort_order = 40
+WHERE id = 4;
+
+UPDATE services SET
+    description = 'Корты для падел-тенниса в Москва-Сити.',
+    rules = 'Бронирование по часу, до 4 игроков на корте.',
+    schedule = 'Ежедневно: 06:00-23:00',
+    has_booking = TRUE,
+    sort_order = 50
+WHERE id = 5;
+
+UPDATE services SET
+    description = 'Подборка светских событий месяца: премьеры, выставки и закрытые вечера.',
+    rules = 'Приглашения на события выдаются по запросу.',
+    sort_order = 60
+WHERE id = 6;
+
+CREATE INDEX idx_services_active_sort ON services(sort_order, id) WHERE is_active;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_services_active_sort;
+ALTER TABLE services
+    DROP COLUMN IF EXISTS is_active,
+    DROP COLUMN IF EXISTS sort_order,
+    DROP COLUMN IF EXISTS has_booking,
+    DROP COLUMN IF EXISTS visit_options,
+    DROP COLUMN IF EXISTS schedule,
+    DROP COLUMN IF EXISTS rules,
+    DROP COLUMN IF EXISTS description;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- новые услуги создаются из бота, id выдаёт последовательность после сидов
+CREATE SEQUENCE services_id_seq OWNED BY services.id;
+SELECT setval('services_id_seq', (SELECT COALESCE(MAX(id), 0) + 1 FROM services), false);
+ALTER TABLE services ALTER COLUMN id SET DEFAULT nextval('services_id_seq');
+
+CREATE TABLE admin_audit_log (
+                                 id BIGSERIAL PRIMARY KEY,
+                                 admin_telegram_id BIGINT NOT NULL,
+                                 action VARCHAR(32) NOT NULL,       -- create / update / hide / show / move / delete
+                                 entity_type VARCHAR(32) NOT NULL,  -- 'service'
+                                 entity_id BIGINT NOT NULL,
+                                 before_data JSONB,                 -- NULL для create
+                                 after_data JSONB,                  -- NULL для delete
+                                 created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_admin_audit_log_entity ON admin_audit_log(entity_type, entity_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_admin_audit_log_entity;
+DROP TABLE IF EXISTS admin_audit_log;
+ALTER TABLE services ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS services_id_seq;
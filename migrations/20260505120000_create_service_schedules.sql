@@ -0,0 +1,90 @@
+-- +goose Up
+-- Недельные часы работы: {"1": [{"open": "10:00", "close": "18:00"}], ...}, дни ISO 1=пн ... 7=вс.
+-- Заменяют work_days, open_time/close_time и текстовое расписание карточки.
+ALTER TABLE services ADD COLUMN weekly_hours JSONB NOT NULL DEFAULT '{}';
+
+UPDATE services SET weekly_hours = '{"2": [{"open": "10:00", "close": "18:00"}], "3": [{"open": "10:00", "close": "18:00"}], "4": [{"open": "10:00", "close": "18:00"}], "5": [{"open": "10:00", "close": "18:00"}], "6": [{"open": "10:00", "close": "18:00"}], "7": [{"open": "10:00", "close": "18:00"}]}'
+WHERE id = 1;
+UPDATE services SET weekly_hours = '{"2": [{"open": "11:00", "close": "20:00"}], "3": [{"open": "11:00", "close": "20:00"}], "4": [{"open": "11:00", "close": "20:00"}], "5": [{"open": "11:00", "close": "20:00"}], "6": [{"open": "11:00", "close": "20:00"}], "7": [{"open": "11:00", "close": "20:00"}]}'
+WHERE id = 2;
+UPDATE services SET weekly_hours = '{"1": [{"open": "19:00", "close": "22:00"}], "2": [{"open": "19:00", "close": "22:00"}], "3": [{"open": "19:00", "close": "22:00"}], "4": [{"open": "19:00", "close": "22:00"}], "5": [{"open": "19:00", "close": "22:00"}], "6": [{"open": "19:00", "close": "22:00"}], "7": [{"open": "19:00", "close": "22:00"}]}'
+WHERE id = 3;
+UPDATE services SET weekly_hours = '{"1": [{"open": "06:00", "close": "23:00"}], "2": [{"open": "06:00", "close": "23:00"}], "3": [{"open": "06:00", "close": "23:00"}], "4": [{"open": "06:00", "close": "23:00"}], "5": [{"open": "06:00", "close": "23:00"}], "6": [{"open": "06:00", "close": "23:00"}], "7": [{"open": "06:00", "close": "23:00"}]}'
+WHERE id IN (4, 5);
+
+ALTER TABLE services
+    DROP COLUMN schedule,
+    DROP COLUMN work_days,
+    DROP COLUMN open_time,
+    DROP COLUMN close_time;
+
+-- Особые часы на дату. Строка без времени закрывает услугу на весь день,
+-- несколько строк на одну дату задают несколько интервалов.
+CREATE TABLE service_schedule_exceptions (
+    id             BIGSERIAL PRIMARY KEY,
+    service_id     INTEGER NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    exception_date DATE    NOT NULL,
+    open_time      TIME,
+    close_time     TIME,
+    note           TEXT    NOT NULL DEFAULT '',  -- показывается в карточке услуги
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT service_schedule_exceptions_hours_check
+        CHECK ((open_time IS NULL) = (close_time IS NULL) AND (open_time IS NULL OR open_time < close_time))
+);
+
+CREATE INDEX idx_service_schedule_exceptions_service_date ON service_schedule_exceptions(service_id, exception_date);
+
+-- Государственные праздники закрывают все услуги, если на дату нет исключения.
+CREATE TABLE public_holidays (
+    holiday_date DATE PRIMARY KEY,
+    title        TEXT NOT NULL
+);
+
+INSERT INTO public_holidays (holiday_date, title) VALUES
+    ('2026-01-01', 'Новогодние каникулы'),
+    ('2026-01-02', 'Новогодние каникулы'),
+    ('2026-01-03', 'Новогодние каникулы'),
+    ('2026-01-04', 'Новогодние каникулы'),
+    ('2026-01-05', 'Новогодние каникулы'),
+    ('2026-01-06', 'Новогодние каникулы'),
+    ('2026-01-07', 'Рождество Христово'),
+    ('2026-01-08', 'Новогодние каникулы'),
+    ('2026-02-23', 'День защитника Отечества'),
+    ('2026-03-08', 'Международный женский день'),
+    ('2026-05-01', 'Праздник Весны и Труда'),
+    ('2026-05-09', 'День Победы'),
+    ('2026-06-12', 'День России'),
+    ('2026-11-04', 'День народного единства'),
+    ('2027-01-01', 'Новогодние каникулы'),
+    ('2027-01-02', 'Новогодние каникулы'),
+    ('2027-01-03', 'Новогодние каникулы'),
+    ('2027-01-04', 'Новогодние каникулы'),
+    ('2027-01-05', 'Новогодние каникулы'),
+    ('2027-01-06', 'Новогодние каникулы'),
+    ('2027-01-07', 'Рождество Христово'),
+    ('2027-01-08', 'Новогодние каникулы'),
+    ('2027-02-23', 'День защитника Отечества'),
+    ('2027-03-08', 'Международный женский день'),
+    ('2027-05-01', 'Праздник Весны и Труда'),
+    ('2027-05-09', 'День Победы'),
+    ('2027-06-12', 'День России'),
+    ('2027-11-04', 'День народного единства');
+
+-- +goose Down
+DROP TABLE IF EXISTS public_holidays;
+DROP INDEX IF EXISTS idx_service_schedule_exceptions_service_date;
+DROP TABLE IF EXISTS service_schedule_exceptions;
+
+ALTER TABLE services
+    ADD COLUMN schedule   TEXT       NOT NULL DEFAULT '',
+    ADD COLUMN work_days  SMALLINT[] NOT NULL DEFAULT '{1,2,3,4,5}',
+    ADD COLUMN open_time  TIME,
+    ADD COLUMN close_time TIME;
+
+UPDATE services SET work_days = ARRAY(SELECT jsonb_object_keys(weekly_hours)::smallint ORDER BY 1);
+UPDATE services
+SET open_time = (weekly_hours -> (SELECT MIN(k) FROM jsonb_object_keys(weekly_hours) AS k) -> 0 ->> 'open')::time,
+    close_time = (weekly_hours -> (SELECT MIN(k) FROM jsonb_object_keys(weekly_hours) AS k) -> 0 ->> 'close')::time
+WHERE slot_minutes IS NOT NULL AND weekly_hours <> '{}';
+
+ALTER TABLE services DROP COLUMN IF EXISTS weekly_hours;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Фотографии карточки услуги. Файл берётся из local_path (относительно bot.media_dir) при первой
+-- отправке, после неё в file_id сохраняется идентификатор из Telegram и файл больше не загружается.
+CREATE TABLE service_photos (
+    id         BIGSERIAL PRIMARY KEY,
+    service_id INTEGER  NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    sort_order SMALLINT NOT NULL DEFAULT 0,
+    local_path TEXT     NOT NULL DEFAULT '',
+    file_id    TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT service_photos_source_check CHECK (local_path <> '' OR file_id IS NOT NULL)
+);
+
+CREATE INDEX idx_service_photos_service_id ON service_photos(service_id, sort_order, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_service_photos_service_id;
+DROP TABLE IF EXISTS service_photos;
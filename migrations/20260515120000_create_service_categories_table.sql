@@ -0,0 +1,30 @@
+-- +goose Up
+-- Категории каталога коробочных решений. Категория без видимых пользователю услуг в меню
+-- не показывается, услуги без категории попадают в «Другое».
+CREATE TABLE service_categories (
+    id         SERIAL PRIMARY KEY,
+    title      VARCHAR(255) NOT NULL,
+    sort_order INTEGER      NOT NULL DEFAULT 0
+);
+
+INSERT INTO service_categories (id, title, sort_order) VALUES
+    (1, 'Музеи', 10),
+    (2, 'Театры', 20),
+    (3, 'Спорт', 30),
+    (4, 'Дайджесты', 40);
+
+SELECT setval('service_categories_id_seq', (SELECT MAX(id) FROM service_categories));
+
+ALTER TABLE services ADD COLUMN category_id INTEGER REFERENCES service_categories(id) ON DELETE SET NULL;
+
+UPDATE services SET category_id = 1 WHERE id IN (1, 2);
+UPDATE services SET category_id = 2 WHERE id = 3;
+UPDATE services SET category_id = 3 WHERE id IN (4, 5);
+UPDATE services SET category_id = 4 WHERE id = 6;
+
+CREATE INDEX idx_services_category_id ON services(category_id, sort_order, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_services_category_id;
+ALTER TABLE services DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS service_categories;
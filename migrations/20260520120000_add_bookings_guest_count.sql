@@ -0,0 +1,44 @@
+-- +goose Up
+-- сколько мест бронирование занимает в слоте: все гости, включая контактное лицо
+ALTER TABLE bookings
+    ADD COLUMN guest_count SMALLINT NOT NULL DEFAULT 1 CHECK (guest_count >= 1);
+
+UPDATE bookings b
+SET guest_count = g.cnt
+FROM (SELECT booking_id, COUNT(*) AS cnt FROM booking_guests GROUP BY booking_id) g
+WHERE g.booking_id = b.id AND g.cnt > 1;
+
+-- до этой миграции групповое бронирование и предложение из листа ожидания занимали
+-- одно место; досчитываем остальных гостей, не выходя за вместимость слота
+UPDATE service_slots s
+SET booked = LEAST(s.booked + x.extra, s.capacity),
+    updated_at = CURRENT_TIMESTAMP
+FROM (
+    SELECT slot_id, SUM(extra) AS extra
+    FROM (
+        SELECT slot_id, guest_count - 1 AS extra
+        FROM bookings
+        WHERE slot_id IS NOT NULL AND COALESCE(status, 'pending') <> 'cancelled'
+        UNION ALL
+        SELECT slot_id, GREATEST(jsonb_array_length(guests), 1) - 1
+        FROM waitlist_entries
+        WHERE status = 'offered' AND slot_id IS NOT NULL
+    ) t
+    GROUP BY slot_id
+) x
+WHERE s.id = x.slot_id AND x.extra > 0;
+
+-- +goose Down
+-- возвращаем прежний учёт: каждое активное бронирование и каждое предложение из листа
+-- ожидания занимают в слоте одно место
+UPDATE service_slots s
+SET booked = LEAST(
+        (SELECT COUNT(*) FROM bookings b
+         WHERE b.slot_id = s.id AND COALESCE(b.status, 'pending') <> 'cancelled')
+        + (SELECT COUNT(*) FROM waitlist_entries w
+           WHERE w.slot_id = s.id AND w.status = 'offered'),
+        s.capacity),
+    updated_at = CURRENT_TIMESTAMP;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS guest_count;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- у пользователя может быть несколько диалогов одновременно, например форма бронирования
+-- и черновик в редакторе услуг: сессия определяется пользователем и диалогом
+ALTER TABLE user_sessions DROP CONSTRAINT IF EXISTS user_sessions_user_id_key;
+ALTER TABLE user_sessions
+    ADD CONSTRAINT user_sessions_user_id_state_key UNIQUE (user_id, current_state);
+
+-- +goose Down
+-- оставляем по одной, самой свежей сессии пользователя
+DELETE FROM user_sessions s
+USING user_sessions newer
+WHERE newer.user_id = s.user_id
+    AND (newer.updated_at, newer.id) > (s.updated_at, s.id);
+
+ALTER TABLE user_sessions DROP CONSTRAINT IF EXISTS user_sessions_user_id_state_key;
+ALTER TABLE user_sessions ADD CONSTRAINT user_sessions_user_id_key UNIQUE (user_id);
@@ -0,0 +1,14 @@
+-- +goose Up
+-- перенос бронирования не меняет статус, поэтому в историю пишем прежние и новые дату и время
+ALTER TABLE booking_status_history
+    ADD COLUMN old_booking_date DATE,
+    ADD COLUMN old_booking_time TIME,
+    ADD COLUMN new_booking_date DATE,
+    ADD COLUMN new_booking_time TIME;
+
+-- +goose Down
+ALTER TABLE booking_status_history
+    DROP COLUMN IF EXISTS new_booking_time,
+    DROP COLUMN IF EXISTS new_booking_date,
+    DROP COLUMN IF EXISTS old_booking_time,
+    DROP COLUMN IF EXISTS old_booking_date;